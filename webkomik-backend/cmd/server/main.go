@@ -11,10 +11,19 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
 	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/config"
 	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
 	comicshandler "github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/handlers/comics"
+	genrehandler "github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/handlers/genres"
+	notificationhandler "github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/handlers/notifications"
+	userhandler "github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/handlers/users"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/identity"
 	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/middleware"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/notify"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/scheduler"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,8 +41,53 @@ func main() {
 	// Pastikan koneksi database ditutup saat aplikasi selesai
 	defer database.CloseDB()
 
+	// Inisialisasi storage untuk file unggahan (gambar halaman)
+	fileStore, err := storage.New(cfg)
+	if err != nil {
+		log.Fatal("Gagal menginisialisasi storage: ", err)
+	}
+
+	// Identity provider untuk meneruskan perubahan peran dan blokir akun ke Supabase Auth
+	identityProvider := identity.New(cfg)
+
+	// Bus event di dalam proses, misalnya untuk pengumuman chapter baru
+	eventBus := events.NewBus()
+
+	// Kanal notifikasi selain in-app: stream SSE dan email selalu aktif (email ke log jika SMTP belum diatur),
+	// web push jika VAPID diatur
+	mailer, err := notify.NewMailer(cfg)
+	if err != nil {
+		log.Fatal("Gagal menginisialisasi mailer: ", err)
+	}
+	notificationHub := notify.NewHub()
+	streamTickets := notify.NewStreamTickets()
+	notifyChannels := []notify.Channel{notificationHub, notify.NewEmailChannel(mailer, cfg.FrontendURL)}
+	var webPush *notify.WebPushChannel
+	if cfg.VAPIDPrivateKey != "" {
+		if webPush, err = notify.NewWebPushChannel(cfg.VAPIDPrivateKey, cfg.VAPIDSubject); err != nil {
+			log.Fatal("Gagal menginisialisasi web push: ", err)
+		}
+		notifyChannels = append(notifyChannels, webPush)
+	} else {
+		log.Println("Peringatan: VAPID_PRIVATE_KEY tidak di-set, notifikasi web push dinonaktifkan.")
+	}
+	notifier := notify.NewDispatcher(notifyChannels...)
+	notifier.Subscribe(eventBus)
+
+	// Scheduler latar belakang dihentikan bersama server
+	schedulerCtx, stopSchedulers := context.WithCancel(context.Background())
+	defer stopSchedulers()
+	go notifier.Run(schedulerCtx)
+	go scheduler.NewChapterReleaser(eventBus, cfg.ChapterReleaseInterval).Run(schedulerCtx)
+	go scheduler.NewTrendingRefresher(cfg.TrendingInterval, cfg.ViewDedupWindow).Run(schedulerCtx)
+	go scheduler.NewSimilarityRefresher(cfg.RecommendationInterval).Run(schedulerCtx)
+
 	// Inisialisasi Gin router
 	router := gin.Default()
+	// Tanpa proxy terpercaya, ClientIP mengabaikan X-Forwarded-For yang bisa dipalsukan klien
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Konfigurasi TRUSTED_PROXIES tidak valid: ", err)
+	}
 
 	// Konfigurasi CORS yang lebih spesifik untuk pengembangan lokal
 	router.Use(cors.New(cors.Config{
@@ -48,6 +102,11 @@ func main() {
 	// Middleware global jika ada (misalnya, CORS, logging tambahan)
 	// router.Use(corsMiddleware()) // Contoh
 
+	// Sajikan file unggahan secara statis jika memakai storage lokal
+	if localStore, ok := fileStore.(*storage.LocalStorage); ok {
+		router.Static("/uploads", localStore.BaseDir())
+	}
+
 	// === Route Publik (tidak memerlukan otentikasi) ===
 	router.GET("/ping", func(c *gin.Context) {
 		var currentTime time.Time
@@ -75,11 +134,27 @@ func main() {
 	{
 		// --- Route Publik di dalam /api ---
 		api.GET("/comics", comicshandler.GetAllComicsHandler)
-		api.GET("/comics/:id", comicshandler.GetComicDetailHandler)
+		api.GET("/comics/search", comicshandler.SearchComicsHandler)
+		api.GET("/comics/trending", comicshandler.TrendingComicsHandler)
+		api.GET("/comics/top", comicshandler.TopComicsHandler)
+		// Login bersifat opsional: anggota tim komik dan peninjau juga bisa melihat konten yang belum dipublikasikan
+		optionalAuth := middleware.OptionalAuthMiddleware(cfg)
+		api.GET("/comics/:id", optionalAuth, comicshandler.GetComicDetailHandler)
+		api.GET("/comics/:id/chapters/:number", optionalAuth, comicshandler.ReadChapterHandler)
+		api.GET("/comics/:id/reviews", optionalAuth, comicshandler.GetComicReviewsHandler)
+		api.GET("/chapters/:id", optionalAuth, comicshandler.GetChapterHandler)
+		api.GET("/chapters/:id/download", optionalAuth, comicshandler.DownloadChapterHandler(fileStore))
+		api.POST("/chapters/:id/view", optionalAuth, comicshandler.RecordChapterViewHandler(cfg.ViewDedupWindow))
+		api.GET("/chapters/:id/comments", optionalAuth, comicshandler.ListChapterCommentsHandler)
+		api.GET("/comments/:id/replies", optionalAuth, comicshandler.ListCommentRepliesHandler)
+		api.GET("/push/public-key", notificationhandler.PushPublicKeyHandler(webPush))
+		// EventSource tidak bisa mengirim header Authorization, jadi stream juga menerima tiket dari /me/notifications/stream-ticket
+		api.GET("/me/notifications/stream", optionalAuth, notificationhandler.StreamHandler(notificationHub, streamTickets))
+		api.GET("/genres", genrehandler.GetAllGenresHandler)
 
 		// --- Grup yang memerlukan otentikasi ---
 		authRequired := api.Group("/") // Base untuk semua yang butuh login
-		authRequired.Use(middleware.AuthMiddleware(cfg))
+		authRequired.Use(middleware.AuthMiddleware(cfg), middleware.AccountStatusMiddleware())
 		{
 			authRequired.GET("/me", func(c *gin.Context) {
 				// ... (kode /me) ...
@@ -99,23 +174,123 @@ func main() {
 				})
 			})
 
-			// --- Grup yang dapat diakses oleh admin dan creator (untuk mengelola konten) ---
-			contentManager := authRequired.Group("/")                       // Mewarisi AuthMiddleware dari authRequired
-			contentManager.Use(middleware.AdminOrCreatorRoleMiddleware()) // Memungkinkan admin DAN creator mengakses
-			{
-				contentManager.POST("/comics", comicshandler.CreateComicHandler) // Endpoint pembuatan komik baru
-				contentManager.PUT("/comics/:id", comicshandler.UpdateComicHandler) // Endpoint update komik
-			}
-
-			// --- Grup yang memerlukan peran admin saja (untuk fitur administrasi) ---
-			adminProtected := authRequired.Group("/")            // Mewarisi AuthMiddleware dari authRequired
-			adminProtected.Use(middleware.AdminRoleMiddleware()) // Hanya admin yang dapat mengakses
-			{
-				// Endpoint khusus admin seperti penghapusan, manajemen user, dll
-				// adminProtected.DELETE("/comics/:id", comicshandler.DeleteComicHandler)
-				// adminProtected.POST("/genres", genrehandler.CreateGenreHandler)
-				// adminProtected.GET("/users", userhandler.GetAllUsersHandler)
-			}
+			// --- Route yang dilindungi izin (pemetaan peran ke izin ada di tabel role_permissions) ---
+			// Route pengelolaan komik dan chapter tidak diperiksa di level route karena anggota tim komik
+			// (editor, uploader) tidak harus memiliki izin global; handler memeriksanya lewat authz.OwnershipPolicy.
+			requireTrashManage := middleware.RequirePermission(authz.ComicTrashManage)
+
+			authRequired.POST("/comics", middleware.RequirePermission(authz.ComicCreate), comicshandler.CreateComicHandler) // Endpoint pembuatan komik baru
+			authRequired.PUT("/comics/:id", comicshandler.UpdateComicHandler)                                               // Endpoint update komik
+			authRequired.DELETE("/comics/:id", comicshandler.DeleteComicHandler)                                            // Pindahkan komik ke sampah
+
+			// Manajemen chapter, chapter diidentifikasi lewat nomor chapternya
+			authRequired.POST("/comics/:id/chapters", comicshandler.CreateChapterHandler)
+			authRequired.PUT("/comics/:id/chapters/:number", comicshandler.UpdateChapterHandler)
+			authRequired.DELETE("/comics/:id/chapters/:number", comicshandler.DeleteChapterHandler(fileStore))
+			authRequired.POST("/comics/:id/chapters/:number/pages", comicshandler.UploadPagesHandler(fileStore))
+			authRequired.POST("/comics/:id/chapters/import", comicshandler.ImportChapterHandler(fileStore))
+
+			// Alur publikasi: tim komik mengajukan, peninjau menyetujui, menolak, atau menyembunyikan
+			requireContentReview := middleware.RequirePermission(authz.ContentReview)
+			authRequired.GET("/me/comics", comicshandler.ListMyComicsHandler)
+			authRequired.POST("/comics/:id/submit", comicshandler.SubmitComicHandler)
+			authRequired.POST("/comics/:id/approve", requireContentReview, comicshandler.ApproveComicHandler)
+			authRequired.POST("/comics/:id/reject", requireContentReview, comicshandler.RejectComicHandler)
+			authRequired.POST("/comics/:id/hide", requireContentReview, comicshandler.HideComicHandler)
+			authRequired.POST("/comics/:id/chapters/:number/submit", comicshandler.SubmitChapterHandler)
+			authRequired.POST("/comics/:id/chapters/:number/approve", requireContentReview, comicshandler.ApproveChapterHandler(eventBus))
+			authRequired.POST("/comics/:id/chapters/:number/reject", requireContentReview, comicshandler.RejectChapterHandler)
+			authRequired.POST("/comics/:id/chapters/:number/hide", requireContentReview, comicshandler.HideChapterHandler)
+			authRequired.GET("/reviews/comics", requireContentReview, comicshandler.ListReviewComicsHandler)
+			authRequired.GET("/reviews/chapters", requireContentReview, comicshandler.ListReviewChaptersHandler)
+
+			// Tim komik: owner mengelola anggota dan undangan, anggota lain boleh melihat tim dan keluar sendiri
+			authRequired.GET("/comics/:id/members", comicshandler.GetComicMembersHandler)
+			authRequired.PUT("/comics/:id/members/:userId", comicshandler.UpdateComicMemberHandler)
+			authRequired.DELETE("/comics/:id/members/:userId", comicshandler.RemoveComicMemberHandler)
+			authRequired.GET("/comics/:id/invitations", comicshandler.GetComicInvitationsHandler)
+			authRequired.POST("/comics/:id/invitations", comicshandler.CreateComicInvitationHandler)
+			authRequired.DELETE("/comics/:id/invitations/:invitationId", comicshandler.RevokeComicInvitationHandler)
+			authRequired.GET("/me/invitations", comicshandler.GetMyInvitationsHandler(identityProvider))
+			authRequired.POST("/invitations/:id/accept", comicshandler.AcceptInvitationHandler(identityProvider))
+			authRequired.POST("/invitations/:id/decline", comicshandler.DeclineInvitationHandler(identityProvider))
+
+			// Pustaka pembaca: komik yang diikuti dan ditandai
+			authRequired.GET("/me/library", comicshandler.ListLibraryHandler)
+			authRequired.PUT("/comics/:id/follow", comicshandler.FollowComicHandler)
+			authRequired.DELETE("/comics/:id/follow", comicshandler.UnfollowComicHandler)
+			authRequired.PUT("/comics/:id/bookmark", comicshandler.BookmarkComicHandler)
+			authRequired.DELETE("/comics/:id/bookmark", comicshandler.UnbookmarkComicHandler)
+			authRequired.POST("/comics/:id/mark-read", comicshandler.MarkComicReadHandler)
+
+			// Progres dan riwayat baca
+			authRequired.POST("/me/progress", comicshandler.RecordProgressHandler)
+			authRequired.GET("/me/continue-reading", comicshandler.ContinueReadingHandler)
+			authRequired.GET("/me/history", comicshandler.ReadingHistoryHandler)
+			authRequired.DELETE("/me/history", comicshandler.ClearReadingHistoryHandler)
+			authRequired.GET("/me/recommendations", comicshandler.RecommendationsHandler)
+
+			// Notifikasi pengguna dan kanal pengirimannya
+			authRequired.GET("/me/notifications", notificationhandler.ListNotificationsHandler)
+			authRequired.GET("/me/notifications/unread-count", notificationhandler.UnreadCountHandler)
+			authRequired.POST("/me/notifications/read", notificationhandler.MarkReadHandler)
+			authRequired.POST("/me/notifications/stream-ticket", notificationhandler.IssueStreamTicketHandler(streamTickets))
+			authRequired.GET("/me/notification-preferences", notificationhandler.GetPreferencesHandler)
+			authRequired.PUT("/me/notification-preferences", notificationhandler.UpdatePreferencesHandler)
+			authRequired.POST("/me/push-subscriptions", notificationhandler.SubscribePushHandler(webPush))
+			authRequired.DELETE("/me/push-subscriptions/:id", notificationhandler.UnsubscribePushHandler)
+
+			// Rating dan ulasan pembaca beserta moderasinya
+			requireReviewModerate := middleware.RequirePermission(authz.ReviewModerate)
+			authRequired.GET("/comics/:id/review", comicshandler.GetMyComicReviewHandler)
+			authRequired.PUT("/comics/:id/review", comicshandler.UpsertComicReviewHandler)
+			authRequired.DELETE("/comics/:id/review", comicshandler.DeleteMyComicReviewHandler)
+			authRequired.POST("/comic-reviews/:id/report", comicshandler.ReportComicReviewHandler)
+			authRequired.GET("/comic-reviews", requireReviewModerate, comicshandler.ListReviewModerationHandler)
+			authRequired.POST("/comic-reviews/:id/hide", requireReviewModerate, comicshandler.HideComicReviewHandler(eventBus))
+			authRequired.POST("/comic-reviews/:id/restore", requireReviewModerate, comicshandler.RestoreComicReviewHandler(eventBus))
+			authRequired.DELETE("/comic-reviews/:id", requireReviewModerate, comicshandler.DeleteComicReviewHandler(eventBus))
+
+			// Komentar chapter beserta moderasinya
+			requireCommentCreate := middleware.RequirePermission(authz.CommentCreate)
+			requireCommentModerate := middleware.RequirePermission(authz.CommentModerate)
+			authRequired.POST("/chapters/:id/comments", requireCommentCreate, comicshandler.CreateCommentHandler)
+			authRequired.POST("/comments/:id/replies", requireCommentCreate, comicshandler.ReplyCommentHandler(eventBus))
+			authRequired.PUT("/comments/:id", requireCommentCreate, comicshandler.UpdateCommentHandler)
+			authRequired.DELETE("/comments/:id", middleware.RequireAnyPermission(authz.CommentDeletePolicy.Permissions()...), comicshandler.DeleteCommentHandler(eventBus))
+			authRequired.PUT("/comments/:id/like", comicshandler.SetCommentLikeHandler)
+			authRequired.DELETE("/comments/:id/like", comicshandler.UnsetCommentLikeHandler)
+			authRequired.GET("/comments", requireCommentModerate, comicshandler.ListCommentModerationHandler)
+			authRequired.POST("/comments/:id/hide", requireCommentModerate, comicshandler.HideCommentHandler(eventBus))
+			authRequired.POST("/comments/:id/restore", requireCommentModerate, comicshandler.RestoreCommentHandler(eventBus))
+			authRequired.POST("/comments/:id/spoiler", requireCommentModerate, comicshandler.SetCommentSpoilerHandler)
+
+			// Sampah komik
+			authRequired.GET("/comics/trash", requireTrashManage, comicshandler.ListTrashedComicsHandler)
+			authRequired.POST("/comics/:id/restore", requireTrashManage, comicshandler.RestoreComicHandler)
+			authRequired.DELETE("/comics/:id/purge", requireTrashManage, comicshandler.PurgeComicHandler(fileStore))
+
+			// Manajemen genre
+			requireGenreManage := middleware.RequirePermission(authz.GenreManage)
+			authRequired.POST("/genres", requireGenreManage, genrehandler.CreateGenreHandler)
+			authRequired.PUT("/genres/:id", requireGenreManage, genrehandler.UpdateGenreHandler)
+			authRequired.DELETE("/genres/:id", requireGenreManage, genrehandler.DeleteGenreHandler)
+
+			// Manajemen pengguna
+			requireUserRead := middleware.RequirePermission(authz.UserRead)
+			requireUserBan := middleware.RequirePermission(authz.UserBan)
+			authRequired.GET("/users", requireUserRead, userhandler.GetAllUsersHandler)
+			authRequired.GET("/users/:id", requireUserRead, userhandler.GetUserDetailHandler)
+			authRequired.GET("/users/:id/comics", requireUserRead, userhandler.GetUserComicsHandler)
+			authRequired.PUT("/users/:id/role", middleware.RequirePermission(authz.UserRoleUpdate), userhandler.UpdateUserRoleHandler(identityProvider))
+			authRequired.POST("/users/:id/suspend", requireUserBan, userhandler.SuspendUserHandler(identityProvider))
+			authRequired.POST("/users/:id/ban", requireUserBan, userhandler.BanUserHandler(identityProvider))
+			authRequired.POST("/users/:id/unban", requireUserBan, userhandler.UnbanUserHandler(identityProvider))
+
+			// Pemetaan peran ke izin
+			requireRoleManage := middleware.RequirePermission(authz.RoleManage)
+			authRequired.GET("/roles", requireRoleManage, userhandler.GetRolePermissionsHandler)
+			authRequired.PUT("/roles/:role/permissions", requireRoleManage, userhandler.UpdateRolePermissionsHandler)
 		}
 	}
 
@@ -128,6 +303,8 @@ func main() {
 		Addr:    serverAddr,
 		Handler: router, // Menggunakan router Gin sebagai handler utama
 	}
+	// Stream notifikasi tidak pernah selesai sendiri, jadi ditutup lebih dulu agar Shutdown tidak menunggu sampai batas waktu
+	srv.RegisterOnShutdown(notificationHub.Close)
 
 	// Jalankan server dalam goroutine agar tidak memblokir proses graceful shutdown
 	go func() {
@@ -144,6 +321,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit // Blokir hingga sinyal diterima
 	log.Println("Menerima sinyal interrupt, mematikan server...")
+	stopSchedulers()
 
 	// Konteks untuk memberi tahu server batas waktu untuk menyelesaikan request yang sedang berjalan.
 	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second) // Tunggu maksimal 10 detik
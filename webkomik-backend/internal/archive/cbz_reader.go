@@ -0,0 +1,85 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidArchive dibungkus oleh semua error validasi arsip CBZ/ZIP.
+var ErrInvalidArchive = errors.New("arsip tidak valid")
+
+// imageExtensions adalah ekstensi file yang dianggap sebagai halaman di dalam arsip.
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".gif":  true,
+}
+
+// ImageEntries membuka arsip ZIP/CBZ dan mengembalikan entri gambar yang sudah diurutkan secara natural.
+// Direktori, file tersembunyi, metadata macOS (__MACOSX), dan file non-gambar seperti ComicInfo.xml diabaikan.
+func ImageEntries(r io.ReaderAt, size int64, maxEntries int) ([]*zip.File, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	entries := []*zip.File{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !isPageEntry(f.Name) {
+			continue
+		}
+		entries = append(entries, f)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: tidak ada gambar di dalam arsip", ErrInvalidArchive)
+	}
+	if len(entries) > maxEntries {
+		return nil, fmt.Errorf("%w: jumlah gambar %d melebihi batas %d", ErrInvalidArchive, len(entries), maxEntries)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return NaturalLess(entries[i].Name, entries[j].Name)
+	})
+	return entries, nil
+}
+
+// isPageEntry menentukan apakah nama entri arsip merupakan gambar halaman.
+func isPageEntry(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, part := range strings.Split(name, "/") {
+		if part == "__MACOSX" || strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return imageExtensions[strings.ToLower(path.Ext(name))]
+}
+
+// ReadEntry membaca seluruh isi entri ke memori dengan batas ukuran maxBytes.
+// Ukuran di header ZIP tidak dipercaya begitu saja untuk mencegah zip bomb.
+func ReadEntry(f *zip.File, maxBytes int64) (*bytes.Reader, error) {
+	if f.UncompressedSize64 > uint64(maxBytes) {
+		return nil, fmt.Errorf("%w: %s berukuran %d byte melebihi batas %d byte", ErrInvalidArchive, f.Name, f.UncompressedSize64, maxBytes)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: gagal membuka %s: %v", ErrInvalidArchive, f.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: gagal membaca %s: %v", ErrInvalidArchive, f.Name, err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: %s melebihi batas %d byte", ErrInvalidArchive, f.Name, maxBytes)
+	}
+	return bytes.NewReader(data), nil
+}
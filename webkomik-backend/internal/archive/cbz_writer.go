@@ -0,0 +1,52 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CBZWriter menulis arsip CBZ secara streaming langsung ke writer tujuan (misalnya response HTTP).
+// Gambar disimpan tanpa kompresi ulang (metode Store) karena JPEG/PNG/WebP sudah terkompresi.
+type CBZWriter struct {
+	zw      *zip.Writer
+	modTime time.Time
+}
+
+// NewCBZWriter membuat CBZWriter dan langsung menulis ComicInfo.xml sebagai entri pertama.
+func NewCBZWriter(w io.Writer, info *ComicInfo, modTime time.Time) (*CBZWriter, error) {
+	cw := &CBZWriter{zw: zip.NewWriter(w), modTime: modTime}
+
+	entry, err := cw.zw.CreateHeader(&zip.FileHeader{Name: "ComicInfo.xml", Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat entri ComicInfo.xml: %w", err)
+	}
+	if _, err := io.WriteString(entry, xml.Header); err != nil {
+		return nil, fmt.Errorf("gagal menulis ComicInfo.xml: %w", err)
+	}
+	enc := xml.NewEncoder(entry)
+	enc.Indent("", "  ")
+	if err := enc.Encode(info); err != nil {
+		return nil, fmt.Errorf("gagal menulis ComicInfo.xml: %w", err)
+	}
+	return cw, nil
+}
+
+// AddPage menyalin isi r sebagai entri gambar bernama name.
+func (cw *CBZWriter) AddPage(name string, r io.Reader) error {
+	entry, err := cw.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: cw.modTime})
+	if err != nil {
+		return fmt.Errorf("gagal membuat entri %s: %w", name, err)
+	}
+	if _, err := io.Copy(entry, r); err != nil {
+		return fmt.Errorf("gagal menulis entri %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close menulis central directory arsip. Writer tujuan tidak ditutup.
+func (cw *CBZWriter) Close() error {
+	return cw.zw.Close()
+}
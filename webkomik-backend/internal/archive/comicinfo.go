@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// ComicInfo adalah metadata ComicInfo.xml (skema ComicRack) yang dibaca oleh kebanyakan aplikasi pembaca CBZ.
+type ComicInfo struct {
+	XMLName   xml.Name        `xml:"ComicInfo"`
+	XSI       string          `xml:"xmlns:xsi,attr"`
+	XSD       string          `xml:"xmlns:xsd,attr"`
+	Title     string          `xml:"Title,omitempty"`
+	Series    string          `xml:"Series"`
+	Number    string          `xml:"Number"`
+	Summary   string          `xml:"Summary,omitempty"`
+	Year      int             `xml:"Year,omitempty"`
+	Month     int             `xml:"Month,omitempty"`
+	Day       int             `xml:"Day,omitempty"`
+	Writer    string          `xml:"Writer,omitempty"`
+	Genre     string          `xml:"Genre,omitempty"`
+	Tags      string          `xml:"Tags,omitempty"`
+	Web       string          `xml:"Web,omitempty"`
+	PageCount int             `xml:"PageCount"`
+	Pages     []ComicInfoPage `xml:"Pages>Page"`
+}
+
+// ComicInfoPage adalah metadata satu halaman di ComicInfo.xml. Image adalah indeks halaman mulai dari 0.
+type ComicInfoPage struct {
+	Image       int    `xml:"Image,attr"`
+	Type        string `xml:"Type,attr,omitempty"`
+	ImageWidth  int    `xml:"ImageWidth,attr,omitempty"`
+	ImageHeight int    `xml:"ImageHeight,attr,omitempty"`
+}
+
+// NewComicInfo menyusun ComicInfo dari data komik, chapter, dan halamannya.
+func NewComicInfo(comic *models.Comic, chapter *models.Chapter, pages []models.Page) *ComicInfo {
+	info := &ComicInfo{
+		XSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		XSD:       "http://www.w3.org/2001/XMLSchema",
+		Series:    comic.Title,
+		Number:    strconv.FormatFloat(float64(chapter.ChapterNumber), 'f', -1, 32),
+		Year:      chapter.CreatedAt.Year(),
+		Month:     int(chapter.CreatedAt.Month()),
+		Day:       chapter.CreatedAt.Day(),
+		PageCount: len(pages),
+	}
+	if chapter.Title != nil {
+		info.Title = *chapter.Title
+	}
+	if comic.Description != nil {
+		info.Summary = *comic.Description
+	}
+	if comic.AuthorName != nil {
+		info.Writer = *comic.AuthorName
+	}
+	// ComicInfo menyimpan banyak genre/tag sebagai satu string dipisah koma
+	genres := make([]string, 0, len(comic.Genres))
+	for _, g := range comic.Genres {
+		genres = append(genres, g.Name)
+	}
+	info.Genre = strings.Join(genres, ", ")
+	info.Tags = strings.Join(comic.Tags, ", ")
+	for i, p := range pages {
+		page := ComicInfoPage{Image: i}
+		if i == 0 {
+			page.Type = "FrontCover"
+		}
+		if p.Width != nil && p.Height != nil {
+			page.ImageWidth, page.ImageHeight = *p.Width, *p.Height
+		}
+		info.Pages = append(info.Pages, page)
+	}
+	return info
+}
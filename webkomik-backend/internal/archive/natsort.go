@@ -0,0 +1,68 @@
+package archive
+
+import "strings"
+
+// NaturalLess membandingkan dua string secara "natural": deret angka dibandingkan berdasarkan nilainya,
+// sehingga "page2.jpg" berada sebelum "page10.jpg". Perbandingan huruf tidak membedakan kapital.
+func NaturalLess(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	for a != "" && b != "" {
+		aDigit, bDigit := isDigit(a[0]), isDigit(b[0])
+		switch {
+		case aDigit && bDigit:
+			aNum, aRest := splitDigits(a)
+			bNum, bRest := splitDigits(b)
+			if cmp := compareNumeric(aNum, bNum); cmp != 0 {
+				return cmp < 0
+			}
+			a, b = aRest, bRest
+		case aDigit != bDigit:
+			// Angka diurutkan sebelum huruf, sama seperti urutan ASCII
+			return aDigit
+		default:
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+			a, b = a[1:], b[1:]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// splitDigits memisahkan deret angka di awal s dari sisanya.
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNumeric membandingkan dua deret angka tanpa konversi ke integer (aman untuk angka yang sangat panjang).
+// Jika nilainya sama, deret dengan nol di depan lebih sedikit dianggap lebih kecil agar urutan tetap stabil.
+func compareNumeric(a, b string) int {
+	ta, tb := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(ta) != len(tb) {
+		if len(ta) < len(tb) {
+			return -1
+		}
+		return 1
+	}
+	if ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package archive
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"angka dibandingkan nilainya", "page2.jpg", "page10.jpg", true},
+		{"angka lebih besar", "page10.jpg", "page2.jpg", false},
+		{"nol di depan dengan nilai berbeda", "page002.jpg", "page10.jpg", true},
+		{"nol di depan dengan nilai sama, lebih sedikit nol lebih dulu", "page1.jpg", "page01.jpg", true},
+		{"nol di depan dengan nilai sama, kebalikannya", "page01.jpg", "page1.jpg", false},
+		{"hanya nol", "0", "00", true},
+		{"angka sangat panjang", "99999999999999999999998", "99999999999999999999999", true},
+		{"angka sebelum huruf", "1.jpg", "a.jpg", true},
+		{"huruf setelah angka", "a.jpg", "1.jpg", false},
+		{"campuran angka dan teks", "ch1-p2", "ch1-p10", true},
+		{"campuran, segmen pertama menentukan", "ch2-p1", "ch10-p1", true},
+		{"prefix sama, lebih pendek lebih dulu", "page", "page1", true},
+		{"prefix sama, lebih panjang", "page1", "page", false},
+		{"prefix angka sama", "12", "12a", true},
+		{"string kosong", "", "a", true},
+		{"keduanya kosong", "", "", false},
+		{"sama persis", "page1.jpg", "page1.jpg", false},
+		{"tidak membedakan kapital", "Page2.JPG", "page10.jpg", true},
+		{"kapital dianggap sama", "PAGE1.jpg", "page1.jpg", false},
+		{"kapital dianggap sama, kebalikannya", "page1.jpg", "PAGE1.jpg", false},
+		{"huruf dibandingkan tanpa kapital", "B.jpg", "a.jpg", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NaturalLess(tt.a, tt.b); got != tt.want {
+				t.Errorf("NaturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNaturalLessSort(t *testing.T) {
+	names := []string{"page10.jpg", "Page2.jpg", "page1.jpg", "page01.jpg", "cover.jpg", "page002.jpg", "001.jpg"}
+	sort.SliceStable(names, func(i, j int) bool { return NaturalLess(names[i], names[j]) })
+
+	want := []string{"001.jpg", "cover.jpg", "page1.jpg", "page01.jpg", "Page2.jpg", "page002.jpg", "page10.jpg"}
+	if !slices.Equal(names, want) {
+		t.Errorf("urutan = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,91 @@
+// Package authz berisi model izin aplikasi: daftar izin, pemetaan peran ke izin yang disimpan di database
+// (dengan cache di memori), dan kebijakan kepemilikan untuk resource seperti komik.
+package authz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+)
+
+// Permission adalah nama izin dengan format "resource:aksi[:cakupan]".
+type Permission string
+
+// Izin yang dikenal aplikasi. Harus sesuai dengan isi tabel permissions.
+const (
+	ComicCreate       Permission = "comic:create"
+	ComicUpdateOwn    Permission = "comic:update:own"
+	ComicUpdateAny    Permission = "comic:update:any"
+	ComicDeleteOwn    Permission = "comic:delete:own"
+	ComicDeleteAny    Permission = "comic:delete:any"
+	ComicTrashManage  Permission = "comic:trash:manage"
+	ChapterPublishOwn Permission = "chapter:publish:own"
+	ChapterPublishAny Permission = "chapter:publish:any"
+	GenreManage       Permission = "genre:manage"
+	UserRead          Permission = "user:read"
+	UserRoleUpdate    Permission = "user:role:update"
+	UserBan           Permission = "user:ban"
+	RoleManage        Permission = "role:manage"
+	ContentReview     Permission = "content:review"
+	ReviewModerate    Permission = "review:moderate"
+	CommentCreate     Permission = "comment:create"
+	CommentDeleteOwn  Permission = "comment:delete:own"
+	CommentModerate   Permission = "comment:moderate"
+)
+
+// cacheTTL adalah lama pemetaan peran ke izin disimpan di memori sebelum dibaca ulang dari database.
+const cacheTTL = time.Minute
+
+// roleCache menyimpan pemetaan peran ke himpunan izin.
+var roleCache struct {
+	mu       sync.RWMutex
+	perms    map[string]map[Permission]bool
+	loadedAt time.Time
+}
+
+// RoleHasPermission memeriksa apakah peran memiliki izin perm.
+func RoleHasPermission(ctx context.Context, role string, perm Permission) (bool, error) {
+	perms, err := rolePermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	return perms[role][perm], nil
+}
+
+// Invalidate menghapus cache sehingga pemeriksaan berikutnya membaca ulang dari database.
+// Dipanggil setelah pemetaan peran ke izin diubah.
+func Invalidate() {
+	roleCache.mu.Lock()
+	roleCache.perms = nil
+	roleCache.mu.Unlock()
+}
+
+// rolePermissions mengembalikan pemetaan dari cache, memuat ulang dari database jika sudah kedaluwarsa.
+func rolePermissions(ctx context.Context) (map[string]map[Permission]bool, error) {
+	roleCache.mu.RLock()
+	perms, loadedAt := roleCache.perms, roleCache.loadedAt
+	roleCache.mu.RUnlock()
+	if perms != nil && time.Since(loadedAt) < cacheTTL {
+		return perms, nil
+	}
+
+	rows, err := database.GetRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms = make(map[string]map[Permission]bool, len(rows))
+	for role, names := range rows {
+		set := make(map[Permission]bool, len(names))
+		for _, name := range names {
+			set[Permission(name)] = true
+		}
+		perms[role] = set
+	}
+
+	roleCache.mu.Lock()
+	roleCache.perms, roleCache.loadedAt = perms, time.Now()
+	roleCache.mu.Unlock()
+	return perms, nil
+}
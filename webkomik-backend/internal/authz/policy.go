@@ -0,0 +1,74 @@
+package authz
+
+import (
+	"context"
+	"slices"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// Owned adalah resource yang dikelola oleh pemilik atau tim, misalnya komik.
+type Owned interface {
+	// MemberRole mengembalikan peran userID terhadap resource (misalnya "owner" atau "editor"),
+	// atau string kosong jika userID bukan pemilik maupun anggota tim.
+	MemberRole(userID string) string
+}
+
+// OwnershipPolicy adalah kebijakan akses untuk resource yang dimiliki pengguna:
+// izin Any berlaku untuk semua resource, sedangkan izin Own hanya berlaku jika pengguna
+// adalah anggota resource dengan salah satu peran di MemberRoles. Anggota tim komik yang diundang
+// umumnya berperan global "user", sehingga izin Own komik juga dipetakan ke peran tersebut
+// (migrasi 022); admin bisa mencabutnya lewat role_permissions.
+type OwnershipPolicy struct {
+	Own         Permission
+	Any         Permission
+	MemberRoles []string
+}
+
+// Kebijakan kepemilikan untuk operasi pada komik dan chapter.
+var (
+	ComicUpdatePolicy = OwnershipPolicy{
+		Own: ComicUpdateOwn, Any: ComicUpdateAny,
+		MemberRoles: []string{models.ComicRoleOwner, models.ComicRoleEditor},
+	}
+	ComicDeletePolicy = OwnershipPolicy{
+		Own: ComicDeleteOwn, Any: ComicDeleteAny,
+		MemberRoles: []string{models.ComicRoleOwner},
+	}
+	ComicMembersPolicy = OwnershipPolicy{
+		Own: ComicUpdateOwn, Any: ComicUpdateAny,
+		MemberRoles: []string{models.ComicRoleOwner},
+	}
+	ChapterPublishPolicy = OwnershipPolicy{
+		Own: ChapterPublishOwn, Any: ChapterPublishAny,
+		MemberRoles: []string{models.ComicRoleOwner, models.ComicRoleEditor, models.ComicRoleUploader},
+	}
+	// ComicPreviewPolicy menentukan siapa yang boleh melihat komik dan chapter yang belum dipublikasikan.
+	ComicPreviewPolicy = OwnershipPolicy{
+		Own: ChapterPublishOwn, Any: ContentReview,
+		MemberRoles: []string{models.ComicRoleOwner, models.ComicRoleEditor, models.ComicRoleUploader},
+	}
+)
+
+// CommentDeletePolicy menentukan siapa yang boleh menghapus komentar: penulisnya sendiri atau moderator.
+var CommentDeletePolicy = OwnershipPolicy{
+	Own: CommentDeleteOwn, Any: CommentModerate,
+	MemberRoles: []string{models.CommentRoleAuthor},
+}
+
+// Permissions mengembalikan kedua izin kebijakan, berguna untuk RequireAnyPermission di level route.
+func (p OwnershipPolicy) Permissions() []Permission {
+	return []Permission{p.Own, p.Any}
+}
+
+// Allows memeriksa apakah pengguna dengan peran role boleh mengakses resource.
+func (p OwnershipPolicy) Allows(ctx context.Context, role, userID string, resource Owned) (bool, error) {
+	ok, err := RoleHasPermission(ctx, role, p.Any)
+	if err != nil || ok {
+		return ok, err
+	}
+	if userID == "" || !slices.Contains(p.MemberRoles, resource.MemberRole(userID)) {
+		return false, nil
+	}
+	return RoleHasPermission(ctx, role, p.Own)
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,7 +18,17 @@ type Config struct {
 
 	SupabaseProjectURL string
 	SupabaseAnonKey    string
-	SupabaseJWTSecret  string // Digunakan untuk validasi JWT dari Supabase
+	SupabaseJWTSecret  string // Digunakan untuk validasi JWT HMAC (HS256) dari Supabase
+	// Service role key untuk Admin API Supabase Auth (ubah peran, ban). Opsional untuk pengembangan lokal.
+	SupabaseServiceRoleKey string
+
+	// Verifikasi JWT asimetris (RS256/ES256) lewat JWKS dari URL atau file lokal
+	JWTJWKSURL     string
+	JWTJWKSFile    string
+	JWTJWKSRefresh time.Duration // Interval pemuatan ulang JWKS
+	JWTIssuer      string        // Kosong berarti claim "iss" tidak diperiksa
+	JWTAudience    string        // Kosong berarti claim "aud" tidak diperiksa
+	JWTAllowHMAC   bool          // Terima token HS256 dengan SupabaseJWTSecret
 
 	DBHost     string
 	DBPort     int
@@ -24,6 +36,46 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+
+	// Storage untuk file unggahan (gambar halaman, dll)
+	StorageDriver    string // "local" atau "s3"
+	StorageLocalDir  string // Direktori root untuk driver local
+	StoragePublicURL string // Prefix URL publik untuk file yang disimpan
+
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool // true untuk MinIO dan layanan S3-compatible lain
+
+	// Jeda maksimum antar pemeriksaan chapter terjadwal oleh scheduler rilis
+	ChapterReleaseInterval time.Duration
+
+	// Alamat IP atau CIDR reverse proxy yang header X-Forwarded-For-nya dipercaya untuk menentukan IP klien.
+	// Kosong berarti IP klien selalu diambil dari alamat koneksi.
+	TrustedProxies []string
+
+	// Jendela deduplikasi view chapter per pengguna/IP dan jeda perhitungan ulang peringkat trending
+	ViewDedupWindow  time.Duration
+	TrendingInterval time.Duration
+
+	// Jeda perhitungan ulang kemiripan komik untuk rekomendasi
+	RecommendationInterval time.Duration
+
+	// URL frontend untuk tautan di email notifikasi
+	FrontendURL string
+
+	// SMTP untuk email notifikasi. Kosong berarti email hanya dicatat ke log.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// VAPID untuk Web Push. Kosong berarti kanal web push dinonaktifkan.
+	VAPIDPrivateKey string // Kunci privat P-256 mentah (32 byte), base64url
+	VAPIDSubject    string // Kontak pengelola untuk push service, misalnya "mailto:admin@example.com"
 }
 
 // LoadConfig memuat konfigurasi dari file .env dan environment variables.
@@ -49,26 +101,115 @@ func LoadConfig() (*Config, error) {
 	dbSSLMode := getEnv("DB_SSLMODE", "require")
 
 	// Validasi bahwa variabel penting ada
-	if supabaseProjectURL == "" || supabaseAnonKey == "" || supabaseJWTSecret == "" || dbHost == "" || dbPassword == "" {
-		return nil, fmt.Errorf("error: SUPABASE_PROJECT_URL, SUPABASE_ANON_KEY, SUPABASE_JWT_SECRET, DB_HOST, dan DB_PASSWORD harus di-set")
+	if supabaseProjectURL == "" || supabaseAnonKey == "" || dbHost == "" || dbPassword == "" {
+		return nil, fmt.Errorf("error: SUPABASE_PROJECT_URL, SUPABASE_ANON_KEY, DB_HOST, dan DB_PASSWORD harus di-set")
 	}
 
+	// Token diverifikasi dengan JWKS jika dikonfigurasi; HMAC hanya dipakai jika diizinkan
+	jwksURL := getEnv("JWT_JWKS_URL", "")
+	jwksFile := getEnv("JWT_JWKS_FILE", "")
+	useJWKS := jwksURL != "" || jwksFile != ""
+	allowHMAC, err := strconv.ParseBool(getEnv("JWT_ALLOW_HMAC", strconv.FormatBool(!useJWKS)))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JWT_ALLOW_HMAC: %w", err)
+	}
+	if allowHMAC && supabaseJWTSecret == "" {
+		return nil, fmt.Errorf("error: SUPABASE_JWT_SECRET harus di-set jika verifikasi HMAC dipakai (set JWT_JWKS_URL/JWT_JWKS_FILE dan JWT_ALLOW_HMAC=false untuk JWKS saja)")
+	}
+	if !allowHMAC && !useJWKS {
+		return nil, fmt.Errorf("error: JWT_JWKS_URL atau JWT_JWKS_FILE harus di-set jika JWT_ALLOW_HMAC=false")
+	}
+	jwksRefresh, err := time.ParseDuration(getEnv("JWT_JWKS_REFRESH", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JWT_JWKS_REFRESH: %w", err)
+	}
+	// Nilai default mengikuti token Supabase Auth; set ke string kosong untuk menonaktifkan pemeriksaan
+	jwtIssuer := getEnv("JWT_ISSUER", strings.TrimRight(supabaseProjectURL, "/")+"/auth/v1")
+	jwtAudience := getEnv("JWT_AUDIENCE", "authenticated")
+
 	dbPort, err := strconv.Atoi(dbPortStr)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing DB_PORT: %w", err)
 	}
 
+	storageDriver := getEnv("STORAGE_DRIVER", "local")
+	storageLocalDir := getEnv("STORAGE_LOCAL_DIR", "./uploads")
+	// Untuk driver s3, URL publik kosong berarti memakai URL objek di bucket
+	defaultPublicURL := ""
+	if storageDriver == "local" {
+		defaultPublicURL = "http://localhost:" + appPort + "/uploads"
+	}
+	storagePublicURL := getEnv("STORAGE_PUBLIC_URL", defaultPublicURL)
+
+	s3UsePathStyle, err := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing S3_USE_PATH_STYLE: %w", err)
+	}
+
+	chapterReleaseInterval, err := time.ParseDuration(getEnv("CHAPTER_RELEASE_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CHAPTER_RELEASE_INTERVAL: %w", err)
+	}
+
+	viewDedupWindow, err := time.ParseDuration(getEnv("VIEW_DEDUP_WINDOW", "30m"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing VIEW_DEDUP_WINDOW: %w", err)
+	}
+	trendingRefreshInterval, err := time.ParseDuration(getEnv("TRENDING_REFRESH_INTERVAL", "10m"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing TRENDING_REFRESH_INTERVAL: %w", err)
+	}
+
+	recommendationInterval, err := time.ParseDuration(getEnv("RECOMMENDATION_INTERVAL", "6h"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing RECOMMENDATION_INTERVAL: %w", err)
+	}
+
+	smtpPort, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SMTP_PORT: %w", err)
+	}
+
 	return &Config{
-		AppPort:            appPort,
-		SupabaseProjectURL: supabaseProjectURL,
-		SupabaseAnonKey:    supabaseAnonKey,
-		SupabaseJWTSecret:  supabaseJWTSecret,
-		DBHost:             dbHost,
-		DBPort:             dbPort,
-		DBUser:             dbUser,
-		DBPassword:         dbPassword,
-		DBName:             dbName,
-		DBSSLMode:          dbSSLMode,
+		AppPort:                appPort,
+		SupabaseProjectURL:     supabaseProjectURL,
+		SupabaseAnonKey:        supabaseAnonKey,
+		SupabaseJWTSecret:      supabaseJWTSecret,
+		SupabaseServiceRoleKey: getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
+		JWTJWKSURL:             jwksURL,
+		JWTJWKSFile:            jwksFile,
+		JWTJWKSRefresh:         jwksRefresh,
+		JWTIssuer:              jwtIssuer,
+		JWTAudience:            jwtAudience,
+		JWTAllowHMAC:           allowHMAC,
+		DBHost:                 dbHost,
+		DBPort:                 dbPort,
+		DBUser:                 dbUser,
+		DBPassword:             dbPassword,
+		DBName:                 dbName,
+		DBSSLMode:              dbSSLMode,
+		StorageDriver:          storageDriver,
+		StorageLocalDir:        storageLocalDir,
+		StoragePublicURL:       storagePublicURL,
+		S3Endpoint:             getEnv("S3_ENDPOINT", ""),
+		S3Region:               getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:               getEnv("S3_BUCKET", ""),
+		S3AccessKey:            getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:            getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle:         s3UsePathStyle,
+		ChapterReleaseInterval: chapterReleaseInterval,
+		TrustedProxies:         splitList(getEnv("TRUSTED_PROXIES", "")),
+		ViewDedupWindow:        viewDedupWindow,
+		TrendingInterval:       trendingRefreshInterval,
+		RecommendationInterval: recommendationInterval,
+		FrontendURL:            getEnv("FRONTEND_URL", "http://localhost:5173"),
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               smtpPort,
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:               getEnv("SMTP_FROM", "WebKomik <no-reply@localhost>"),
+		VAPIDPrivateKey:        getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:           getEnv("VAPID_SUBJECT", "mailto:admin@localhost"),
 	}, nil
 }
 
@@ -79,3 +220,14 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// splitList memecah daftar yang dipisahkan koma dan membuang elemen kosong.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDuplicateChapterNumber dikembalikan jika nomor chapter sudah dipakai chapter lain pada komik yang sama.
+var ErrDuplicateChapterNumber = errors.New("nomor chapter sudah digunakan untuk komik ini")
+
+// chapterColumns adalah kolom chapter yang dipilih, dengan alias ch untuk tabel chapters.
+const chapterColumns = `ch.id, ch.comic_id, ch.chapter_number, ch.title, ch.status, ch.review_note,
+	ch.publish_at, ch.published_at, ch.created_at, ch.updated_at`
+
+// releasedChapterCondition adalah kondisi SQL untuk chapter yang sudah tampil untuk publik (alias ch).
+// Chapter terjadwal dianggap rilis begitu publish_at lewat, tanpa menunggu scheduler mengisi published_at.
+const releasedChapterCondition = `(ch.status = 'published' AND (ch.published_at IS NOT NULL OR ch.publish_at <= NOW()))`
+
+// scanChapter membaca satu baris hasil query yang memakai chapterColumns.
+func scanChapter(row pgx.Row) (*models.Chapter, error) {
+	var ch models.Chapter
+	err := row.Scan(
+		&ch.ID,
+		&ch.ComicID,
+		&ch.ChapterNumber,
+		&ch.Title,
+		&ch.Status,
+		&ch.ReviewNote,
+		&ch.PublishAt,
+		&ch.PublishedAt,
+		&ch.CreatedAt,
+		&ch.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+// GetChapterByNumber mengambil satu chapter berdasarkan comicID dan nomor chapternya.
+// Mengembalikan nil, nil jika chapter tidak ditemukan atau komiknya berada di sampah.
+func GetChapterByNumber(ctx context.Context, comicID int64, chapterNumber float32) (*models.Chapter, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM chapters ch
+		JOIN comics c ON c.id = ch.comic_id AND c.deleted_at IS NULL
+		WHERE ch.comic_id = $1 AND ch.chapter_number = $2;
+	`, chapterColumns)
+	ch, err := scanChapter(DB.QueryRow(ctx, query, comicID, chapterNumber))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Chapter tidak ditemukan, bukan error server
+		}
+		return nil, fmt.Errorf("gagal query GetChapterByNumber: %w", err)
+	}
+	return ch, nil
+}
+
+// CreateChapter menyimpan chapter baru untuk sebuah komik.
+// Kolom updated_at komik ikut diperbarui agar komik naik di urutan "terakhir diperbarui".
+func CreateChapter(ctx context.Context, comicID int64, input models.Chapter) (*models.Chapter, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi CreateChapter: %w", err)
+	}
+	defer tx.Rollback(ctx) // Tidak berpengaruh jika transaksi sudah di-commit
+
+	created, err := insertChapter(ctx, tx, comicID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi CreateChapter: %w", err)
+	}
+	return created, nil
+}
+
+// ReserveChapterID mengambil ID chapter berikutnya dari sequence tanpa menyimpan chapter, sehingga file
+// halaman bisa diunggah ke storage dengan key chapter tersebut sebelum transaksi dimulai.
+// ID yang tidak jadi dipakai hanya menyisakan celah pada urutan ID.
+func ReserveChapterID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := DB.QueryRow(ctx, "SELECT nextval(pg_get_serial_sequence('chapters', 'id'))").Scan(&id); err != nil {
+		return 0, fmt.Errorf("gagal memesan ID chapter: %w", err)
+	}
+	return id, nil
+}
+
+// CreateChapterWithPages membuat chapter dengan ID yang sudah dipesan lewat ReserveChapterID beserta
+// halamannya dalam satu transaksi. Halaman harus sudah tersimpan di storage; transaksi hanya berisi insert
+// agar koneksi database tidak tertahan selama upload.
+func CreateChapterWithPages(ctx context.Context, chapterID, comicID int64, input models.Chapter, pages []models.Page) (*models.Chapter, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi CreateChapterWithPages: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	input.ID = chapterID
+	created, err := insertChapter(ctx, tx, comicID, input)
+	if err != nil {
+		return nil, err
+	}
+	created.Pages, err = insertPages(ctx, tx, created.ID, pages)
+	if err != nil {
+		return nil, err
+	}
+	created.PageCount = len(created.Pages)
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi CreateChapterWithPages: %w", err)
+	}
+	return created, nil
+}
+
+// insertChapter menyisipkan chapter dan memperbarui updated_at komik menggunakan transaksi yang diberikan.
+// Jika input.ID diisi (ID yang sudah dipesan), chapter disimpan dengan ID tersebut.
+func insertChapter(ctx context.Context, tx pgx.Tx, comicID int64, input models.Chapter) (*models.Chapter, error) {
+	idValue := "DEFAULT"
+	args := []interface{}{comicID, input.ChapterNumber, input.Title, input.PublishAt}
+	if input.ID != 0 {
+		args = append(args, input.ID)
+		idValue = fmt.Sprintf("$%d", len(args))
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO chapters AS ch (id, comic_id, chapter_number, title, publish_at)
+		VALUES (%s, $1, $2, $3, $4)
+		RETURNING %s;
+	`, idValue, chapterColumns)
+	created, err := scanChapter(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return nil, ErrDuplicateChapterNumber
+		}
+		return nil, fmt.Errorf("gagal membuat chapter di database: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE comics SET updated_at = $1 WHERE id = $2", time.Now(), comicID); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui updated_at komik: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateChapter memperbarui chapter yang sudah ada.
+// updates hanya berisi field yang ingin diubah ("chapter_number", "title", dan/atau "publish_at").
+func UpdateChapter(ctx context.Context, chapterID int64, updates map[string]interface{}) (*models.Chapter, error) {
+	setClauses := ""
+	values := []interface{}{}
+	paramCounter := 1
+
+	if chapterNumber, ok := updates["chapter_number"].(float32); ok {
+		setClauses += fmt.Sprintf("chapter_number = $%d, ", paramCounter)
+		values = append(values, chapterNumber)
+		paramCounter++
+	}
+
+	if title, ok := updates["title"].(*string); ok {
+		setClauses += fmt.Sprintf("title = $%d, ", paramCounter)
+		values = append(values, title)
+		paramCounter++
+	}
+
+	if publishAt, ok := updates["publish_at"].(*time.Time); ok {
+		setClauses += fmt.Sprintf("publish_at = $%d, ", paramCounter)
+		values = append(values, publishAt)
+		paramCounter++
+	}
+
+	// Selalu update kolom updated_at
+	setClauses += fmt.Sprintf("updated_at = $%d", paramCounter)
+	values = append(values, time.Now())
+	paramCounter++
+
+	values = append(values, chapterID)
+
+	query := fmt.Sprintf(`
+		UPDATE chapters AS ch
+		SET %s
+		WHERE ch.id = $%d
+		RETURNING %s;
+	`, setClauses, paramCounter, chapterColumns)
+
+	updated, err := scanChapter(DB.QueryRow(ctx, query, values...))
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return nil, ErrDuplicateChapterNumber
+		}
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("chapter dengan ID %d tidak ditemukan", chapterID)
+		}
+		return nil, fmt.Errorf("gagal memperbarui chapter di database: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteChapter menghapus chapter beserta seluruh halamannya. Mengembalikan URL gambar halaman yang dihapus
+// agar file-nya bisa dihapus dari storage setelah transaksi selesai.
+func DeleteChapter(ctx context.Context, chapterID int64) ([]string, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi DeleteChapter: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Hapus halaman lebih dulu agar tidak bergantung pada ON DELETE CASCADE di skema lama
+	rows, err := tx.Query(ctx, "DELETE FROM pages WHERE chapter_id = $1 RETURNING image_url", chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghapus halaman chapter: %w", err)
+	}
+	imageURLs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghapus halaman chapter: %w", err)
+	}
+	tag, err := tx.Exec(ctx, "DELETE FROM chapters WHERE id = $1", chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghapus chapter: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("chapter dengan ID %d tidak ditemukan", chapterID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi DeleteChapter: %w", err)
+	}
+	return imageURLs, nil
+}
+
+// GetChapterByID mengambil satu chapter berdasarkan ID.
+// Mengembalikan nil, nil jika chapter tidak ditemukan atau komiknya berada di sampah.
+func GetChapterByID(ctx context.Context, chapterID int64) (*models.Chapter, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM chapters ch
+		JOIN comics c ON c.id = ch.comic_id AND c.deleted_at IS NULL
+		WHERE ch.id = $1;
+	`, chapterColumns)
+	ch, err := scanChapter(DB.QueryRow(ctx, query, chapterID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Chapter tidak ditemukan, bukan error server
+		}
+		return nil, fmt.Errorf("gagal query GetChapterByID: %w", err)
+	}
+	return ch, nil
+}
+
+// GetAdjacentChapters mengambil chapter sebelum dan sesudah chapter tertentu dalam satu komik,
+// berdasarkan urutan chapter_number (sehingga chapter ekstra seperti 10.5 berada di antara 10 dan 11).
+// Jika publishedOnly bernilai true, chapter yang belum rilis untuk publik dilewati.
+// Nilai nil berarti tidak ada chapter sebelum/sesudahnya.
+func GetAdjacentChapters(ctx context.Context, chapter *models.Chapter, publishedOnly bool) (prev, next *models.ChapterRef, err error) {
+	// Perbandingan memakai nilai chapter_number yang tersimpan di database, bukan nilai float dari Go,
+	// agar tidak terpengaruh pembulatan float saat dikirim sebagai parameter.
+	query := fmt.Sprintf(`
+		WITH cur AS (
+			SELECT comic_id, chapter_number FROM chapters WHERE id = $1
+		)
+		SELECT p.id, p.chapter_number, p.title, n.id, n.chapter_number, n.title
+		FROM cur
+		LEFT JOIN LATERAL (
+			SELECT ch.id, ch.chapter_number, ch.title FROM chapters ch
+			WHERE ch.comic_id = cur.comic_id AND ch.chapter_number < cur.chapter_number
+				AND (NOT $2 OR %[1]s)
+			ORDER BY ch.chapter_number DESC
+			LIMIT 1
+		) p ON true
+		LEFT JOIN LATERAL (
+			SELECT ch.id, ch.chapter_number, ch.title FROM chapters ch
+			WHERE ch.comic_id = cur.comic_id AND ch.chapter_number > cur.chapter_number
+				AND (NOT $2 OR %[1]s)
+			ORDER BY ch.chapter_number ASC
+			LIMIT 1
+		) n ON true;
+	`, releasedChapterCondition)
+	var (
+		prevID, nextID         *int64
+		prevNumber, nextNumber *float32
+		prevTitle, nextTitle   *string
+	)
+	err = DB.QueryRow(ctx, query, chapter.ID, publishedOnly).Scan(&prevID, &prevNumber, &prevTitle, &nextID, &nextNumber, &nextTitle)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, nil // Chapter sudah tidak ada
+		}
+		return nil, nil, fmt.Errorf("gagal query GetAdjacentChapters: %w", err)
+	}
+
+	if prevID != nil {
+		prev = &models.ChapterRef{ID: *prevID, ChapterNumber: *prevNumber, Title: prevTitle}
+	}
+	if nextID != nil {
+		next = &models.ChapterRef{ID: *nextID, ChapterNumber: *nextNumber, Title: nextTitle}
+	}
+	return prev, next, nil
+}
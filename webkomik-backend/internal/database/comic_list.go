@@ -0,0 +1,357 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// Opsi pengurutan yang didukung oleh ListComics.
+const (
+	ComicSortCreatedAt  = "created_at"
+	ComicSortUpdatedAt  = "updated_at"
+	ComicSortTitle      = "title"
+	ComicSortPopularity = "popularity"
+	// ComicSortSubmittedAt hanya dipakai antrean review; komik di luar status in_review bisa tidak memiliki submitted_at.
+	ComicSortSubmittedAt = "submitted_at"
+)
+
+// Arah cursor untuk keyset pagination.
+const (
+	cursorDirNext = "next"
+	cursorDirPrev = "prev"
+)
+
+// ErrInvalidCursor dikembalikan jika cursor tidak bisa didekode atau tidak cocok dengan sort yang diminta.
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// comicSortColumns memetakan opsi sort ke kolom SQL yang aman dipakai di query.
+var comicSortColumns = map[string]string{
+	ComicSortCreatedAt:   "c.created_at",
+	ComicSortUpdatedAt:   "c.updated_at",
+	ComicSortTitle:       "c.title",
+	ComicSortPopularity:  "c.view_count",
+	ComicSortSubmittedAt: "c.submitted_at",
+}
+
+// ComicListParams berisi parameter filter, sort, dan paginasi untuk ListComics.
+// Jika Cursor diisi, Offset diabaikan dan paginasi memakai keyset.
+type ComicListParams struct {
+	Limit  int
+	Offset int
+	Cursor string
+
+	GenreIDs      []int64
+	GenreMatchAll bool // true: komik harus memiliki semua genre di GenreIDs, false: cukup salah satu
+	TagSlugs      []string
+	TagMatchAll   bool // true: komik harus memiliki semua tag di TagSlugs, false: cukup salah satu
+	AuthorName    *string
+	MemberID      *string  // ID pengguna yang menjadi anggota tim komik
+	Statuses      []string // Status publikasi yang ditampilkan; kosong berarti semua status
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	UpdatedFrom   *time.Time
+	UpdatedTo     *time.Time
+
+	Sort string // Salah satu ComicSort*
+	Desc bool
+
+	Trashed bool // true untuk daftar komik di sampah (soft-deleted) alih-alih komik aktif
+}
+
+// ComicListResult adalah hasil ListComics beserta metadata paginasi.
+type ComicListResult struct {
+	Comics     []models.Comic
+	Total      int64
+	NextCursor *string
+	PrevCursor *string
+	HasMore    bool
+}
+
+// comicCursor adalah isi cursor keyset sebelum di-encode ke base64.
+type comicCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"o"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+	Dir   string `json:"d"`
+}
+
+// IsValidComicSort memeriksa apakah opsi sort didukung.
+func IsValidComicSort(sort string) bool {
+	_, ok := comicSortColumns[sort]
+	return ok
+}
+
+// ListComics mengambil daftar komik dengan filter, sort, dan paginasi (offset atau keyset).
+func ListComics(ctx context.Context, params ComicListParams) (*ComicListResult, error) {
+	sortColumn, ok := comicSortColumns[params.Sort]
+	if !ok {
+		return nil, fmt.Errorf("opsi sort tidak dikenal: %s", params.Sort)
+	}
+
+	// 1. Bangun klausa WHERE dari filter
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if params.Trashed {
+		conditions = append(conditions, "c.deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "c.deleted_at IS NULL")
+	}
+	if len(params.GenreIDs) > 0 {
+		if params.GenreMatchAll {
+			args = append(args, params.GenreIDs)
+			conditions = append(conditions, fmt.Sprintf(
+				"(SELECT COUNT(DISTINCT cg.genre_id) FROM comic_genres cg WHERE cg.comic_id = c.id AND cg.genre_id = ANY($%d)) = %d",
+				len(args), len(params.GenreIDs)))
+		} else {
+			addCondition("EXISTS (SELECT 1 FROM comic_genres cg WHERE cg.comic_id = c.id AND cg.genre_id = ANY($%d))", params.GenreIDs)
+		}
+	}
+	if len(params.TagSlugs) > 0 {
+		if params.TagMatchAll {
+			args = append(args, params.TagSlugs)
+			conditions = append(conditions, fmt.Sprintf(
+				"(SELECT COUNT(DISTINCT t.id) FROM comic_tags ct JOIN tags t ON t.id = ct.tag_id WHERE ct.comic_id = c.id AND t.slug = ANY($%d)) = %d",
+				len(args), len(params.TagSlugs)))
+		} else {
+			addCondition("EXISTS (SELECT 1 FROM comic_tags ct JOIN tags t ON t.id = ct.tag_id WHERE ct.comic_id = c.id AND t.slug = ANY($%d))", params.TagSlugs)
+		}
+	}
+	if params.AuthorName != nil {
+		addCondition("lower(c.author_name) = lower($%d)", *params.AuthorName)
+	}
+	if params.MemberID != nil {
+		addCondition("EXISTS (SELECT 1 FROM comic_members cm WHERE cm.comic_id = c.id AND cm.user_id = $%d::uuid)", *params.MemberID)
+	}
+	if len(params.Statuses) > 0 {
+		addCondition("c.status = ANY($%d)", params.Statuses)
+	}
+	if params.CreatedFrom != nil {
+		addCondition("c.created_at >= $%d", *params.CreatedFrom)
+	}
+	if params.CreatedTo != nil {
+		addCondition("c.created_at < $%d", *params.CreatedTo)
+	}
+	if params.UpdatedFrom != nil {
+		addCondition("c.updated_at >= $%d", *params.UpdatedFrom)
+	}
+	if params.UpdatedTo != nil {
+		addCondition("c.updated_at < $%d", *params.UpdatedTo)
+	}
+
+	filterClause := ""
+	if len(conditions) > 0 {
+		filterClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// 2. Hitung total baris yang cocok dengan filter (tanpa kondisi cursor)
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM comics c %s;", filterClause)
+	if err := DB.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("gagal menghitung total komik: %w", err)
+	}
+
+	// 3. Tambahkan kondisi keyset jika cursor diberikan
+	dir := cursorDirNext
+	if params.Cursor != "" {
+		cur, err := decodeComicCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cur.Sort != params.Sort || cur.Desc != params.Desc {
+			return nil, ErrInvalidCursor
+		}
+		value, err := cur.typedValue()
+		if err != nil {
+			return nil, err
+		}
+		dir = cur.Dir
+
+		// Untuk urutan DESC, halaman "next" berisi nilai yang lebih kecil dari cursor.
+		op := ">"
+		if params.Desc != (dir == cursorDirPrev) {
+			op = "<"
+		}
+		args = append(args, value, cur.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, c.id) %s ($%d, $%d)", sortColumn, op, len(args)-1, len(args)))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Halaman "prev" diambil dengan urutan terbalik lalu dibalik lagi di Go.
+	orderDir := "ASC"
+	if params.Desc != (dir == cursorDirPrev) {
+		orderDir = "DESC"
+	}
+
+	// Ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya.
+	args = append(args, params.Limit+1)
+	limitClause := fmt.Sprintf("LIMIT $%d", len(args))
+	if params.Cursor == "" && params.Offset > 0 {
+		args = append(args, params.Offset)
+		limitClause += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			c.id, c.title, c.description, c.author_name,
+			%s AS genres, %s AS tags,
+			c.cover_image_url, c.view_count, c.rating_average, c.rating_count,
+			c.status, c.review_note, c.published_at, c.submitted_at,
+			c.created_at, c.updated_at, c.deleted_at
+		FROM comics c
+		%s
+		ORDER BY %s %s, c.id %s
+		%s;
+	`, comicGenresColumn("c"), comicTagsColumn("c"), whereClause, sortColumn, orderDir, orderDir, limitClause)
+
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menjalankan query ListComics: %w", err)
+	}
+	defer rows.Close()
+
+	comics := []models.Comic{}
+	for rows.Next() {
+		var comic models.Comic
+		err := rows.Scan(
+			&comic.ID,
+			&comic.Title,
+			&comic.Description,
+			&comic.AuthorName,
+			&comic.Genres,
+			&comic.Tags,
+			&comic.CoverImageURL,
+			&comic.ViewCount,
+			&comic.RatingAverage,
+			&comic.RatingCount,
+			&comic.Status,
+			&comic.ReviewNote,
+			&comic.PublishedAt,
+			&comic.SubmittedAt,
+			&comic.CreatedAt,
+			&comic.UpdatedAt,
+			&comic.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris komik: %w", err)
+		}
+		comics = append(comics, comic)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris komik: %w", err)
+	}
+
+	// 4. Potong baris ekstra dan susun kembali urutan untuk halaman "prev"
+	hasMore := len(comics) > params.Limit
+	if hasMore {
+		comics = comics[:params.Limit]
+	}
+	if dir == cursorDirPrev {
+		for i, j := 0, len(comics)-1; i < j; i, j = i+1, j-1 {
+			comics[i], comics[j] = comics[j], comics[i]
+		}
+	}
+
+	result := &ComicListResult{Comics: comics, Total: total}
+	if len(comics) == 0 {
+		return result, nil
+	}
+
+	// 5. Tentukan apakah ada halaman sebelum/sesudah hasil ini
+	hasNext, hasPrev := hasMore, false
+	switch {
+	case dir == cursorDirPrev:
+		hasNext, hasPrev = true, hasMore
+	case params.Cursor != "":
+		hasPrev = true
+	default:
+		hasPrev = params.Offset > 0
+	}
+	result.HasMore = hasNext
+
+	if hasNext {
+		next := encodeComicCursor(params, comics[len(comics)-1], cursorDirNext)
+		result.NextCursor = &next
+	}
+	if hasPrev {
+		prev := encodeComicCursor(params, comics[0], cursorDirPrev)
+		result.PrevCursor = &prev
+	}
+
+	return result, nil
+}
+
+// encodeComicCursor membuat cursor keyset dari posisi sebuah komik pada urutan yang diminta.
+func encodeComicCursor(params ComicListParams, comic models.Comic, dir string) string {
+	cur := comicCursor{Sort: params.Sort, Desc: params.Desc, ID: comic.ID, Dir: dir}
+	switch params.Sort {
+	case ComicSortCreatedAt:
+		cur.Value = comic.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case ComicSortUpdatedAt:
+		cur.Value = comic.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case ComicSortTitle:
+		cur.Value = comic.Title
+	case ComicSortPopularity:
+		cur.Value = strconv.FormatInt(comic.ViewCount, 10)
+	case ComicSortSubmittedAt:
+		if comic.SubmittedAt != nil {
+			cur.Value = comic.SubmittedAt.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	raw, _ := json.Marshal(cur) // Struct sederhana, tidak mungkin gagal
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeComicCursor mengurai cursor base64 menjadi comicCursor.
+func decodeComicCursor(s string) (*comicCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur comicCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cur.Dir != cursorDirNext && cur.Dir != cursorDirPrev {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// typedValue mengubah nilai cursor ke tipe Go yang sesuai dengan kolom sort.
+func (cur *comicCursor) typedValue() (interface{}, error) {
+	switch cur.Sort {
+	case ComicSortCreatedAt, ComicSortUpdatedAt, ComicSortSubmittedAt:
+		t, err := time.Parse(time.RFC3339Nano, cur.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case ComicSortTitle:
+		return cur.Value, nil
+	case ComicSortPopularity:
+		n, err := strconv.ParseInt(cur.Value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return n, nil
+	}
+	return nil, ErrInvalidCursor
+}
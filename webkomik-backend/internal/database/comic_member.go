@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// InvitationTTL adalah masa berlaku undangan anggota tim.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Error sentinel untuk operasi anggota tim komik.
+var (
+	ErrAlreadyMember       = errors.New("pengguna sudah menjadi anggota tim komik")
+	ErrDuplicateInvitation = errors.New("undangan untuk pengguna ini masih menunggu jawaban")
+	ErrLastOwner           = errors.New("komik harus memiliki minimal satu owner")
+	ErrInvitationClosed    = errors.New("undangan sudah tidak berlaku")
+)
+
+// GetComicMembers mengambil seluruh anggota tim sebuah komik, owner lebih dulu.
+func GetComicMembers(ctx context.Context, comicID int64) ([]models.ComicMember, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT m.comic_id, m.user_id::text, u.email, m.role, m.added_by::text, m.created_at, m.updated_at
+		FROM comic_members m
+		LEFT JOIN user_accounts u ON u.id = m.user_id
+		WHERE m.comic_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, m.created_at;
+	`, comicID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query GetComicMembers: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.ComicMember{}
+	for rows.Next() {
+		var m models.ComicMember
+		if err := rows.Scan(&m.ComicID, &m.UserID, &m.Email, &m.Role, &m.AddedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("gagal scan baris anggota komik: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris anggota komik: %w", err)
+	}
+	return members, nil
+}
+
+// UpdateComicMemberRole mengubah peran anggota tim. Mengembalikan false jika anggota tidak ditemukan,
+// atau ErrLastOwner jika perubahan akan membuat komik tanpa owner.
+func UpdateComicMemberRole(ctx context.Context, comicID int64, userID, role string) (bool, error) {
+	return changeComicMember(ctx, comicID, userID, func(tx pgx.Tx) (int64, error) {
+		tag, err := tx.Exec(ctx, `
+			UPDATE comic_members SET role = $3, updated_at = NOW()
+			WHERE comic_id = $1 AND user_id = $2::uuid;
+		`, comicID, userID, role)
+		return tag.RowsAffected(), err
+	})
+}
+
+// RemoveComicMember mengeluarkan anggota dari tim. Mengembalikan false jika anggota tidak ditemukan,
+// atau ErrLastOwner jika yang dikeluarkan adalah owner terakhir.
+func RemoveComicMember(ctx context.Context, comicID int64, userID string) (bool, error) {
+	return changeComicMember(ctx, comicID, userID, func(tx pgx.Tx) (int64, error) {
+		tag, err := tx.Exec(ctx, "DELETE FROM comic_members WHERE comic_id = $1 AND user_id = $2::uuid", comicID, userID)
+		return tag.RowsAffected(), err
+	})
+}
+
+// changeComicMember menjalankan perubahan anggota dalam transaksi dan memastikan komik tetap memiliki owner.
+func changeComicMember(ctx context.Context, comicID int64, userID string, change func(tx pgx.Tx) (int64, error)) (bool, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi perubahan anggota: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	// Kunci baris komik agar dua perubahan bersamaan tidak sama-sama menghapus owner terakhir
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM comics WHERE id = $1 FOR UPDATE", comicID); err != nil {
+		return false, fmt.Errorf("gagal mengunci komik: %w", err)
+	}
+
+	affected, err := change(tx)
+	if err != nil {
+		return false, fmt.Errorf("gagal mengubah anggota komik: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	var owners int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM comic_members WHERE comic_id = $1 AND role = 'owner'", comicID).Scan(&owners); err != nil {
+		return false, fmt.Errorf("gagal menghitung owner komik: %w", err)
+	}
+	if owners == 0 {
+		return true, ErrLastOwner
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit perubahan anggota: %w", err)
+	}
+	return true, nil
+}
+
+// addComicMember menambahkan anggota tim di dalam transaksi. Anggota yang sudah ada tidak diubah.
+func addComicMember(ctx context.Context, tx pgx.Tx, comicID int64, userID, role string, addedBy *string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO comic_members (comic_id, user_id, role, added_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (comic_id, user_id) DO NOTHING;
+	`, comicID, userID, role, addedBy)
+	if err != nil {
+		return fmt.Errorf("gagal menambahkan anggota komik: %w", err)
+	}
+	return nil
+}
+
+// invitationColumns adalah kolom undangan yang dipilih, dengan alias i untuk comic_invitations dan c untuk comics.
+const invitationColumns = `
+	i.id, i.comic_id, c.title, i.invitee_user_id::text, i.invitee_email, i.role, i.invited_by::text,
+	CASE WHEN i.status = 'pending' AND i.expires_at <= NOW() THEN 'expired' ELSE i.status END,
+	i.expires_at, i.created_at, i.responded_at`
+
+// scanInvitation membaca satu baris hasil query yang memakai invitationColumns.
+func scanInvitation(row pgx.Row) (*models.ComicInvitation, error) {
+	var inv models.ComicInvitation
+	err := row.Scan(
+		&inv.ID,
+		&inv.ComicID,
+		&inv.ComicTitle,
+		&inv.InviteeUserID,
+		&inv.InviteeEmail,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.CreatedAt,
+		&inv.RespondedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// queryInvitations menjalankan query undangan dan membaca seluruh barisnya.
+func queryInvitations(ctx context.Context, query string, args ...interface{}) ([]models.ComicInvitation, error) {
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query undangan komik: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.ComicInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris undangan: %w", err)
+		}
+		invitations = append(invitations, *inv)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris undangan: %w", err)
+	}
+	return invitations, nil
+}
+
+// CreateComicInvitation membuat undangan anggota tim untuk inviteeUserID atau inviteeEmail.
+// Undangan lewat email tidak dicocokkan ke akun saat dibuat; penerimanya baru ditentukan saat undangan
+// dijawab oleh pengguna yang email terkonfirmasinya sama (lihat RespondComicInvitation).
+func CreateComicInvitation(ctx context.Context, comicID int64, inviteeUserID, inviteeEmail *string, role, invitedBy string) (*models.ComicInvitation, error) {
+	if inviteeUserID != nil {
+		var isMember bool
+		err := DB.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM comic_members WHERE comic_id = $1 AND user_id = $2::uuid)", comicID, *inviteeUserID).Scan(&isMember)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memeriksa anggota komik: %w", err)
+		}
+		if isMember {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	// Undangan pending yang sudah kedaluwarsa ditutup dulu agar tidak menghalangi undangan baru
+	_, err := DB.Exec(ctx, `
+		UPDATE comic_invitations SET status = 'revoked', responded_at = NOW()
+		WHERE comic_id = $1 AND status = 'pending' AND expires_at <= NOW();
+	`, comicID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menutup undangan kedaluwarsa: %w", err)
+	}
+
+	var id int64
+	err = DB.QueryRow(ctx, `
+		INSERT INTO comic_invitations (comic_id, invitee_user_id, invitee_email, role, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`, comicID, inviteeUserID, inviteeEmail, role, invitedBy, time.Now().Add(InvitationTTL)).Scan(&id)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return nil, ErrDuplicateInvitation
+		}
+		return nil, fmt.Errorf("gagal membuat undangan: %w", err)
+	}
+	return GetComicInvitation(ctx, id)
+}
+
+// GetComicInvitation mengambil satu undangan. Mengembalikan nil jika tidak ditemukan.
+func GetComicInvitation(ctx context.Context, id int64) (*models.ComicInvitation, error) {
+	query := fmt.Sprintf("SELECT %s FROM comic_invitations i JOIN comics c ON c.id = i.comic_id WHERE i.id = $1;", invitationColumns)
+	inv, err := scanInvitation(DB.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query GetComicInvitation: %w", err)
+	}
+	return inv, nil
+}
+
+// ListComicInvitations mengambil undangan yang masih menunggu jawaban untuk sebuah komik.
+func ListComicInvitations(ctx context.Context, comicID int64) ([]models.ComicInvitation, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM comic_invitations i JOIN comics c ON c.id = i.comic_id
+		WHERE i.comic_id = $1 AND i.status = 'pending' AND i.expires_at > NOW()
+		ORDER BY i.created_at DESC;
+	`, invitationColumns)
+	return queryInvitations(ctx, query, comicID)
+}
+
+// ListUserInvitations mengambil undangan yang masih berlaku untuk pengguna, dicocokkan lewat ID atau email.
+// email harus email yang sudah dikonfirmasi di identity provider, atau kosong jika belum ada.
+func ListUserInvitations(ctx context.Context, userID, email string) ([]models.ComicInvitation, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM comic_invitations i JOIN comics c ON c.id = i.comic_id
+		WHERE i.status = 'pending' AND i.expires_at > NOW() AND c.deleted_at IS NULL
+			AND (i.invitee_user_id = $1::uuid OR ($2 <> '' AND lower(i.invitee_email) = lower($2)))
+		ORDER BY i.created_at DESC;
+	`, invitationColumns)
+	return queryInvitations(ctx, query, userID, email)
+}
+
+// RevokeComicInvitation membatalkan undangan pending. Mengembalikan false jika undangan tidak ditemukan.
+func RevokeComicInvitation(ctx context.Context, comicID, invitationID int64) (bool, error) {
+	tag, err := DB.Exec(ctx, `
+		UPDATE comic_invitations SET status = 'revoked', responded_at = NOW()
+		WHERE id = $1 AND comic_id = $2 AND status = 'pending';
+	`, invitationID, comicID)
+	if err != nil {
+		return false, fmt.Errorf("gagal membatalkan undangan: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RespondComicInvitation menerima atau menolak undangan atas nama pengguna yang diundang. Seperti
+// ListUserInvitations, email harus email yang sudah dikonfirmasi di identity provider.
+// Mengembalikan nil jika undangan tidak ditemukan atau bukan untuk pengguna ini,
+// dan ErrInvitationClosed jika undangan sudah dijawab, dibatalkan, atau kedaluwarsa.
+func RespondComicInvitation(ctx context.Context, invitationID int64, userID, email string, accept bool) (*models.ComicInvitation, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi RespondComicInvitation: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	var (
+		comicID int64
+		role    string
+		open    bool
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT i.comic_id, i.role, i.status = 'pending' AND i.expires_at > NOW() AND c.deleted_at IS NULL
+		FROM comic_invitations i JOIN comics c ON c.id = i.comic_id
+		WHERE i.id = $1 AND (i.invitee_user_id = $2::uuid OR ($3 <> '' AND lower(i.invitee_email) = lower($3)))
+		FOR UPDATE OF i;
+	`, invitationID, userID, email).Scan(&comicID, &role, &open)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil undangan: %w", err)
+	}
+	if !open {
+		return nil, ErrInvitationClosed
+	}
+
+	status := models.InvitationStatusDeclined
+	if accept {
+		status = models.InvitationStatusAccepted
+		var invitedBy string
+		if err := tx.QueryRow(ctx, "SELECT invited_by::text FROM comic_invitations WHERE id = $1", invitationID).Scan(&invitedBy); err != nil {
+			return nil, fmt.Errorf("gagal membaca pengundang: %w", err)
+		}
+		if err := addComicMember(ctx, tx, comicID, userID, role, &invitedBy); err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE comic_invitations SET status = $2, invitee_user_id = $3, responded_at = NOW()
+		WHERE id = $1;
+	`, invitationID, status, userID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memperbarui undangan: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi RespondComicInvitation: %w", err)
+	}
+	return GetComicInvitation(ctx, invitationID)
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// searchSimilarityThreshold adalah ambang word_similarity pg_trgm untuk pencocokan fuzzy.
+// Lebih rendah dari default (0.6) supaya salah ketik satu-dua huruf pada judul pendek tetap cocok.
+const searchSimilarityThreshold = 0.4
+
+// htmlEscapeSQL membungkus ekspresi teks SQL agar karakter khusus HTML di-escape. Judul dan deskripsi
+// berasal dari pengguna, jadi harus di-escape sebelum ts_headline menyisipkan tag <mark>.
+func htmlEscapeSQL(expr string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`, expr)
+}
+
+// SearchComics mencari komik berdasarkan kata kunci dengan gabungan full-text search dan similarity trigram.
+// Hanya komik berstatus published yang dicari. Hasil diurutkan berdasarkan relevansi
+// dan mengembalikan total hasil yang cocok untuk paginasi. Cuplikan highlight berupa HTML yang aman
+// dirender: teks asli di-escape dan hanya tag <mark> yang ditambahkan.
+func SearchComics(ctx context.Context, term string, limit, offset int) ([]models.ComicSearchResult, int64, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal memulai transaksi SearchComics: %w", err)
+	}
+	defer tx.Rollback(ctx) // Transaksi hanya dipakai untuk SET LOCAL, tidak ada perubahan data
+
+	// SET LOCAL tidak menerima parameter, nilai threshold berasal dari konstanta.
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %v", searchSimilarityThreshold)); err != nil {
+		return nil, 0, fmt.Errorf("gagal mengatur threshold similarity: %w", err)
+	}
+
+	// Query dibentuk dari tiga konfigurasi (indonesian, english, simple) lalu digabung dengan OR
+	// sehingga stemming kedua bahasa maupun kata yang tidak dikenal stemmer tetap bisa cocok.
+	query := fmt.Sprintf(`
+		WITH q AS (
+			SELECT websearch_to_tsquery('indonesian', $1)
+				|| websearch_to_tsquery('english', $1)
+				|| websearch_to_tsquery('simple', $1) AS tsq
+		), matched AS (
+			SELECT
+				c.id, c.title, c.description, c.author_name,
+				c.cover_image_url, c.view_count, c.rating_average, c.rating_count, c.created_at, c.updated_at,
+				ts_rank_cd(c.search_vector, q.tsq) AS text_rank,
+				GREATEST(
+					word_similarity($1, c.title),
+					word_similarity($1, coalesce(c.author_name, '')) * 0.8,
+					word_similarity($1, coalesce(c.description, '')) * 0.5
+				) AS fuzzy_rank
+			FROM comics c, q
+			WHERE c.deleted_at IS NULL AND c.status = 'published' AND (
+				c.search_vector @@ q.tsq
+				OR $1 <%% c.title
+				OR $1 <%% c.author_name
+				OR $1 <%% c.description
+			)
+		)
+		SELECT
+			m.id, m.title, m.description, m.author_name,
+			%s AS genres, %s AS tags,
+			m.cover_image_url, m.view_count, m.rating_average, m.rating_count, m.created_at, m.updated_at,
+			(m.text_rank + m.fuzzy_rank)::float8 AS rank,
+			ts_headline('indonesian', %s, q.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			CASE WHEN m.description IS NULL THEN NULL ELSE
+				ts_headline('indonesian', %s, q.tsq,
+					'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')
+			END,
+			COUNT(*) OVER () AS total
+		FROM matched m
+		CROSS JOIN q
+		ORDER BY rank DESC, m.id DESC
+		LIMIT $2 OFFSET $3;
+	`, comicGenresColumn("m"), comicTagsColumn("m"), htmlEscapeSQL("m.title"), htmlEscapeSQL("m.description"))
+
+	rows, err := tx.Query(ctx, query, term, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal menjalankan query SearchComics: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.ComicSearchResult{}
+	var total int64
+	for rows.Next() {
+		var r models.ComicSearchResult
+		err := rows.Scan(
+			&r.ID,
+			&r.Title,
+			&r.Description,
+			&r.AuthorName,
+			&r.Genres,
+			&r.Tags,
+			&r.CoverImageURL,
+			&r.ViewCount,
+			&r.RatingAverage,
+			&r.RatingCount,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+			&r.Rank,
+			&r.TitleHighlight,
+			&r.DescriptionHighlight,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal scan hasil pencarian komik: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterasi hasil pencarian komik: %w", err)
+	}
+
+	// COUNT(*) OVER () tidak tersedia jika halaman kosong; hitung ulang hanya bila offset melewati hasil.
+	if len(results) == 0 && offset > 0 {
+		countQuery := `
+			WITH q AS (
+				SELECT websearch_to_tsquery('indonesian', $1)
+					|| websearch_to_tsquery('english', $1)
+					|| websearch_to_tsquery('simple', $1) AS tsq
+			)
+			SELECT COUNT(*) FROM comics c, q
+			WHERE c.deleted_at IS NULL AND c.status = 'published' AND (
+				c.search_vector @@ q.tsq
+				OR $1 <% c.title
+				OR $1 <% c.author_name
+				OR $1 <% c.description
+			);
+		`
+		if err := tx.QueryRow(ctx, countQuery, term).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("gagal menghitung hasil pencarian komik: %w", err)
+		}
+	}
+
+	return results, total, nil
+}
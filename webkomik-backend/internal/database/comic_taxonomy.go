@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/slug"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUnknownGenre dikembalikan jika salah satu genre_id yang diberikan tidak ada di tabel genres.
+var ErrUnknownGenre = errors.New("genre tidak ditemukan")
+
+// querier adalah bagian dari pgxpool.Pool dan pgx.Tx yang dipakai fungsi-fungsi yang bisa berjalan di keduanya.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// comicGenresColumn mengembalikan ekspresi SQL berisi array JSON genre untuk komik dengan alias tabel tertentu.
+func comicGenresColumn(alias string) string {
+	return fmt.Sprintf(`COALESCE((
+			SELECT json_agg(json_build_object('id', g.id, 'name', g.name, 'slug', g.slug) ORDER BY g.display_order, g.name)
+			FROM comic_genres cg JOIN genres g ON g.id = cg.genre_id
+			WHERE cg.comic_id = %s.id
+		), '[]'::json)`, alias)
+}
+
+// comicTagsColumn mengembalikan ekspresi SQL berisi array nama tag untuk komik dengan alias tabel tertentu.
+func comicTagsColumn(alias string) string {
+	return fmt.Sprintf(`COALESCE((
+			SELECT array_agg(t.name ORDER BY t.name)
+			FROM comic_tags ct JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.comic_id = %s.id
+		), '{}'::text[])`, alias)
+}
+
+// loadComicTaxonomy mengisi Genres dan Tags sebuah komik.
+func loadComicTaxonomy(ctx context.Context, q querier, comic *models.Comic) error {
+	query := fmt.Sprintf("SELECT %s, %s FROM comics c WHERE c.id = $1;", comicGenresColumn("c"), comicTagsColumn("c"))
+	if err := q.QueryRow(ctx, query, comic.ID).Scan(&comic.Genres, &comic.Tags); err != nil {
+		return fmt.Errorf("gagal mengambil genre dan tag komik: %w", err)
+	}
+	return nil
+}
+
+// setComicGenres mengganti seluruh genre sebuah komik dengan genreIDs.
+func setComicGenres(ctx context.Context, tx pgx.Tx, comicID int64, genreIDs []int64) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM comic_genres WHERE comic_id = $1", comicID); err != nil {
+		return fmt.Errorf("gagal menghapus genre lama komik: %w", err)
+	}
+	for _, genreID := range genreIDs {
+		_, err := tx.Exec(ctx, "INSERT INTO comic_genres (comic_id, genre_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", comicID, genreID)
+		if err != nil {
+			if isPgError(err, pgForeignKeyViolation) {
+				return fmt.Errorf("%w: ID %d", ErrUnknownGenre, genreID)
+			}
+			return fmt.Errorf("gagal menyimpan genre komik: %w", err)
+		}
+	}
+	return nil
+}
+
+// setComicTags mengganti seluruh tag sebuah komik. Tag yang belum ada dibuat otomatis berdasarkan slug-nya.
+func setComicTags(ctx context.Context, tx pgx.Tx, comicID int64, tags []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM comic_tags WHERE comic_id = $1", comicID); err != nil {
+		return fmt.Errorf("gagal menghapus tag lama komik: %w", err)
+	}
+	for _, name := range NormalizeTags(tags) {
+		// DO UPDATE (tanpa perubahan berarti) dipakai agar RETURNING tetap mengembalikan id tag yang sudah ada
+		var tagID int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO tags (name, slug) VALUES ($1, $2)
+			ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
+			RETURNING id;
+		`, name, slug.Make(name)).Scan(&tagID)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan tag %q: %w", name, err)
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO comic_tags (comic_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", comicID, tagID); err != nil {
+			return fmt.Errorf("gagal menghubungkan tag %q ke komik: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NormalizeTags merapikan daftar tag: spasi berlebih dibuang, tag kosong diabaikan,
+// dan tag dengan slug yang sama hanya diambil sekali.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		name := strings.Join(strings.Fields(tag), " ")
+		s := slug.Make(name)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, name)
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrComicNotTrashed dikembalikan jika komik yang akan di-purge belum dipindahkan ke sampah.
+var ErrComicNotTrashed = errors.New("komik belum berada di sampah")
+
+// SoftDeleteComic memindahkan komik ke sampah dengan mengisi deleted_at.
+// Mengembalikan false jika komik tidak ditemukan atau sudah berada di sampah.
+func SoftDeleteComic(ctx context.Context, comicID int64) (bool, error) {
+	tag, err := DB.Exec(ctx, "UPDATE comics SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL", comicID)
+	if err != nil {
+		return false, fmt.Errorf("gagal memindahkan komik ke sampah: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RestoreComic mengembalikan komik dari sampah.
+// Mengembalikan false jika komik tidak ditemukan di sampah.
+func RestoreComic(ctx context.Context, comicID int64) (bool, error) {
+	tag, err := DB.Exec(ctx, "UPDATE comics SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", comicID)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulihkan komik: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// PurgeComic menghapus permanen komik yang sudah berada di sampah beserta seluruh chapter dan halamannya.
+// Mengembalikan URL gambar (halaman dan sampul) yang perlu dihapus dari storage oleh pemanggil.
+// Mengembalikan found=false jika komik tidak ada, atau ErrComicNotTrashed jika komik belum di sampah.
+func PurgeComic(ctx context.Context, comicID int64) (found bool, imageURLs []string, err error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, nil, fmt.Errorf("gagal memulai transaksi PurgeComic: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci baris komik agar tidak dipulihkan bersamaan dengan proses purge
+	var trashed bool
+	var coverURL *string
+	err = tx.QueryRow(ctx, "SELECT deleted_at IS NOT NULL, cover_image_url FROM comics WHERE id = $1 FOR UPDATE", comicID).Scan(&trashed, &coverURL)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil, nil
+		}
+		return false, nil, fmt.Errorf("gagal memeriksa komik: %w", err)
+	}
+	if !trashed {
+		return true, nil, ErrComicNotTrashed
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT p.image_url
+		FROM pages p
+		JOIN chapters ch ON ch.id = p.chapter_id
+		WHERE ch.comic_id = $1;
+	`, comicID)
+	if err != nil {
+		return true, nil, fmt.Errorf("gagal mengambil halaman komik: %w", err)
+	}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			rows.Close()
+			return true, nil, fmt.Errorf("gagal scan URL halaman: %w", err)
+		}
+		imageURLs = append(imageURLs, url)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return true, nil, fmt.Errorf("error iterasi halaman komik: %w", err)
+	}
+	if coverURL != nil && *coverURL != "" {
+		imageURLs = append(imageURLs, *coverURL)
+	}
+
+	// Hapus berurutan dari tabel anak agar tidak bergantung pada ON DELETE CASCADE di skema lama
+	if _, err := tx.Exec(ctx, "DELETE FROM pages WHERE chapter_id IN (SELECT id FROM chapters WHERE comic_id = $1)", comicID); err != nil {
+		return true, nil, fmt.Errorf("gagal menghapus halaman komik: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM chapters WHERE comic_id = $1", comicID); err != nil {
+		return true, nil, fmt.Errorf("gagal menghapus chapter komik: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM comics WHERE id = $1", comicID); err != nil {
+		return true, nil, fmt.Errorf("gagal menghapus komik: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return true, nil, fmt.Errorf("gagal commit transaksi PurgeComic: %w", err)
+	}
+	return true, imageURLs, nil
+}
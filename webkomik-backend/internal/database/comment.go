@@ -0,0 +1,467 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Opsi pengurutan komentar teratas pada sebuah chapter.
+const (
+	CommentSortNewest = "newest"
+	CommentSortTop    = "top" // Paling banyak disukai lebih dulu
+)
+
+// commentSortColumns memetakan opsi sort komentar ke kolom SQL yang aman dipakai di query.
+// Keduanya diurutkan menurun bersama id sebagai pemecah nilai yang sama.
+var commentSortColumns = map[string]string{
+	CommentSortNewest: "cm.created_at",
+	CommentSortTop:    "cm.like_count",
+}
+
+// ErrCommentPageNotFound dikembalikan jika nomor halaman yang dikomentari tidak ada di chapter.
+var ErrCommentPageNotFound = errors.New("halaman tidak ditemukan di chapter ini")
+
+// commentCursor adalah isi cursor keyset komentar sebelum di-encode ke base64.
+type commentCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// CommentListParams berisi parameter untuk ListChapterComments.
+type CommentListParams struct {
+	ChapterID  int64
+	PageNumber *int   // Hanya komentar pada halaman ini
+	ViewerID   string // Pengguna yang melihat, untuk liked_by_me; kosong untuk pengunjung anonim
+	Sort       string // Salah satu CommentSort*
+	Cursor     string
+	Limit      int
+}
+
+// commentColumns mengembalikan kolom komentar yang dipilih dengan alias cm untuk chapter_comments.
+// viewerParam adalah nomor parameter berisi ID pengguna yang melihat untuk kolom liked_by_me; string kosong
+// untuk pengunjung anonim.
+func commentColumns(viewerParam int) string {
+	return fmt.Sprintf(`
+		cm.id, cm.chapter_id, (SELECT p.page_number FROM pages p WHERE p.id = cm.page_id),
+		cm.root_id, cm.parent_id, cm.user_id::text, cm.body, cm.is_spoiler, cm.status, cm.moderation_note,
+		cm.like_count, cm.reply_count,
+		EXISTS (SELECT 1 FROM chapter_comment_likes l WHERE l.comment_id = cm.id AND l.user_id = NULLIF($%d, '')::uuid),
+		cm.edited_at, cm.created_at, cm.updated_at`, viewerParam)
+}
+
+// scanComment membaca satu baris hasil query yang memakai commentColumns.
+func scanComment(row pgx.Row) (*models.Comment, error) {
+	var cm models.Comment
+	err := row.Scan(
+		&cm.ID,
+		&cm.ChapterID,
+		&cm.PageNumber,
+		&cm.RootID,
+		&cm.ParentID,
+		&cm.UserID,
+		&cm.Body,
+		&cm.IsSpoiler,
+		&cm.Status,
+		&cm.ModerationNote,
+		&cm.LikeCount,
+		&cm.ReplyCount,
+		&cm.LikedByMe,
+		&cm.EditedAt,
+		&cm.CreatedAt,
+		&cm.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &cm, nil
+}
+
+// queryComments menjalankan query komentar dan membaca seluruh barisnya.
+func queryComments(ctx context.Context, query string, args ...interface{}) ([]models.Comment, error) {
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query komentar: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		cm, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris komentar: %w", err)
+		}
+		comments = append(comments, *cm)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris komentar: %w", err)
+	}
+	return comments, nil
+}
+
+// GetComment mengambil satu komentar berdasarkan ID dari sudut pandang viewerID.
+// Mengembalikan nil, nil jika komentar tidak ditemukan.
+func GetComment(ctx context.Context, id int64, viewerID string) (*models.Comment, error) {
+	query := fmt.Sprintf("SELECT %s FROM chapter_comments cm WHERE cm.id = $1;", commentColumns(2))
+	cm, err := scanComment(DB.QueryRow(ctx, query, id, viewerID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query GetComment: %w", err)
+	}
+	return cm, nil
+}
+
+// CreateComment menulis komentar baru pada chapter, atau balasan jika parent diisi.
+// Balasan selalu berada di chapter dan thread yang sama dengan komentar yang dibalas.
+// ErrCommentPageNotFound dikembalikan jika pageNumber tidak ada di chapter.
+func CreateComment(ctx context.Context, chapterID int64, userID, body string, isSpoiler bool, pageNumber *int, parent *models.Comment) (*models.Comment, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi CreateComment: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	var pageID *int64
+	if pageNumber != nil {
+		var id int64
+		err := tx.QueryRow(ctx, "SELECT id FROM pages WHERE chapter_id = $1 AND page_number = $2", chapterID, *pageNumber).Scan(&id)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, ErrCommentPageNotFound
+			}
+			return nil, fmt.Errorf("gagal mencari halaman komentar: %w", err)
+		}
+		pageID = &id
+	}
+
+	var rootID, parentID *int64
+	if parent != nil {
+		root := parent.ID
+		if parent.RootID != nil {
+			root = *parent.RootID
+		}
+		rootID, parentID = &root, &parent.ID
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO chapter_comments AS cm (chapter_id, page_id, root_id, parent_id, user_id, body, is_spoiler)
+		VALUES ($2, $3, $4, $5, $1::uuid, $6, $7)
+		RETURNING %s;
+	`, commentColumns(1))
+	created, err := scanComment(tx.QueryRow(ctx, query, userID, chapterID, pageID, rootID, parentID, body, isSpoiler))
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan komentar: %w", err)
+	}
+
+	if rootID != nil {
+		if _, err := tx.Exec(ctx, "UPDATE chapter_comments SET reply_count = reply_count + 1 WHERE id = $1", *rootID); err != nil {
+			return nil, fmt.Errorf("gagal memperbarui jumlah balasan: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit komentar: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateComment mengubah isi dan/atau penanda spoiler komentar oleh penulisnya.
+// Nilai nil berarti field tidak diubah. Mengembalikan nil, nil jika komentar tidak ditemukan, bukan milik userID,
+// atau sudah tidak tampil.
+func UpdateComment(ctx context.Context, id int64, userID string, body *string, isSpoiler *bool) (*models.Comment, error) {
+	query := fmt.Sprintf(`
+		UPDATE chapter_comments AS cm SET
+			body = COALESCE($3, cm.body),
+			is_spoiler = COALESCE($4, cm.is_spoiler),
+			edited_at = CASE WHEN $3::text IS NOT NULL AND $3 <> cm.body THEN NOW() ELSE cm.edited_at END,
+			updated_at = NOW()
+		WHERE cm.id = $2 AND cm.user_id = $1::uuid AND cm.status = 'published'
+		RETURNING %s;
+	`, commentColumns(1))
+	cm, err := scanComment(DB.QueryRow(ctx, query, userID, id, body, isSpoiler))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal memperbarui komentar: %w", err)
+	}
+	return cm, nil
+}
+
+// commentStatusTransitions memetakan status tujuan ke status asal yang diizinkan.
+// Komentar yang sudah dihapus tidak bisa dipulihkan.
+var commentStatusTransitions = map[string][]string{
+	models.CommentStatusHidden:    {models.CommentStatusPublished},
+	models.CommentStatusPublished: {models.CommentStatusHidden},
+	models.CommentStatusDeleted:   {models.CommentStatusPublished, models.CommentStatusHidden},
+}
+
+// SetCommentStatus menyembunyikan, memulihkan, atau menghapus komentar, lalu menyesuaikan jumlah balasan thread.
+// moderatorID dan note dicatat untuk aksi moderator; keduanya nil jika komentar dihapus oleh penulisnya.
+// Mengembalikan false jika komentar tidak ditemukan, atau ErrInvalidTransition jika status asal tidak sesuai.
+func SetCommentStatus(ctx context.Context, id int64, status string, moderatorID, note *string) (bool, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi SetCommentStatus: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	var (
+		current string
+		rootID  *int64
+	)
+	err = tx.QueryRow(ctx, "SELECT status, root_id FROM chapter_comments WHERE id = $1 FOR UPDATE", id).Scan(&current, &rootID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("gagal membaca status komentar: %w", err)
+	}
+	if !slices.Contains(commentStatusTransitions[status], current) {
+		return true, ErrInvalidTransition
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE chapter_comments SET
+			status = $2,
+			moderation_note = CASE WHEN $3::uuid IS NULL THEN moderation_note ELSE $4 END,
+			moderated_by = COALESCE($3::uuid, moderated_by),
+			moderated_at = CASE WHEN $3::uuid IS NULL THEN moderated_at ELSE NOW() END,
+			updated_at = NOW()
+		WHERE id = $1;
+	`, id, status, moderatorID, note)
+	if err != nil {
+		return false, fmt.Errorf("gagal mengubah status komentar: %w", err)
+	}
+
+	// reply_count hanya menghitung balasan yang tampil
+	wasVisible, isVisible := current == models.CommentStatusPublished, status == models.CommentStatusPublished
+	if rootID != nil && wasVisible != isVisible {
+		delta := 1
+		if wasVisible {
+			delta = -1
+		}
+		if _, err := tx.Exec(ctx, "UPDATE chapter_comments SET reply_count = reply_count + $2 WHERE id = $1", *rootID, delta); err != nil {
+			return false, fmt.Errorf("gagal memperbarui jumlah balasan: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit status komentar: %w", err)
+	}
+	return true, nil
+}
+
+// SetCommentSpoiler mengubah penanda spoiler komentar oleh moderator. Mengembalikan false jika komentar tidak ditemukan.
+func SetCommentSpoiler(ctx context.Context, id int64, isSpoiler bool) (bool, error) {
+	tag, err := DB.Exec(ctx, "UPDATE chapter_comments SET is_spoiler = $2, updated_at = NOW() WHERE id = $1", id, isSpoiler)
+	if err != nil {
+		return false, fmt.Errorf("gagal mengubah penanda spoiler komentar: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetCommentLike menyukai (like = true) atau batal menyukai komentar untuk pengguna, lalu mengembalikan
+// jumlah suka terbaru. Aksi berulang tidak mengubah jumlah. found bernilai false jika komentar tidak ditemukan.
+func SetCommentLike(ctx context.Context, id int64, userID string, like bool) (likeCount int, found bool, err error) {
+	change, delta := `
+		INSERT INTO chapter_comment_likes (comment_id, user_id)
+		SELECT id, $2::uuid FROM chapter_comments WHERE id = $1
+		ON CONFLICT (comment_id, user_id) DO NOTHING
+		RETURNING comment_id`, 1
+	if !like {
+		change, delta = `
+		DELETE FROM chapter_comment_likes WHERE comment_id = $1 AND user_id = $2::uuid
+		RETURNING comment_id`, -1
+	}
+
+	// SELECT akhir melihat snapshot sebelum perintah berjalan, sehingga selisihnya ditambahkan manual
+	query := fmt.Sprintf(`
+		WITH changed AS (%s
+		), counted AS (
+			UPDATE chapter_comments SET like_count = like_count + $3
+			WHERE id IN (SELECT comment_id FROM changed)
+		)
+		SELECT cm.like_count + $3 * (SELECT COUNT(*) FROM changed)::int
+		FROM chapter_comments cm WHERE cm.id = $1;
+	`, change)
+	err = DB.QueryRow(ctx, query, id, userID, delta).Scan(&likeCount)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("gagal menyimpan suka komentar: %w", err)
+	}
+	return likeCount, true, nil
+}
+
+// ListChapterComments mengambil komentar teratas pada sebuah chapter dengan keyset pagination.
+// Komentar yang disembunyikan atau dihapus tetap diambil jika masih memiliki balasan agar thread tidak terputus.
+// nextCursor bernilai nil jika tidak ada halaman berikutnya.
+func ListChapterComments(ctx context.Context, params CommentListParams) (comments []models.Comment, nextCursor *string, err error) {
+	sortColumn, ok := commentSortColumns[params.Sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("opsi sort komentar tidak dikenal: %s", params.Sort)
+	}
+
+	args := []interface{}{params.ViewerID, params.ChapterID}
+	conditions := []string{
+		"cm.chapter_id = $2",
+		"cm.root_id IS NULL",
+		"(cm.status = 'published' OR cm.reply_count > 0)",
+	}
+	if params.PageNumber != nil {
+		args = append(args, *params.PageNumber)
+		conditions = append(conditions, fmt.Sprintf("cm.page_id = (SELECT p.id FROM pages p WHERE p.chapter_id = cm.chapter_id AND p.page_number = $%d)", len(args)))
+	}
+	if params.Cursor != "" {
+		cur, err := decodeCommentCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, cur.value, cur.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, cm.id) < ($%d, $%d)", sortColumn, len(args)-1, len(args)))
+	}
+
+	// Ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+	args = append(args, params.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s FROM chapter_comments cm
+		WHERE %s
+		ORDER BY %s DESC, cm.id DESC
+		LIMIT $%d;
+	`, commentColumns(1), strings.Join(conditions, " AND "), sortColumn, len(args))
+
+	comments, err = queryComments(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(comments) > params.Limit {
+		comments = comments[:params.Limit]
+		next := encodeCommentCursor(params.Sort, comments[len(comments)-1])
+		nextCursor = &next
+	}
+	return comments, nextCursor, nil
+}
+
+// ListCommentReplies mengambil balasan yang tampil dalam satu thread, yang terlama lebih dulu,
+// dengan keyset pagination. nextCursor bernilai nil jika tidak ada halaman berikutnya.
+func ListCommentReplies(ctx context.Context, rootID int64, viewerID, cursor string, limit int) (replies []models.Comment, nextCursor *string, err error) {
+	args := []interface{}{viewerID, rootID}
+	conditions := []string{"cm.root_id = $2", "cm.status = 'published'"}
+	if cursor != "" {
+		cur, err := decodeCommentCursor(cursor, CommentSortNewest)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, cur.value, cur.ID)
+		conditions = append(conditions, fmt.Sprintf("(cm.created_at, cm.id) > ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s FROM chapter_comments cm
+		WHERE %s
+		ORDER BY cm.created_at ASC, cm.id ASC
+		LIMIT $%d;
+	`, commentColumns(1), strings.Join(conditions, " AND "), len(args))
+
+	replies, err = queryComments(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(replies) > limit {
+		replies = replies[:limit]
+		next := encodeCommentCursor(CommentSortNewest, replies[len(replies)-1])
+		nextCursor = &next
+	}
+	return replies, nextCursor, nil
+}
+
+// ListCommentsForModeration mengambil komentar untuk moderator, yang terbaru lebih dulu, beserta totalnya.
+// status kosong berarti semua status.
+func ListCommentsForModeration(ctx context.Context, status string, limit, offset int) ([]models.Comment, int64, error) {
+	filterClause := ""
+	args := []interface{}{}
+	if status != "" {
+		args = append(args, status)
+		filterClause = "WHERE cm.status = $1"
+	}
+
+	var total int64
+	if err := DB.QueryRow(ctx, "SELECT COUNT(*) FROM chapter_comments cm "+filterClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total komentar: %w", err)
+	}
+
+	// liked_by_me tidak relevan untuk moderator, jadi viewer diisi string kosong
+	args = append(args, "", limit, offset)
+	query := fmt.Sprintf(`
+		SELECT %s FROM chapter_comments cm
+		%s
+		ORDER BY cm.created_at DESC, cm.id DESC
+		LIMIT $%d OFFSET $%d;
+	`, commentColumns(len(args)-2), filterClause, len(args)-1, len(args))
+	comments, err := queryComments(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// decodedCommentCursor adalah cursor komentar dengan nilai yang sudah dikonversi ke tipe kolom sort.
+type decodedCommentCursor struct {
+	commentCursor
+	value interface{}
+}
+
+// encodeCommentCursor membuat cursor keyset dari posisi sebuah komentar pada urutan sort.
+func encodeCommentCursor(sort string, cm models.Comment) string {
+	cur := commentCursor{Sort: sort, ID: cm.ID}
+	switch sort {
+	case CommentSortNewest:
+		cur.Value = cm.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case CommentSortTop:
+		cur.Value = strconv.Itoa(cm.LikeCount)
+	}
+	raw, _ := json.Marshal(cur) // Struct sederhana, tidak mungkin gagal
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCommentCursor mengurai cursor komentar dan memastikan cocok dengan sort yang diminta.
+func decodeCommentCursor(s, sort string) (*decodedCommentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur decodedCommentCursor
+	if err := json.Unmarshal(raw, &cur.commentCursor); err != nil || cur.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	switch sort {
+	case CommentSortNewest:
+		t, err := time.Parse(time.RFC3339Nano, cur.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cur.value = t
+	case CommentSortTop:
+		n, err := strconv.Atoi(cur.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cur.value = n
+	}
+	return &cur, nil
+}
@@ -73,80 +73,34 @@ func CloseDB() {
 	}
 }
 
-// GetAllComics mengambil semua komik dari database beserta nama genrenya.
-func GetAllComics(ctx context.Context) ([]models.Comic, error) {
-	query := `
-		SELECT 
-			c.id, c.title, c.description, c.author_name, 
-			c.genre_id, g.name AS genre_name, 
-			c.cover_image_url, c.created_at, c.updated_at
-		FROM comics c
-		LEFT JOIN genres g ON c.genre_id = g.id
-		ORDER BY c.created_at DESC; 
-	` // Urutkan berdasarkan yang terbaru dibuat
-
-	rows, err := DB.Query(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("gagal menjalankan query GetAllComics: %w", err)
-	}
-	defer rows.Close()
-
-	var comics []models.Comic
-	for rows.Next() {
-		var comic models.Comic
-		// Perhatikan bahwa kita perlu scan ke field yang sesuai di struct Comic.
-		// Untuk field yang bisa NULL (pointer di struct), pgx akan menangani scan ke pointer tersebut.
-		// Jika kolom di DB adalah NULL, field pointer di struct akan menjadi nil.
-		err := rows.Scan(
-			&comic.ID,
-			&comic.Title,
-			&comic.Description,   // Pointer
-			&comic.AuthorName,    // Pointer
-			&comic.GenreID,       // Pointer
-			&comic.GenreName,     // Pointer (dari tabel genres)
-			&comic.CoverImageURL, // Pointer
-			&comic.CreatedAt,
-			&comic.UpdatedAt,
-		)
-		if err != nil {
-			// Jika ada error saat scan satu baris, log dan lanjutkan ke baris berikutnya
-			// atau hentikan dan kembalikan error, tergantung preferensi.
-			// Untuk daftar, mungkin lebih baik log dan skip baris yang error.
-			log.Printf("Error scanning comic row: %v\n", err)
-			// return nil, fmt.Errorf("gagal scan baris komik: %w", err) // Atau hentikan jika kritikal
-			continue // Lanjutkan ke baris berikutnya
-		}
-		comics = append(comics, comic)
-	}
-
-	if err = rows.Err(); err != nil {
-		// Error yang terjadi selama iterasi rows (misalnya masalah koneksi)
-		return nil, fmt.Errorf("error iterasi baris komik: %w", err)
-	}
-
-	return comics, nil
-}
-
 // GetComicByID mengambil detail satu komik berdasarkan ID.
+// Komik yang berada di sampah (soft-deleted) dianggap tidak ditemukan.
 func GetComicByID(ctx context.Context, id int64) (*models.Comic, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT 
 			c.id, c.title, c.description, c.author_name, 
-			c.genre_id, g.name AS genre_name, 
-			c.cover_image_url, c.created_at, c.updated_at
+			%s AS genres, %s AS tags,
+			c.cover_image_url, c.uploaded_by_admin_id, c.view_count, c.rating_average, c.rating_count,
+			c.status, c.review_note, c.published_at, c.created_at, c.updated_at
 		FROM comics c
-		LEFT JOIN genres g ON c.genre_id = g.id
-		WHERE c.id = $1;
-	`
+		WHERE c.id = $1 AND c.deleted_at IS NULL;
+	`, comicGenresColumn("c"), comicTagsColumn("c"))
 	var comic models.Comic
 	err := DB.QueryRow(ctx, query, id).Scan(
 		&comic.ID,
 		&comic.Title,
 		&comic.Description,
 		&comic.AuthorName,
-		&comic.GenreID,
-		&comic.GenreName,
+		&comic.Genres,
+		&comic.Tags,
 		&comic.CoverImageURL,
+		&comic.UploadedByAdminID,
+		&comic.ViewCount,
+		&comic.RatingAverage,
+		&comic.RatingCount,
+		&comic.Status,
+		&comic.ReviewNote,
+		&comic.PublishedAt,
 		&comic.CreatedAt,
 		&comic.UpdatedAt,
 	)
@@ -159,15 +113,20 @@ func GetComicByID(ctx context.Context, id int64) (*models.Comic, error) {
 	return &comic, nil
 }
 
-// GetChaptersByComicID mengambil semua chapter untuk comicID tertentu.
-func GetChaptersByComicID(ctx context.Context, comicID int64) ([]models.Chapter, error) {
-	query := `
-		SELECT id, comic_id, chapter_number, title, created_at, updated_at
-		FROM chapters
-		WHERE comic_id = $1
-		ORDER BY chapter_number ASC;
-	`
-	rows, err := DB.Query(ctx, query, comicID)
+// GetChaptersByComicID mengambil ringkasan semua chapter untuk comicID tertentu beserta jumlah halamannya.
+// Halaman tidak ikut diambil; gunakan GetPagesByChapterID untuk membaca satu chapter.
+// Jika publishedOnly bernilai true, hanya chapter yang sudah rilis untuk publik yang diambil;
+// chapter terjadwal yang publish_at-nya belum tiba disembunyikan.
+func GetChaptersByComicID(ctx context.Context, comicID int64, publishedOnly bool) ([]models.Chapter, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, COUNT(p.id) AS page_count
+		FROM chapters ch
+		LEFT JOIN pages p ON p.chapter_id = ch.id
+		WHERE ch.comic_id = $1 AND (NOT $2 OR %s)
+		GROUP BY ch.id
+		ORDER BY ch.chapter_number ASC;
+	`, chapterColumns, releasedChapterCondition)
+	rows, err := DB.Query(ctx, query, comicID, publishedOnly)
 	if err != nil {
 		return nil, fmt.Errorf("gagal query GetChaptersByComicID: %w", err)
 	}
@@ -181,8 +140,13 @@ func GetChaptersByComicID(ctx context.Context, comicID int64) ([]models.Chapter,
 			&ch.ComicID,
 			&ch.ChapterNumber,
 			&ch.Title,
+			&ch.Status,
+			&ch.ReviewNote,
+			&ch.PublishAt,
+			&ch.PublishedAt,
 			&ch.CreatedAt,
 			&ch.UpdatedAt,
+			&ch.PageCount,
 		)
 		if err != nil {
 			log.Printf("Error scanning chapter row: %v\n", err)
@@ -199,7 +163,7 @@ func GetChaptersByComicID(ctx context.Context, comicID int64) ([]models.Chapter,
 // GetPagesByChapterID mengambil semua halaman untuk chapterID tertentu.
 func GetPagesByChapterID(ctx context.Context, chapterID int64) ([]models.Page, error) {
 	query := `
-		SELECT id, chapter_id, image_url, page_number, created_at
+		SELECT id, chapter_id, image_url, page_number, width, height, created_at
 		FROM pages
 		WHERE chapter_id = $1
 		ORDER BY page_number ASC;
@@ -218,6 +182,8 @@ func GetPagesByChapterID(ctx context.Context, chapterID int64) ([]models.Page, e
 			&p.ChapterID,
 			&p.ImageURL,
 			&p.PageNumber,
+			&p.Width,
+			&p.Height,
 			&p.CreatedAt,
 		)
 		if err != nil {
@@ -232,24 +198,30 @@ func GetPagesByChapterID(ctx context.Context, chapterID int64) ([]models.Page, e
 	return pages, nil
 }
 
-// CreateComic menyimpan komik baru ke database.
-// Ia mengembalikan komik yang baru dibuat atau error.
+// CreateComic menyimpan komik baru ke database beserta genre (genreIDs) dan tag (input.Tags)-nya.
+// Pembuat komik otomatis menjadi owner di tim komik.
+// Ia mengembalikan komik yang baru dibuat atau error. ErrUnknownGenre dikembalikan jika ada genre yang tidak ada.
 // adminID adalah ID pengguna (dari Supabase auth.users.id) yang membuat komik ini.
-func CreateComic(ctx context.Context, input models.Comic, adminID string) (*models.Comic, error) {
+func CreateComic(ctx context.Context, input models.Comic, genreIDs []int64, adminID string) (*models.Comic, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi CreateComic: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
 	query := `
-		INSERT INTO comics (title, description, author_name, genre_id, cover_image_url, uploaded_by_admin_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, title, description, author_name, genre_id, cover_image_url, uploaded_by_admin_id, created_at, updated_at;
+		INSERT INTO comics (title, description, author_name, cover_image_url, uploaded_by_admin_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, title, description, author_name, cover_image_url, uploaded_by_admin_id, view_count,
+			rating_average, rating_count, status, review_note, published_at, created_at, updated_at;
 	`
 	// Variabel untuk menampung hasil RETURNING, termasuk yang mungkin NULL
 	var createdComic models.Comic
-	//var genreNamePlaceholder *string // Placeholder, karena RETURNING tidak langsung join dengan genre name
 
-	err := DB.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		input.Title,
 		input.Description,
 		input.AuthorName,
-		input.GenreID,
 		input.CoverImageURL,
 		adminID, // adminID yang bertipe UUID dari Supabase
 	).Scan(
@@ -257,9 +229,14 @@ func CreateComic(ctx context.Context, input models.Comic, adminID string) (*mode
 		&createdComic.Title,
 		&createdComic.Description,
 		&createdComic.AuthorName,
-		&createdComic.GenreID,
 		&createdComic.CoverImageURL,
 		&createdComic.UploadedByAdminID, // Akan berisi adminID
+		&createdComic.ViewCount,
+		&createdComic.RatingAverage,
+		&createdComic.RatingCount,
+		&createdComic.Status,
+		&createdComic.ReviewNote,
+		&createdComic.PublishedAt,
 		&createdComic.CreatedAt,
 		&createdComic.UpdatedAt,
 	)
@@ -268,19 +245,22 @@ func CreateComic(ctx context.Context, input models.Comic, adminID string) (*mode
 		return nil, fmt.Errorf("gagal membuat komik di database: %w", err)
 	}
 
-	// Jika genre_id ada, kita mungkin ingin mengambil nama genre untuk dikembalikan
-	// Ini opsional, atau bisa dilakukan di handler jika perlu.
-	if createdComic.GenreID != nil {
-		var genreName string
-		errGenre := DB.QueryRow(ctx, "SELECT name FROM genres WHERE id = $1", *createdComic.GenreID).Scan(&genreName)
-		if errGenre == nil {
-			createdComic.GenreName = &genreName
-		} else if errGenre != pgx.ErrNoRows {
-			// Log error tapi jangan gagalkan pembuatan komik utama
-			log.Printf("Peringatan: Gagal mengambil nama genre untuk komik baru ID %d: %v", createdComic.ID, errGenre)
-		}
+	if err := addComicMember(ctx, tx, createdComic.ID, adminID, models.ComicRoleOwner, nil); err != nil {
+		return nil, err
+	}
+	if err := setComicGenres(ctx, tx, createdComic.ID, genreIDs); err != nil {
+		return nil, err
+	}
+	if err := setComicTags(ctx, tx, createdComic.ID, input.Tags); err != nil {
+		return nil, err
+	}
+	if err := loadComicTaxonomy(ctx, tx, &createdComic); err != nil {
+		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi CreateComic: %w", err)
+	}
 	return &createdComic, nil
 }
 
@@ -329,15 +309,6 @@ func UpdateComic(ctx context.Context, comicID int64, updates map[string]interfac
 		paramCounter++
 	}
 
-	if genreID, ok := updates["genre_id"].(*int64); ok {
-		if setClauses != "" {
-			setClauses += ", "
-		}
-		setClauses += fmt.Sprintf("genre_id = $%d", paramCounter)
-		values = append(values, genreID)
-		paramCounter++
-	}
-
 	if coverImageURL, ok := updates["cover_image_url"].(*string); ok {
 		if setClauses != "" {
 			setClauses += ", "
@@ -358,23 +329,35 @@ func UpdateComic(ctx context.Context, comicID int64, updates map[string]interfac
 	// Tambahkan ID komik sebagai parameter terakhir untuk klausa WHERE
 	values = append(values, comicID)
 
-	// 3. Jalankan query update
+	// 3. Jalankan query update bersama perubahan genre/tag dalam satu transaksi
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi UpdateComic: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
 	query := fmt.Sprintf(`
 		UPDATE comics
 		SET %s
 		WHERE id = $%d
-		RETURNING id, title, description, author_name, genre_id, cover_image_url, uploaded_by_admin_id, created_at, updated_at;
+		RETURNING id, title, description, author_name, cover_image_url, uploaded_by_admin_id, view_count,
+			rating_average, rating_count, status, review_note, published_at, created_at, updated_at;
 	`, setClauses, paramCounter)
 
 	var updatedComic models.Comic
-	err = DB.QueryRow(ctx, query, values...).Scan(
+	err = tx.QueryRow(ctx, query, values...).Scan(
 		&updatedComic.ID,
 		&updatedComic.Title,
 		&updatedComic.Description,
 		&updatedComic.AuthorName,
-		&updatedComic.GenreID,
 		&updatedComic.CoverImageURL,
 		&updatedComic.UploadedByAdminID,
+		&updatedComic.ViewCount,
+		&updatedComic.RatingAverage,
+		&updatedComic.RatingCount,
+		&updatedComic.Status,
+		&updatedComic.ReviewNote,
+		&updatedComic.PublishedAt,
 		&updatedComic.CreatedAt,
 		&updatedComic.UpdatedAt,
 	)
@@ -383,17 +366,23 @@ func UpdateComic(ctx context.Context, comicID int64, updates map[string]interfac
 		return nil, fmt.Errorf("gagal memperbarui komik di database: %w", err)
 	}
 
-	// 4. Ambil nama genre jika ada genre_id
-	if updatedComic.GenreID != nil {
-		var genreName string
-		errGenre := DB.QueryRow(ctx, "SELECT name FROM genres WHERE id = $1", *updatedComic.GenreID).Scan(&genreName)
-		if errGenre == nil {
-			updatedComic.GenreName = &genreName
-		} else if errGenre != pgx.ErrNoRows {
-			// Log error tapi jangan gagalkan pembaruan komik utama
-			log.Printf("Peringatan: Gagal mengambil nama genre untuk komik ID %d: %v", updatedComic.ID, errGenre)
+	// 4. Ganti genre dan tag jika key-nya ada (slice kosong berarti dikosongkan)
+	if genreIDs, ok := updates["genre_ids"].([]int64); ok {
+		if err := setComicGenres(ctx, tx, updatedComic.ID, genreIDs); err != nil {
+			return nil, err
+		}
+	}
+	if tags, ok := updates["tags"].([]string); ok {
+		if err := setComicTags(ctx, tx, updatedComic.ID, tags); err != nil {
+			return nil, err
 		}
 	}
+	if err := loadComicTaxonomy(ctx, tx, &updatedComic); err != nil {
+		return nil, err
+	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi UpdateComic: %w", err)
+	}
 	return &updatedComic, nil
 }
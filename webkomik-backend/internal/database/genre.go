@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Error sentinel untuk operasi genre.
+var (
+	ErrDuplicateGenreSlug = errors.New("slug genre sudah dipakai")
+	ErrGenreInUse         = errors.New("genre masih dipakai oleh komik")
+)
+
+// genreColumns adalah kolom genre yang dipilih beserta jumlah komik aktif yang sudah dipublikasikan.
+const genreColumns = `
+	g.id, g.name, g.slug, g.description, g.display_order,
+	(SELECT COUNT(*) FROM comic_genres cg JOIN comics c ON c.id = cg.comic_id
+		WHERE cg.genre_id = g.id AND c.deleted_at IS NULL AND c.status = 'published') AS comic_count,
+	g.created_at, g.updated_at`
+
+// scanGenre membaca satu baris hasil query yang memakai genreColumns.
+func scanGenre(row pgx.Row) (*models.Genre, error) {
+	var genre models.Genre
+	err := row.Scan(
+		&genre.ID,
+		&genre.Name,
+		&genre.Slug,
+		&genre.Description,
+		&genre.DisplayOrder,
+		&genre.ComicCount,
+		&genre.CreatedAt,
+		&genre.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+// ListGenres mengambil seluruh genre diurutkan berdasarkan display_order lalu nama.
+func ListGenres(ctx context.Context) ([]models.Genre, error) {
+	query := fmt.Sprintf("SELECT %s FROM genres g ORDER BY g.display_order ASC, g.name ASC;", genreColumns)
+	rows, err := DB.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ListGenres: %w", err)
+	}
+	defer rows.Close()
+
+	genres := []models.Genre{}
+	for rows.Next() {
+		genre, err := scanGenre(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris genre: %w", err)
+		}
+		genres = append(genres, *genre)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris genre: %w", err)
+	}
+	return genres, nil
+}
+
+// GetGenreByID mengambil satu genre berdasarkan ID. Mengembalikan nil jika tidak ditemukan.
+func GetGenreByID(ctx context.Context, id int64) (*models.Genre, error) {
+	query := fmt.Sprintf("SELECT %s FROM genres g WHERE g.id = $1;", genreColumns)
+	genre, err := scanGenre(DB.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query GetGenreByID: %w", err)
+	}
+	return genre, nil
+}
+
+// CreateGenre menyimpan genre baru. Mengembalikan ErrDuplicateGenreSlug jika slug sudah dipakai.
+func CreateGenre(ctx context.Context, input models.Genre) (*models.Genre, error) {
+	var id int64
+	err := DB.QueryRow(ctx, `
+		INSERT INTO genres (name, slug, description, display_order)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;
+	`, input.Name, input.Slug, input.Description, input.DisplayOrder).Scan(&id)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return nil, ErrDuplicateGenreSlug
+		}
+		return nil, fmt.Errorf("gagal membuat genre di database: %w", err)
+	}
+	return GetGenreByID(ctx, id)
+}
+
+// UpdateGenre memperbarui sebagian field genre. Key yang didukung: name (string), slug (string),
+// description (*string), dan display_order (int). Mengembalikan nil jika genre tidak ditemukan.
+func UpdateGenre(ctx context.Context, id int64, updates map[string]interface{}) (*models.Genre, error) {
+	setClauses := ""
+	values := []interface{}{}
+	paramCounter := 1
+	addClause := func(column string, value interface{}) {
+		if setClauses != "" {
+			setClauses += ", "
+		}
+		setClauses += fmt.Sprintf("%s = $%d", column, paramCounter)
+		values = append(values, value)
+		paramCounter++
+	}
+
+	if name, ok := updates["name"].(string); ok {
+		addClause("name", name)
+	}
+	if slug, ok := updates["slug"].(string); ok {
+		addClause("slug", slug)
+	}
+	if description, ok := updates["description"].(*string); ok {
+		addClause("description", description)
+	}
+	if displayOrder, ok := updates["display_order"].(int); ok {
+		addClause("display_order", displayOrder)
+	}
+	addClause("updated_at", time.Now())
+	values = append(values, id)
+
+	query := fmt.Sprintf("UPDATE genres SET %s WHERE id = $%d;", setClauses, paramCounter)
+	tag, err := DB.Exec(ctx, query, values...)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return nil, ErrDuplicateGenreSlug
+		}
+		return nil, fmt.Errorf("gagal memperbarui genre di database: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return GetGenreByID(ctx, id)
+}
+
+// DeleteGenre menghapus genre. Mengembalikan false jika genre tidak ditemukan,
+// atau ErrGenreInUse jika masih ada komik (termasuk yang di sampah) yang memakainya.
+func DeleteGenre(ctx context.Context, id int64) (bool, error) {
+	// Relasi comic_genres memakai ON DELETE RESTRICT, sehingga database yang menolak penghapusan
+	tag, err := DB.Exec(ctx, "DELETE FROM genres WHERE id = $1", id)
+	if err != nil {
+		if isPgError(err, pgForeignKeyViolation) {
+			return true, ErrGenreInUse
+		}
+		return false, fmt.Errorf("gagal menghapus genre: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
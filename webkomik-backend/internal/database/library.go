@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Opsi pengurutan pustaka pembaca.
+const (
+	LibrarySortUpdated = "updated" // Chapter terbaru yang rilis paling akhir lebih dulu
+	LibrarySortAdded   = "added"   // Komik yang paling baru ditambahkan ke pustaka lebih dulu
+	LibrarySortTitle   = "title"
+	LibrarySortUnread  = "unread" // Paling banyak chapter belum dibaca lebih dulu
+)
+
+// librarySortOrders memetakan opsi sort pustaka ke klausa ORDER BY yang aman dipakai di query.
+var librarySortOrders = map[string]string{
+	LibrarySortUpdated: "last_updated_at DESC, c.id DESC",
+	LibrarySortAdded:   "le.created_at DESC, c.id DESC",
+	LibrarySortTitle:   "c.title ASC, c.id ASC",
+	LibrarySortUnread:  "stats.unread_count DESC, last_updated_at DESC, c.id DESC",
+}
+
+// libraryKindColumns memetakan jenis entri pustaka ke kolom flag di library_entries.
+var libraryKindColumns = map[string]string{
+	models.LibraryKindFollow:   "following",
+	models.LibraryKindBookmark: "bookmarked",
+}
+
+// otherColumn mengembalikan kolom flag pustaka selain column.
+func otherColumn(column string) string {
+	if column == "following" {
+		return "bookmarked"
+	}
+	return "following"
+}
+
+// libraryStateColumns adalah kolom status pustaka yang dipilih, dengan alias le untuk library_entries.
+const libraryStateColumns = `le.following, le.bookmarked, le.last_read_chapter_number, le.last_read_at, le.created_at`
+
+// LibraryListParams berisi parameter filter, sort, dan paginasi untuk ListLibrary.
+type LibraryListParams struct {
+	UserID     string
+	Kind       string // Salah satu models.LibraryKind*, kosong berarti semua entri
+	UnreadOnly bool   // Hanya komik yang memiliki chapter belum dibaca
+	Sort       string // Salah satu LibrarySort*
+	Limit      int
+	Offset     int
+}
+
+// scanLibraryState membaca satu baris hasil query yang memakai libraryStateColumns.
+func scanLibraryState(row pgx.Row) (*models.LibraryState, error) {
+	var state models.LibraryState
+	err := row.Scan(
+		&state.Following,
+		&state.Bookmarked,
+		&state.LastReadChapterNumber,
+		&state.LastReadAt,
+		&state.AddedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetLibraryState mengambil status komik di pustaka pengguna.
+// Mengembalikan nil, nil jika komik tidak ada di pustaka.
+func GetLibraryState(ctx context.Context, userID string, comicID int64) (*models.LibraryState, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM library_entries le
+		WHERE le.user_id = $1::uuid AND le.comic_id = $2;
+	`, libraryStateColumns)
+	state, err := scanLibraryState(DB.QueryRow(ctx, query, userID, comicID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query GetLibraryState: %w", err)
+	}
+	return state, nil
+}
+
+// SetLibraryFlag mengikuti/menandai (on = true) atau berhenti mengikuti/menghapus tanda (on = false)
+// sebuah komik untuk pengguna. Entri dihapus jika komik tidak lagi diikuti maupun ditandai.
+// Mengembalikan status terbaru, atau nil jika komik sudah tidak ada di pustaka.
+func SetLibraryFlag(ctx context.Context, userID string, comicID int64, kind string, on bool) (*models.LibraryState, error) {
+	column, ok := libraryKindColumns[kind]
+	if !ok {
+		return nil, fmt.Errorf("jenis entri pustaka tidak dikenal: %s", kind)
+	}
+
+	var query string
+	if on {
+		query = fmt.Sprintf(`
+			INSERT INTO library_entries AS le (user_id, comic_id, %[1]s)
+			VALUES ($1, $2, TRUE)
+			ON CONFLICT (user_id, comic_id) DO UPDATE SET %[1]s = TRUE, updated_at = NOW()
+			RETURNING %[2]s;
+		`, column, libraryStateColumns)
+	} else {
+		// Baris tanpa flag lain dihapus, sisanya cukup dimatikan flag-nya.
+		// Kedua perintah melihat snapshot yang sama dan menyentuh baris yang berbeda.
+		query = fmt.Sprintf(`
+			WITH removed AS (
+				DELETE FROM library_entries
+				WHERE user_id = $1::uuid AND comic_id = $2 AND NOT %[2]s
+			)
+			UPDATE library_entries AS le SET %[1]s = FALSE, updated_at = NOW()
+			WHERE le.user_id = $1::uuid AND le.comic_id = $2 AND le.%[2]s
+			RETURNING %[3]s;
+		`, column, otherColumn(column), libraryStateColumns)
+	}
+
+	state, err := scanLibraryState(DB.QueryRow(ctx, query, userID, comicID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengubah pustaka pengguna: %w", err)
+	}
+	return state, nil
+}
+
+// MarkLibraryRead menandai chapter sampai chapterNumber sudah dibaca untuk komik di pustaka pengguna.
+// Jika chapterNumber nil, semua chapter yang sudah rilis dianggap dibaca.
+// Mengembalikan nil, nil jika komik tidak ada di pustaka.
+func MarkLibraryRead(ctx context.Context, userID string, comicID int64, chapterNumber *float32) (*models.LibraryState, error) {
+	query := fmt.Sprintf(`
+		UPDATE library_entries AS le SET
+			last_read_chapter_number = COALESCE($3::real, (
+				SELECT MAX(ch.chapter_number) FROM chapters ch
+				WHERE ch.comic_id = le.comic_id AND %s
+			), le.last_read_chapter_number),
+			last_read_at = NOW(),
+			updated_at = NOW()
+		WHERE le.user_id = $1::uuid AND le.comic_id = $2
+		RETURNING %s;
+	`, releasedChapterCondition, libraryStateColumns)
+
+	state, err := scanLibraryState(DB.QueryRow(ctx, query, userID, comicID, chapterNumber))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal menandai chapter dibaca: %w", err)
+	}
+	return state, nil
+}
+
+// ListLibrary mengambil komik di pustaka pengguna beserta jumlah chapter yang belum dibaca dan chapter terbarunya.
+// Hanya komik berstatus published yang ditampilkan; chapter yang dihitung hanya yang sudah rilis untuk publik.
+func ListLibrary(ctx context.Context, params LibraryListParams) ([]models.LibraryEntry, int64, error) {
+	orderBy, ok := librarySortOrders[params.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("opsi sort pustaka tidak dikenal: %s", params.Sort)
+	}
+
+	filter := ""
+	if params.Kind != "" {
+		column, ok := libraryKindColumns[params.Kind]
+		if !ok {
+			return nil, 0, fmt.Errorf("jenis entri pustaka tidak dikenal: %s", params.Kind)
+		}
+		filter += " AND le." + column
+	}
+	if params.UnreadOnly {
+		filter += " AND stats.unread_count > 0"
+	}
+
+	// Chapter yang belum dibaca adalah chapter rilis dengan nomor di atas chapter terakhir yang ditandai dibaca.
+	fromClause := fmt.Sprintf(`
+		FROM library_entries le
+		JOIN comics c ON c.id = le.comic_id
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (
+					WHERE le.last_read_chapter_number IS NULL OR ch.chapter_number > le.last_read_chapter_number
+				)::int AS unread_count,
+				MAX(COALESCE(ch.published_at, ch.publish_at)) AS last_chapter_at
+			FROM chapters ch
+			WHERE ch.comic_id = c.id AND %s
+		) stats
+		WHERE le.user_id = $1::uuid AND c.deleted_at IS NULL AND c.status = 'published'%s
+	`, releasedChapterCondition, filter)
+
+	var total int64
+	if err := DB.QueryRow(ctx, "SELECT COUNT(*) "+fromClause, params.UserID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total pustaka: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			c.id, c.title, c.description, c.author_name,
+			%s AS genres, %s AS tags,
+			c.cover_image_url, c.view_count, c.rating_average, c.rating_count,
+			c.status, c.published_at, c.created_at, c.updated_at,
+			%s,
+			stats.unread_count,
+			COALESCE(stats.last_chapter_at, c.published_at, c.created_at) AS last_updated_at,
+			latest.id, latest.chapter_number, latest.title
+		%s
+		LEFT JOIN LATERAL (
+			SELECT ch.id, ch.chapter_number, ch.title FROM chapters ch
+			WHERE ch.comic_id = c.id AND %s
+			ORDER BY ch.chapter_number DESC
+			LIMIT 1
+		) latest ON true
+		ORDER BY %s
+		LIMIT $2 OFFSET $3;
+	`, comicGenresColumn("c"), comicTagsColumn("c"), libraryStateColumns, fromClause, releasedChapterCondition, orderBy)
+
+	rows, err := DB.Query(ctx, query, params.UserID, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal menjalankan query ListLibrary: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.LibraryEntry{}
+	for rows.Next() {
+		var (
+			e            models.LibraryEntry
+			latestID     *int64
+			latestNumber *float32
+			latestTitle  *string
+		)
+		err := rows.Scan(
+			&e.Comic.ID,
+			&e.Comic.Title,
+			&e.Comic.Description,
+			&e.Comic.AuthorName,
+			&e.Comic.Genres,
+			&e.Comic.Tags,
+			&e.Comic.CoverImageURL,
+			&e.Comic.ViewCount,
+			&e.Comic.RatingAverage,
+			&e.Comic.RatingCount,
+			&e.Comic.Status,
+			&e.Comic.PublishedAt,
+			&e.Comic.CreatedAt,
+			&e.Comic.UpdatedAt,
+			&e.Following,
+			&e.Bookmarked,
+			&e.LastReadChapterNumber,
+			&e.LastReadAt,
+			&e.AddedAt,
+			&e.UnreadCount,
+			&e.LastUpdatedAt,
+			&latestID,
+			&latestNumber,
+			&latestTitle,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal scan baris pustaka: %w", err)
+		}
+		if latestID != nil {
+			e.LatestChapter = &models.ChapterRef{ID: *latestID, ChapterNumber: *latestNumber, Title: latestTitle}
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterasi baris pustaka: %w", err)
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,311 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPushEndpointTaken dikembalikan jika endpoint Web Push sudah terdaftar atas nama pengguna lain.
+var ErrPushEndpointTaken = errors.New("endpoint push sudah terdaftar untuk pengguna lain")
+
+// notificationColumns adalah kolom notifikasi yang dipilih, dengan alias n untuk notifications.
+const notificationColumns = `n.id, n.user_id::text, n.type, n.comic_id, n.chapter_id, n.title, n.body, n.link, n.read_at, n.created_at`
+
+// preferenceColumns adalah kolom preferensi notifikasi dengan alias np; pengguna tanpa baris preferensi
+// mendapat nilai default yang sama dengan default kolom di tabel.
+const preferenceColumns = `COALESCE(np.email_enabled, FALSE), COALESCE(np.push_enabled, TRUE)`
+
+// scanNotification membaca satu baris hasil query yang memakai notificationColumns.
+func scanNotification(row pgx.Row, extra ...interface{}) (*models.Notification, error) {
+	var n models.Notification
+	dest := []interface{}{
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.ComicID,
+		&n.ChapterID,
+		&n.Title,
+		&n.Body,
+		&n.Link,
+		&n.ReadAt,
+		&n.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// ListComicFollowerIDs mengambil ID pengguna yang mengikuti sebuah komik, diurutkan berdasarkan ID,
+// mulai setelah afterUserID (nil untuk batch pertama). Dipakai untuk fan-out notifikasi per batch.
+func ListComicFollowerIDs(ctx context.Context, comicID int64, afterUserID *string, limit int) ([]string, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT le.user_id::text FROM library_entries le
+		WHERE le.comic_id = $1 AND le.following AND ($2::uuid IS NULL OR le.user_id > $2::uuid)
+		ORDER BY le.user_id
+		LIMIT $3;
+	`, comicID, afterUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ListComicFollowerIDs: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := []string{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("gagal scan baris pengikut komik: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris pengikut komik: %w", err)
+	}
+	return userIDs, nil
+}
+
+// CreateNotifications membuat notifikasi dengan isi yang sama dari template untuk setiap pengguna di userIDs,
+// lalu mengembalikan notifikasi yang tersimpan beserta email dan preferensi kanal penerimanya.
+// Notifikasi yang sudah pernah dibuat (misalnya chapter baru yang sama) dilewati.
+func CreateNotifications(ctx context.Context, userIDs []string, template models.Notification) ([]models.NotificationRecipient, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf(`
+		WITH n AS (
+			INSERT INTO notifications (user_id, type, comic_id, chapter_id, title, body, link)
+			SELECT u, $2, $3, $4, $5, $6, $7 FROM unnest($1::uuid[]) AS u
+			ON CONFLICT DO NOTHING
+			RETURNING *
+		)
+		SELECT %s, ua.email, %s
+		FROM n
+		LEFT JOIN user_accounts ua ON ua.id = n.user_id
+		LEFT JOIN notification_preferences np ON np.user_id = n.user_id;
+	`, notificationColumns, preferenceColumns)
+
+	rows, err := DB.Query(ctx, query, userIDs, template.Type, template.ComicID, template.ChapterID, template.Title, template.Body, template.Link)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan notifikasi: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []models.NotificationRecipient{}
+	for rows.Next() {
+		var r models.NotificationRecipient
+		n, err := scanNotification(rows, &r.Email, &r.EmailEnabled, &r.PushEnabled)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris notifikasi baru: %w", err)
+		}
+		r.Notification = *n
+		recipients = append(recipients, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris notifikasi baru: %w", err)
+	}
+	return recipients, nil
+}
+
+// ListNotifications mengambil notifikasi pengguna, yang terbaru lebih dulu, beserta total untuk paginasi.
+func ListNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	whereClause := "WHERE n.user_id = $1::uuid"
+	if unreadOnly {
+		whereClause += " AND n.read_at IS NULL"
+	}
+
+	var total int64
+	if err := DB.QueryRow(ctx, "SELECT COUNT(*) FROM notifications n "+whereClause, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total notifikasi: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM notifications n
+		%s
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $2 OFFSET $3;
+	`, notificationColumns, whereClause)
+	rows, err := DB.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal query ListNotifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal scan baris notifikasi: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterasi baris notifikasi: %w", err)
+	}
+	return notifications, total, nil
+}
+
+// ListNotificationsAfter mengambil notifikasi pengguna dengan ID lebih besar dari afterID, yang terlama lebih dulu.
+// Dipakai stream notifikasi untuk mengirim ulang notifikasi yang terlewat saat klien tersambung ulang.
+func ListNotificationsAfter(ctx context.Context, userID string, afterID int64, limit int) ([]models.Notification, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM notifications n
+		WHERE n.user_id = $1::uuid AND n.id > $2
+		ORDER BY n.id
+		LIMIT $3;
+	`, notificationColumns)
+	rows, err := DB.Query(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ListNotificationsAfter: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris notifikasi: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris notifikasi: %w", err)
+	}
+	return notifications, nil
+}
+
+// CountUnreadNotifications menghitung notifikasi pengguna yang belum dibaca.
+func CountUnreadNotifications(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := DB.QueryRow(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = $1::uuid AND read_at IS NULL", userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menghitung notifikasi belum dibaca: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationsRead menandai notifikasi pengguna sudah dibaca. Jika ids kosong, semua notifikasi
+// yang belum dibaca ditandai. Mengembalikan jumlah notifikasi yang berubah.
+func MarkNotificationsRead(ctx context.Context, userID string, ids []int64) (int64, error) {
+	query := "UPDATE notifications SET read_at = NOW() WHERE user_id = $1::uuid AND read_at IS NULL"
+	args := []interface{}{userID}
+	if len(ids) > 0 {
+		query += " AND id = ANY($2)"
+		args = append(args, ids)
+	}
+	tag, err := DB.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menandai notifikasi dibaca: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetNotificationPreferences mengambil preferensi kanal notifikasi pengguna, atau nilai default jika belum diatur.
+func GetNotificationPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM (SELECT 1) d
+		LEFT JOIN notification_preferences np ON np.user_id = $1::uuid;
+	`, preferenceColumns)
+	var prefs models.NotificationPreferences
+	if err := DB.QueryRow(ctx, query, userID).Scan(&prefs.EmailEnabled, &prefs.PushEnabled); err != nil {
+		return nil, fmt.Errorf("gagal query GetNotificationPreferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// UpdateNotificationPreferences mengubah preferensi kanal notifikasi pengguna. Nilai nil berarti tidak diubah.
+func UpdateNotificationPreferences(ctx context.Context, userID string, emailEnabled, pushEnabled *bool) (*models.NotificationPreferences, error) {
+	var prefs models.NotificationPreferences
+	err := DB.QueryRow(ctx, `
+		INSERT INTO notification_preferences AS np (user_id, email_enabled, push_enabled)
+		VALUES ($1::uuid, COALESCE($2, FALSE), COALESCE($3, TRUE))
+		ON CONFLICT (user_id) DO UPDATE SET
+			email_enabled = COALESCE($2, np.email_enabled),
+			push_enabled = COALESCE($3, np.push_enabled),
+			updated_at = NOW()
+		RETURNING np.email_enabled, np.push_enabled;
+	`, userID, emailEnabled, pushEnabled).Scan(&prefs.EmailEnabled, &prefs.PushEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan preferensi notifikasi: %w", err)
+	}
+	return &prefs, nil
+}
+
+// pushSubscriptionColumns adalah kolom langganan Web Push yang dipilih.
+const pushSubscriptionColumns = `id, user_id::text, endpoint, p256dh, auth, user_agent, created_at`
+
+// scanPushSubscription membaca satu baris hasil query yang memakai pushSubscriptionColumns.
+func scanPushSubscription(row pgx.Row) (*models.PushSubscription, error) {
+	var s models.PushSubscription
+	err := row.Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256DH, &s.Auth, &s.UserAgent, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SavePushSubscription menyimpan langganan Web Push milik pengguna. Endpoint yang sudah terdaftar atas nama
+// pengguna yang sama diperbarui kuncinya; endpoint milik pengguna lain ditolak dengan ErrPushEndpointTaken.
+func SavePushSubscription(ctx context.Context, sub models.PushSubscription) (*models.PushSubscription, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent)
+		VALUES ($1::uuid, $2, $3, $4, $5)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth, user_agent = EXCLUDED.user_agent
+			WHERE push_subscriptions.user_id = EXCLUDED.user_id
+		RETURNING %s;
+	`, pushSubscriptionColumns)
+	saved, err := scanPushSubscription(DB.QueryRow(ctx, query, sub.UserID, sub.Endpoint, sub.P256DH, sub.Auth, sub.UserAgent))
+	if err != nil {
+		// Tidak ada baris yang dikembalikan berarti endpoint bentrok dengan langganan milik pengguna lain
+		if err == pgx.ErrNoRows {
+			return nil, ErrPushEndpointTaken
+		}
+		return nil, fmt.Errorf("gagal menyimpan langganan push: %w", err)
+	}
+	return saved, nil
+}
+
+// ListPushSubscriptions mengambil semua langganan Web Push milik pengguna-pengguna di userIDs.
+func ListPushSubscriptions(ctx context.Context, userIDs []string) ([]models.PushSubscription, error) {
+	query := fmt.Sprintf("SELECT %s FROM push_subscriptions WHERE user_id = ANY($1::uuid[]) ORDER BY id;", pushSubscriptionColumns)
+	rows, err := DB.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ListPushSubscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []models.PushSubscription{}
+	for rows.Next() {
+		s, err := scanPushSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris langganan push: %w", err)
+		}
+		subs = append(subs, *s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris langganan push: %w", err)
+	}
+	return subs, nil
+}
+
+// DeletePushSubscription menghapus langganan Web Push milik pengguna.
+// Mengembalikan false jika langganan tidak ditemukan atau bukan milik pengguna.
+func DeletePushSubscription(ctx context.Context, userID string, id int64) (bool, error) {
+	tag, err := DB.Exec(ctx, "DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2::uuid", id, userID)
+	if err != nil {
+		return false, fmt.Errorf("gagal menghapus langganan push: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeletePushSubscriptionByEndpoint menghapus langganan yang sudah kedaluwarsa menurut push service.
+func DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	if _, err := DB.Exec(ctx, "DELETE FROM push_subscriptions WHERE endpoint = $1", endpoint); err != nil {
+		return fmt.Errorf("gagal menghapus langganan push kedaluwarsa: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDuplicatePageNumber dikembalikan jika nomor halaman sudah dipakai halaman lain pada chapter yang sama.
+var ErrDuplicatePageNumber = errors.New("nomor halaman sudah digunakan untuk chapter ini")
+
+// GetMaxPageNumber mengembalikan nomor halaman terbesar pada sebuah chapter, atau 0 jika belum ada halaman.
+func GetMaxPageNumber(ctx context.Context, chapterID int64) (int, error) {
+	var maxPage int
+	err := DB.QueryRow(ctx, "SELECT COALESCE(MAX(page_number), 0) FROM pages WHERE chapter_id = $1", chapterID).Scan(&maxPage)
+	if err != nil {
+		return 0, fmt.Errorf("gagal query GetMaxPageNumber: %w", err)
+	}
+	return maxPage, nil
+}
+
+// CreatePages menyimpan beberapa halaman sekaligus untuk sebuah chapter dalam satu transaksi.
+func CreatePages(ctx context.Context, chapterID int64, pages []models.Page) ([]models.Page, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi CreatePages: %w", err)
+	}
+	defer tx.Rollback(ctx) // Tidak berpengaruh jika transaksi sudah di-commit
+
+	created, err := insertPages(ctx, tx, chapterID, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi CreatePages: %w", err)
+	}
+	return created, nil
+}
+
+// insertPages menyisipkan halaman-halaman ke tabel pages menggunakan transaksi yang diberikan.
+func insertPages(ctx context.Context, tx pgx.Tx, chapterID int64, pages []models.Page) ([]models.Page, error) {
+	query := `
+		INSERT INTO pages (chapter_id, image_url, page_number, width, height)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, chapter_id, image_url, page_number, width, height, created_at;
+	`
+	created := make([]models.Page, 0, len(pages))
+	for _, page := range pages {
+		var p models.Page
+		err := tx.QueryRow(ctx, query, chapterID, page.ImageURL, page.PageNumber, page.Width, page.Height).Scan(
+			&p.ID,
+			&p.ChapterID,
+			&p.ImageURL,
+			&p.PageNumber,
+			&p.Width,
+			&p.Height,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			if isPgError(err, pgUniqueViolation) {
+				return nil, ErrDuplicatePageNumber
+			}
+			return nil, fmt.Errorf("gagal menyimpan halaman %d: %w", page.PageNumber, err)
+		}
+		created = append(created, p)
+	}
+	return created, nil
+}
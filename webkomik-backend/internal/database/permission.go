@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// ErrUnknownPermission dikembalikan jika izin yang diberikan tidak terdaftar di tabel permissions.
+var ErrUnknownPermission = errors.New("izin tidak dikenal")
+
+// ListPermissions mengambil seluruh izin yang terdaftar.
+func ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	rows, err := DB.Query(ctx, "SELECT name, description FROM permissions ORDER BY name;")
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ListPermissions: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, fmt.Errorf("gagal scan baris izin: %w", err)
+		}
+		permissions = append(permissions, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris izin: %w", err)
+	}
+	return permissions, nil
+}
+
+// GetRolePermissions mengambil pemetaan seluruh peran ke daftar izinnya.
+func GetRolePermissions(ctx context.Context) (map[string][]string, error) {
+	rows, err := DB.Query(ctx, "SELECT role, permission FROM role_permissions ORDER BY role, permission;")
+	if err != nil {
+		return nil, fmt.Errorf("gagal query GetRolePermissions: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var role, permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, fmt.Errorf("gagal scan baris izin peran: %w", err)
+		}
+		result[role] = append(result[role], permission)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris izin peran: %w", err)
+	}
+	return result, nil
+}
+
+// SetRolePermissions mengganti seluruh izin sebuah peran dalam satu transaksi.
+func SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi SetRolePermissions: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	if _, err := tx.Exec(ctx, "DELETE FROM role_permissions WHERE role = $1", role); err != nil {
+		return fmt.Errorf("gagal menghapus izin lama peran %s: %w", role, err)
+	}
+	for _, permission := range permissions {
+		_, err := tx.Exec(ctx, "INSERT INTO role_permissions (role, permission) VALUES ($1, $2) ON CONFLICT DO NOTHING", role, permission)
+		if err != nil {
+			if isPgError(err, pgForeignKeyViolation) {
+				return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+			}
+			return fmt.Errorf("gagal menyimpan izin peran %s: %w", role, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi SetRolePermissions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,405 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Aksi pada alur publikasi komik dan chapter.
+const (
+	PublicationSubmit  = "submit"  // Tim komik mengajukan konten untuk ditinjau
+	PublicationApprove = "approve" // Peninjau mempublikasikan konten
+	PublicationReject  = "reject"  // Peninjau mengembalikan konten ke draft dengan alasan
+	PublicationHide    = "hide"    // Peninjau menurunkan konten dari publik
+)
+
+// ErrInvalidTransition dikembalikan jika aksi tidak berlaku untuk status publikasi saat ini,
+// misalnya menyetujui komik yang masih draft.
+var ErrInvalidTransition = errors.New("aksi tidak bisa dilakukan pada status publikasi saat ini")
+
+// publicationTransition adalah status asal yang diizinkan dan status tujuan sebuah aksi.
+type publicationTransition struct {
+	from []string
+	to   string
+}
+
+// publicationTransitions memetakan aksi ke transisi statusnya.
+var publicationTransitions = map[string]publicationTransition{
+	PublicationSubmit:  {from: []string{models.PublicationDraft, models.PublicationHidden}, to: models.PublicationInReview},
+	PublicationApprove: {from: []string{models.PublicationInReview, models.PublicationHidden}, to: models.PublicationPublished},
+	PublicationReject:  {from: []string{models.PublicationInReview}, to: models.PublicationDraft},
+	PublicationHide:    {from: []string{models.PublicationInReview, models.PublicationPublished}, to: models.PublicationHidden},
+}
+
+// publishedAtOnApprove adalah ekspresi published_at saat konten disetujui per tabel.
+// Chapter dengan publish_at di masa depan belum rilis; published_at-nya diisi scheduler saat jadwal tiba.
+var publishedAtOnApprove = map[string]string{
+	"comics":   "COALESCE(published_at, NOW())",
+	"chapters": "COALESCE(published_at, CASE WHEN publish_at IS NULL OR publish_at <= NOW() THEN NOW() END)",
+}
+
+// ChangeComicStatus menjalankan aksi publikasi pada komik. actorID adalah pengguna yang melakukan aksi
+// dan note adalah alasan dari peninjau (dipakai untuk reject dan hide).
+func ChangeComicStatus(ctx context.Context, comicID int64, action, actorID string, note *string) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi ChangeComicStatus: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	if _, err := changePublicationStatus(ctx, tx, "comics", comicID, action, actorID, note); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi ChangeComicStatus: %w", err)
+	}
+	return nil
+}
+
+// ChangeChapterStatus menjalankan aksi publikasi pada chapter. released bernilai true jika chapter
+// baru saja rilis untuk pertama kali; dalam hal ini updated_at komiknya ikut diperbarui agar komik naik
+// di urutan "terakhir diperbarui" dan chapter dicatat di outbox notifikasi chapter baru. Chapter terjadwal yang disetujui sebelum publish_at belum dianggap rilis.
+func ChangeChapterStatus(ctx context.Context, chapterID int64, action, actorID string, note *string) (released bool, err error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi ChangeChapterStatus: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	released, err = changePublicationStatus(ctx, tx, "chapters", chapterID, action, actorID, note)
+	if err != nil {
+		return false, err
+	}
+	if released {
+		_, err := tx.Exec(ctx, "UPDATE comics SET updated_at = NOW() WHERE id = (SELECT comic_id FROM chapters WHERE id = $1)", chapterID)
+		if err != nil {
+			return false, fmt.Errorf("gagal memperbarui updated_at komik: %w", err)
+		}
+		// Pemanggil langsung mengirim event chapter baru, jadi baris outbox dicatat sudah dicoba
+		if err := enqueueChapterRelease(ctx, tx, []int64{chapterID}, true); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit transaksi ChangeChapterStatus: %w", err)
+	}
+	return released, nil
+}
+
+// changePublicationStatus mengubah status baris id pada table ("comics" atau "chapters") sesuai aksi.
+// Mengembalikan true jika konten baru pertama kali rilis untuk publik.
+func changePublicationStatus(ctx context.Context, tx pgx.Tx, table string, id int64, action, actorID string, note *string) (bool, error) {
+	transition, ok := publicationTransitions[action]
+	if !ok {
+		return false, fmt.Errorf("aksi publikasi tidak dikenal: %s", action)
+	}
+
+	var (
+		status      string
+		neverPublic bool
+	)
+	query := fmt.Sprintf("SELECT status, published_at IS NULL FROM %s WHERE id = $1 FOR UPDATE", table)
+	if err := tx.QueryRow(ctx, query, id).Scan(&status, &neverPublic); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, fmt.Errorf("data %s dengan ID %d tidak ditemukan", table, id)
+		}
+		return false, fmt.Errorf("gagal membaca status publikasi: %w", err)
+	}
+	if !slices.Contains(transition.from, status) {
+		return false, ErrInvalidTransition
+	}
+
+	switch action {
+	case PublicationSubmit:
+		query = fmt.Sprintf("UPDATE %s SET status = $2, review_note = NULL, submitted_at = NOW() WHERE id = $1", table)
+		_, err := tx.Exec(ctx, query, id, transition.to)
+		if err != nil {
+			return false, fmt.Errorf("gagal mengajukan review: %w", err)
+		}
+	case PublicationApprove:
+		query = fmt.Sprintf(`
+			UPDATE %s SET status = $2, review_note = NULL, reviewed_at = NOW(), reviewed_by = $3,
+				published_at = %s
+			WHERE id = $1
+			RETURNING published_at IS NOT NULL`, table, publishedAtOnApprove[table])
+		var public bool
+		if err := tx.QueryRow(ctx, query, id, transition.to, actorID).Scan(&public); err != nil {
+			return false, fmt.Errorf("gagal mempublikasikan: %w", err)
+		}
+		return neverPublic && public, nil
+	default:
+		query = fmt.Sprintf("UPDATE %s SET status = $2, review_note = $4, reviewed_at = NOW(), reviewed_by = $3 WHERE id = $1", table)
+		_, err := tx.Exec(ctx, query, id, transition.to, actorID, note)
+		if err != nil {
+			return false, fmt.Errorf("gagal mengubah status publikasi: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// ReleaseDueChapters merilis chapter terjadwal yang publish_at-nya sudah lewat: published_at diisi dengan
+// publish_at, updated_at komiknya diperbarui, dan chapter dicatat di outbox notifikasi chapter baru. Baris dikunci dengan SKIP LOCKED sehingga aman dijalankan
+// oleh beberapa instance server sekaligus. Mengembalikan chapter yang dirilis beserta judul komiknya.
+func ReleaseDueChapters(ctx context.Context, limit int) ([]models.Chapter, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi ReleaseDueChapters: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	query := fmt.Sprintf(`
+		WITH due AS (
+			SELECT id FROM chapters
+			WHERE status = 'published' AND published_at IS NULL AND publish_at <= NOW()
+			ORDER BY publish_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE chapters AS ch SET published_at = ch.publish_at
+		FROM due
+		WHERE ch.id = due.id
+		RETURNING %s, (SELECT title FROM comics WHERE id = ch.comic_id);
+	`, chapterColumns)
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal merilis chapter terjadwal: %w", err)
+	}
+	chapters := []models.Chapter{}
+	for rows.Next() {
+		var ch models.Chapter
+		err := rows.Scan(
+			&ch.ID,
+			&ch.ComicID,
+			&ch.ChapterNumber,
+			&ch.Title,
+			&ch.Status,
+			&ch.ReviewNote,
+			&ch.PublishAt,
+			&ch.PublishedAt,
+			&ch.CreatedAt,
+			&ch.UpdatedAt,
+			&ch.ComicTitle,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("gagal scan chapter yang dirilis: %w", err)
+		}
+		chapters = append(chapters, ch)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi chapter yang dirilis: %w", err)
+	}
+
+	chapterIDs := make([]int64, len(chapters))
+	for i, ch := range chapters {
+		if _, err := tx.Exec(ctx, "UPDATE comics SET updated_at = $2 WHERE id = $1 AND updated_at < $2", ch.ComicID, *ch.PublishedAt); err != nil {
+			return nil, fmt.Errorf("gagal memperbarui updated_at komik: %w", err)
+		}
+		chapterIDs[i] = ch.ID
+	}
+	if err := enqueueChapterRelease(ctx, tx, chapterIDs, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi ReleaseDueChapters: %w", err)
+	}
+	return chapters, nil
+}
+
+// NextChapterReleaseAt mengembalikan jadwal rilis terdekat yang belum diproses, atau nil jika tidak ada.
+func NextChapterReleaseAt(ctx context.Context) (*time.Time, error) {
+	var next *time.Time
+	err := DB.QueryRow(ctx, `
+		SELECT MIN(publish_at) FROM chapters
+		WHERE status = 'published' AND published_at IS NULL AND publish_at IS NOT NULL;
+	`).Scan(&next)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query NextChapterReleaseAt: %w", err)
+	}
+	return next, nil
+}
+
+// ClearChapterSchedule menghapus jadwal rilis chapter yang belum rilis. Chapter yang sudah disetujui langsung
+// rilis saat itu juga dan dicatat di outbox notifikasi, yang kemudian diumumkan oleh scheduler rilis chapter.
+// Mengembalikan true jika chapter langsung rilis.
+func ClearChapterSchedule(ctx context.Context, chapterID int64) (released bool, err error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi ClearChapterSchedule: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	var comicID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE chapters SET publish_at = NULL, updated_at = NOW(),
+			published_at = CASE WHEN status = 'published' THEN NOW() END
+		WHERE id = $1 AND published_at IS NULL
+		RETURNING comic_id, published_at IS NOT NULL;
+	`, chapterID).Scan(&comicID, &released)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, ErrInvalidTransition // Chapter sudah rilis atau tidak ada
+		}
+		return false, fmt.Errorf("gagal menghapus jadwal rilis chapter: %w", err)
+	}
+	if released {
+		if _, err := tx.Exec(ctx, "UPDATE comics SET updated_at = NOW() WHERE id = $1", comicID); err != nil {
+			return false, fmt.Errorf("gagal memperbarui updated_at komik: %w", err)
+		}
+		if err := enqueueChapterRelease(ctx, tx, []int64{chapterID}, false); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit transaksi ClearChapterSchedule: %w", err)
+	}
+	return released, nil
+}
+
+// enqueueChapterRelease mencatat chapter yang baru rilis di outbox notifikasi chapter baru, di dalam transaksi
+// yang mengisi published_at-nya. attempted true berarti pemanggil langsung mengirim event sendiri, sehingga
+// scheduler baru mengulanginya jika fan-out tidak selesai dalam waktu yang wajar.
+func enqueueChapterRelease(ctx context.Context, tx pgx.Tx, chapterIDs []int64, attempted bool) error {
+	if len(chapterIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO chapter_release_outbox (chapter_id, attempted_at)
+		SELECT id, CASE WHEN $2 THEN NOW() END FROM unnest($1::bigint[]) AS id
+		ON CONFLICT (chapter_id) DO NOTHING;
+	`, chapterIDs, attempted)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat outbox rilis chapter: %w", err)
+	}
+	return nil
+}
+
+// ClaimChapterReleases mengambil paling banyak limit chapter dari outbox notifikasi yang belum pernah dicoba
+// atau percobaan terakhirnya lebih lama dari retryAfter, lalu menandainya sedang dicoba. Mengembalikan chapter
+// beserta judul komiknya. Baris dikunci dengan SKIP LOCKED sehingga aman dijalankan beberapa instance server.
+func ClaimChapterReleases(ctx context.Context, limit int, retryAfter time.Duration) ([]models.Chapter, error) {
+	query := fmt.Sprintf(`
+		WITH claimed AS (
+			SELECT chapter_id FROM chapter_release_outbox
+			WHERE attempted_at IS NULL OR attempted_at <= NOW() - make_interval(secs => $2)
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), marked AS (
+			UPDATE chapter_release_outbox o SET attempted_at = NOW()
+			FROM claimed
+			WHERE o.chapter_id = claimed.chapter_id
+			RETURNING o.chapter_id
+		)
+		SELECT %s, c.title
+		FROM marked
+		JOIN chapters ch ON ch.id = marked.chapter_id
+		JOIN comics c ON c.id = ch.comic_id;
+	`, chapterColumns)
+	rows, err := DB.Query(ctx, query, limit, retryAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ClaimChapterReleases: %w", err)
+	}
+	defer rows.Close()
+
+	chapters := []models.Chapter{}
+	for rows.Next() {
+		var ch models.Chapter
+		err := rows.Scan(
+			&ch.ID,
+			&ch.ComicID,
+			&ch.ChapterNumber,
+			&ch.Title,
+			&ch.Status,
+			&ch.ReviewNote,
+			&ch.PublishAt,
+			&ch.PublishedAt,
+			&ch.CreatedAt,
+			&ch.UpdatedAt,
+			&ch.ComicTitle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan chapter dari outbox: %w", err)
+		}
+		chapters = append(chapters, ch)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi chapter dari outbox: %w", err)
+	}
+	return chapters, nil
+}
+
+// CompleteChapterRelease menghapus chapter dari outbox setelah notifikasi chapter barunya selesai dibuat.
+func CompleteChapterRelease(ctx context.Context, chapterID int64) error {
+	if _, err := DB.Exec(ctx, "DELETE FROM chapter_release_outbox WHERE chapter_id = $1", chapterID); err != nil {
+		return fmt.Errorf("gagal menghapus outbox rilis chapter: %w", err)
+	}
+	return nil
+}
+
+// ListChaptersByStatus mengambil chapter dengan status tertentu dari semua komik aktif beserta judul komiknya,
+// diurutkan dari yang paling lama diajukan. Dipakai untuk antrean review.
+func ListChaptersByStatus(ctx context.Context, status string, limit, offset int) ([]models.Chapter, int64, error) {
+	var total int64
+	err := DB.QueryRow(ctx, `
+		SELECT COUNT(*) FROM chapters ch
+		JOIN comics c ON c.id = ch.comic_id AND c.deleted_at IS NULL
+		WHERE ch.status = $1;
+	`, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung chapter: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, c.title,
+			(SELECT COUNT(*) FROM pages p WHERE p.chapter_id = ch.id) AS page_count
+		FROM chapters ch
+		JOIN comics c ON c.id = ch.comic_id AND c.deleted_at IS NULL
+		WHERE ch.status = $1
+		ORDER BY ch.submitted_at ASC NULLS LAST, ch.id ASC
+		LIMIT $2 OFFSET $3;
+	`, chapterColumns)
+	rows, err := DB.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal query ListChaptersByStatus: %w", err)
+	}
+	defer rows.Close()
+
+	chapters := []models.Chapter{}
+	for rows.Next() {
+		var ch models.Chapter
+		err := rows.Scan(
+			&ch.ID,
+			&ch.ComicID,
+			&ch.ChapterNumber,
+			&ch.Title,
+			&ch.Status,
+			&ch.ReviewNote,
+			&ch.PublishAt,
+			&ch.PublishedAt,
+			&ch.CreatedAt,
+			&ch.UpdatedAt,
+			&ch.ComicTitle,
+			&ch.PageCount,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal scan baris chapter: %w", err)
+		}
+		chapters = append(chapters, ch)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterasi baris chapter: %w", err)
+	}
+	return chapters, total, nil
+}
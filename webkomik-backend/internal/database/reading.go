@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// RecordReadingProgress menyimpan sekumpulan laporan posisi baca pengguna dalam satu perintah SQL:
+// riwayat per chapter, posisi terakhir per komik, dan chapter terakhir dibaca di pustaka.
+// Laporan untuk chapter yang tidak ada atau belum rilis untuk publik diabaikan, begitu juga laporan
+// yang lebih lama dari data tersimpan. Mengembalikan jumlah laporan yang diterima.
+func RecordReadingProgress(ctx context.Context, userID string, updates []models.ProgressUpdate) (int, error) {
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	chapterIDs := make([]int64, len(updates))
+	pageNumbers := make([]int32, len(updates))
+	readAts := make([]time.Time, len(updates))
+	for i, u := range updates {
+		chapterIDs[i] = u.ChapterID
+		pageNumbers[i] = int32(u.PageNumber)
+		readAts[i] = u.ReadAt
+	}
+
+	// Laporan untuk chapter yang sama di satu batch diambil yang terbaru (DISTINCT ON),
+	// karena satu perintah tidak boleh meng-upsert baris yang sama dua kali.
+	query := fmt.Sprintf(`
+		WITH input AS (
+			SELECT * FROM unnest($2::bigint[], $3::int[], $4::timestamptz[]) AS t(chapter_id, page_number, read_at)
+		), valid AS (
+			SELECT DISTINCT ON (ch.id) ch.id AS chapter_id, ch.comic_id, ch.chapter_number, i.page_number, i.read_at
+			FROM input i
+			JOIN chapters ch ON ch.id = i.chapter_id
+			JOIN comics c ON c.id = ch.comic_id
+			WHERE %s AND c.deleted_at IS NULL AND c.status = 'published'
+			ORDER BY ch.id, i.read_at DESC
+		), history AS (
+			INSERT INTO reading_history AS rh (user_id, chapter_id, comic_id, page_number, read_at)
+			SELECT $1::uuid, chapter_id, comic_id, page_number, read_at FROM valid
+			ON CONFLICT (user_id, chapter_id) DO UPDATE
+				SET page_number = EXCLUDED.page_number, read_at = EXCLUDED.read_at
+				WHERE rh.read_at <= EXCLUDED.read_at
+		), progress AS (
+			INSERT INTO reading_progress AS rp (user_id, comic_id, chapter_id, page_number, updated_at)
+			SELECT $1::uuid, comic_id, chapter_id, page_number, read_at
+			FROM (SELECT DISTINCT ON (comic_id) * FROM valid ORDER BY comic_id, read_at DESC) latest
+			ON CONFLICT (user_id, comic_id) DO UPDATE
+				SET chapter_id = EXCLUDED.chapter_id, page_number = EXCLUDED.page_number, updated_at = EXCLUDED.updated_at
+				WHERE rp.updated_at <= EXCLUDED.updated_at
+		), library AS (
+			UPDATE library_entries AS le SET
+				last_read_chapter_number = GREATEST(le.last_read_chapter_number, r.chapter_number),
+				last_read_at = GREATEST(le.last_read_at, r.read_at),
+				updated_at = NOW()
+			FROM (
+				SELECT comic_id, MAX(chapter_number) AS chapter_number, MAX(read_at) AS read_at
+				FROM valid GROUP BY comic_id
+			) r
+			WHERE le.user_id = $1::uuid AND le.comic_id = r.comic_id
+		)
+		SELECT COUNT(*) FROM valid;
+	`, releasedChapterCondition)
+
+	var accepted int
+	if err := DB.QueryRow(ctx, query, userID, chapterIDs, pageNumbers, readAts).Scan(&accepted); err != nil {
+		return 0, fmt.Errorf("gagal menyimpan progres baca: %w", err)
+	}
+	return accepted, nil
+}
+
+// progressQuery membentuk query posisi baca pengguna ($1) dengan kondisi tambahan pada alias rp dan c.
+// Hanya komik berstatus published dan chapter yang sudah rilis yang ditampilkan.
+func progressQuery(extraCondition, suffix string) string {
+	return fmt.Sprintf(`
+		SELECT
+			rp.comic_id, c.title, c.cover_image_url,
+			ch.id, ch.chapter_number, ch.title,
+			rp.page_number,
+			(SELECT COUNT(*) FROM pages p WHERE p.chapter_id = ch.id)::int AS page_count,
+			nx.id, nx.chapter_number, nx.title,
+			rp.updated_at
+		FROM reading_progress rp
+		JOIN comics c ON c.id = rp.comic_id
+		JOIN chapters ch ON ch.id = rp.chapter_id
+		LEFT JOIN LATERAL (
+			SELECT ch.id, ch.chapter_number, ch.title FROM chapters ch
+			WHERE ch.comic_id = rp.comic_id AND ch.chapter_number > (SELECT chapter_number FROM chapters WHERE id = rp.chapter_id)
+				AND %[1]s
+			ORDER BY ch.chapter_number ASC
+			LIMIT 1
+		) nx ON true
+		WHERE rp.user_id = $1::uuid AND c.deleted_at IS NULL AND c.status = 'published' AND %[1]s%[2]s
+		%[3]s;
+	`, releasedChapterCondition, extraCondition, suffix)
+}
+
+// scanReadingProgress membaca satu baris hasil progressQuery.
+func scanReadingProgress(row pgx.Row) (*models.ReadingProgress, error) {
+	var (
+		p          models.ReadingProgress
+		nextID     *int64
+		nextNumber *float32
+		nextTitle  *string
+	)
+	err := row.Scan(
+		&p.ComicID,
+		&p.ComicTitle,
+		&p.CoverImageURL,
+		&p.Chapter.ID,
+		&p.Chapter.ChapterNumber,
+		&p.Chapter.Title,
+		&p.PageNumber,
+		&p.PageCount,
+		&nextID,
+		&nextNumber,
+		&nextTitle,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if nextID != nil {
+		p.NextChapter = &models.ChapterRef{ID: *nextID, ChapterNumber: *nextNumber, Title: nextTitle}
+	}
+	return &p, nil
+}
+
+// GetReadingProgress mengambil posisi baca terakhir pengguna pada sebuah komik.
+// Mengembalikan nil, nil jika pengguna belum pernah membaca komik tersebut.
+func GetReadingProgress(ctx context.Context, userID string, comicID int64) (*models.ReadingProgress, error) {
+	p, err := scanReadingProgress(DB.QueryRow(ctx, progressQuery(" AND rp.comic_id = $2", ""), userID, comicID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query GetReadingProgress: %w", err)
+	}
+	return p, nil
+}
+
+// ListContinueReading mengambil komik yang sedang dibaca pengguna, yang terakhir dibaca lebih dulu.
+// Komik yang sudah dibaca sampai halaman terakhir chapter terbaru tidak ditampilkan.
+func ListContinueReading(ctx context.Context, userID string, limit int) ([]models.ReadingProgress, error) {
+	// Kondisi "selesai" memakai nx dan page_count yang sama dengan kolom yang dipilih
+	query := progressQuery(
+		" AND NOT (nx.id IS NULL AND rp.page_number >= (SELECT COUNT(*) FROM pages p WHERE p.chapter_id = ch.id))",
+		"ORDER BY rp.updated_at DESC, rp.comic_id DESC LIMIT $2",
+	)
+	rows, err := DB.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ListContinueReading: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ReadingProgress{}
+	for rows.Next() {
+		p, err := scanReadingProgress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris lanjutkan membaca: %w", err)
+		}
+		entries = append(entries, *p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris lanjutkan membaca: %w", err)
+	}
+	return entries, nil
+}
+
+// ListReadingHistory mengambil riwayat chapter yang dibaca pengguna, yang terakhir dibaca lebih dulu,
+// beserta total riwayat untuk paginasi. Jika comicID diisi, hanya riwayat komik tersebut yang diambil.
+func ListReadingHistory(ctx context.Context, userID string, comicID *int64, limit, offset int) ([]models.ReadingHistoryEntry, int64, error) {
+	conditions := []string{"rh.user_id = $1::uuid", "c.deleted_at IS NULL", "c.status = 'published'", releasedChapterCondition}
+	args := []interface{}{userID}
+	if comicID != nil {
+		args = append(args, *comicID)
+		conditions = append(conditions, fmt.Sprintf("rh.comic_id = $%d", len(args)))
+	}
+	fromClause := fmt.Sprintf(`
+		FROM reading_history rh
+		JOIN comics c ON c.id = rh.comic_id
+		JOIN chapters ch ON ch.id = rh.chapter_id
+		WHERE %s
+	`, strings.Join(conditions, " AND "))
+
+	var total int64
+	if err := DB.QueryRow(ctx, "SELECT COUNT(*) "+fromClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total riwayat baca: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT
+			rh.comic_id, c.title, c.cover_image_url,
+			ch.id, ch.chapter_number, ch.title,
+			rh.page_number,
+			(SELECT COUNT(*) FROM pages p WHERE p.chapter_id = ch.id)::int AS page_count,
+			rh.read_at
+		%s
+		ORDER BY rh.read_at DESC, rh.chapter_id DESC
+		LIMIT $%d OFFSET $%d;
+	`, fromClause, len(args)-1, len(args))
+
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal query ListReadingHistory: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ReadingHistoryEntry{}
+	for rows.Next() {
+		var e models.ReadingHistoryEntry
+		err := rows.Scan(
+			&e.ComicID,
+			&e.ComicTitle,
+			&e.CoverImageURL,
+			&e.Chapter.ID,
+			&e.Chapter.ChapterNumber,
+			&e.Chapter.Title,
+			&e.PageNumber,
+			&e.PageCount,
+			&e.ReadAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal scan baris riwayat baca: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterasi baris riwayat baca: %w", err)
+	}
+	return entries, total, nil
+}
+
+// ClearReadingHistory menghapus riwayat baca pengguna, atau hanya untuk satu komik jika comicID diisi.
+// Posisi baca terakhir komik yang riwayatnya dihapus ikut dihapus agar tidak muncul di lanjutkan membaca.
+func ClearReadingHistory(ctx context.Context, userID string, comicID *int64) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi ClearReadingHistory: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	for _, table := range []string{"reading_history", "reading_progress"} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1::uuid AND ($2::bigint IS NULL OR comic_id = $2);", table)
+		if _, err := tx.Exec(ctx, query, userID, comicID); err != nil {
+			return fmt.Errorf("gagal menghapus %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit penghapusan riwayat baca: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/similarity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// coReaderWeight dan genreWeight adalah bobot co-occurrence pembaca (cosine) dan kesamaan genre (Jaccard)
+	// pada skor kemiripan antar komik.
+	coReaderWeight = 0.7
+	genreWeight    = 0.3
+	// similarNeighbors adalah jumlah komik paling mirip yang disimpan untuk setiap komik.
+	similarNeighbors = 50
+	// genreCandidates adalah jumlah komik terpopuler per genre yang dipertimbangkan sebagai tetangga lewat
+	// genre saja, agar genre besar tidak menghasilkan pasangan sebanyak kuadrat jumlah komiknya.
+	genreCandidates = 100
+	// interactionsPerUser membatasi interaksi terbaru per pengguna yang dihitung, agar pengguna dengan
+	// ribuan interaksi tidak mendominasi skor maupun waktu perhitungan.
+	interactionsPerUser = 200
+	// similarityWriteBatch adalah jumlah komik sumber yang kemiripannya ditulis dalam satu transaksi.
+	similarityWriteBatch = 200
+)
+
+// similarityLockKey adalah kunci advisory lock agar hanya satu instance server yang menghitung ulang
+// kemiripan komik pada satu waktu.
+const similarityLockKey = "comic_similarities"
+
+// RefreshComicSimilarities menghitung ulang tabel comic_similarities dari data pustaka, riwayat baca,
+// rating, dan genre komik yang dipublikasikan. Interaksi pengguna dengan sebuah komik adalah follow atau bookmark,
+// membaca chapter-nya, atau memberi rating 4 ke atas. Skor dihitung di memori (lihat paket similarity) lalu
+// ditulis per batch komik dalam transaksi pendek, sehingga pembaca tabel tidak pernah melihatnya kosong.
+// Jika instance lain sedang menghitung ulang, pemanggilan ini dilewati dan mengembalikan false.
+// Mengembalikan jumlah pasangan komik yang disimpan.
+func RefreshComicSimilarities(ctx context.Context) (int64, bool, error) {
+	conn, err := DB.Acquire(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("gagal mengambil koneksi RefreshComicSimilarities: %w", err)
+	}
+	defer conn.Release()
+
+	// Lock level sesi karena perhitungan melewati beberapa transaksi; dilepas sebelum koneksi dikembalikan
+	// ke pool walaupun ctx sudah dibatalkan.
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", similarityLockKey).Scan(&locked); err != nil {
+		return 0, false, fmt.Errorf("gagal mengambil lock kemiripan komik: %w", err)
+	}
+	if !locked {
+		return 0, false, nil
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(hashtext($1))", similarityLockKey)
+
+	var startedAt time.Time
+	if err := conn.QueryRow(ctx, "SELECT NOW()").Scan(&startedAt); err != nil {
+		return 0, false, fmt.Errorf("gagal membaca waktu database: %w", err)
+	}
+
+	builder := similarity.NewBuilder()
+	if err := loadSimilarityComics(ctx, conn, builder); err != nil {
+		return 0, false, err
+	}
+	if err := loadSimilarityReaders(ctx, conn, builder); err != nil {
+		return 0, false, err
+	}
+
+	pairs := builder.Build(similarity.Params{
+		CoReaderWeight:  coReaderWeight,
+		GenreWeight:     genreWeight,
+		Neighbors:       similarNeighbors,
+		GenreCandidates: genreCandidates,
+	})
+
+	var saved int64
+	for len(pairs) > 0 {
+		// Potong batch di batas komik sumber agar semua tetangga satu komik diganti dalam transaksi yang sama
+		n, sources := 0, 0
+		for n < len(pairs) {
+			if n == 0 || pairs[n].ComicID != pairs[n-1].ComicID {
+				if sources == similarityWriteBatch {
+					break
+				}
+				sources++
+			}
+			n++
+		}
+		tag, err := writeComicSimilarities(ctx, conn, pairs[:n])
+		if err != nil {
+			return saved, false, err
+		}
+		saved += tag
+		pairs = pairs[n:]
+	}
+
+	// Komik yang tidak lagi dipublikasikan atau tidak punya tetangga tidak tersentuh batch di atas
+	if _, err := conn.Exec(ctx, "DELETE FROM comic_similarities WHERE computed_at < $1", startedAt); err != nil {
+		return saved, false, fmt.Errorf("gagal menghapus kemiripan komik lama: %w", err)
+	}
+	return saved, true, nil
+}
+
+// loadSimilarityComics mendaftarkan komik yang dipublikasikan beserta genre dan jumlah view-nya ke builder.
+func loadSimilarityComics(ctx context.Context, conn *pgxpool.Conn, builder *similarity.Builder) error {
+	rows, err := conn.Query(ctx, `
+		SELECT c.id, c.view_count, COALESCE(array_agg(cg.genre_id) FILTER (WHERE cg.genre_id IS NOT NULL), '{}')
+		FROM comics c
+		LEFT JOIN comic_genres cg ON cg.comic_id = c.id
+		WHERE c.deleted_at IS NULL AND c.status = 'published'
+		GROUP BY c.id`)
+	if err != nil {
+		return fmt.Errorf("gagal query komik untuk kemiripan: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id, views int64
+			genres    []int64
+		)
+		if err := rows.Scan(&id, &views, &genres); err != nil {
+			return fmt.Errorf("gagal scan komik untuk kemiripan: %w", err)
+		}
+		builder.AddComic(id, genres, views)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterasi komik untuk kemiripan: %w", err)
+	}
+	return nil
+}
+
+// loadSimilarityReaders membaca interaksi setiap pengguna, terbaru lebih dulu, dan mencatat paling banyak
+// interactionsPerUser komik per pengguna ke builder.
+func loadSimilarityReaders(ctx context.Context, conn *pgxpool.Conn, builder *similarity.Builder) error {
+	rows, err := conn.Query(ctx, `
+		SELECT user_id::text, comic_id FROM (
+			SELECT user_id, comic_id, updated_at AS at FROM library_entries
+			UNION ALL
+			SELECT user_id, comic_id, read_at FROM reading_history
+			UNION ALL
+			SELECT user_id, comic_id, updated_at FROM comic_reviews WHERE rating >= 4
+		) s
+		GROUP BY user_id, comic_id
+		ORDER BY user_id, MAX(at) DESC`)
+	if err != nil {
+		return fmt.Errorf("gagal query interaksi untuk kemiripan: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		currentUser string
+		comics      []int64
+	)
+	for rows.Next() {
+		var (
+			userID  string
+			comicID int64
+		)
+		if err := rows.Scan(&userID, &comicID); err != nil {
+			return fmt.Errorf("gagal scan interaksi untuk kemiripan: %w", err)
+		}
+		if userID != currentUser {
+			builder.AddReader(comics)
+			currentUser, comics = userID, comics[:0]
+		}
+		if len(comics) < interactionsPerUser {
+			comics = append(comics, comicID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterasi interaksi untuk kemiripan: %w", err)
+	}
+	builder.AddReader(comics)
+	return nil
+}
+
+// writeComicSimilarities mengganti tetangga komik-komik sumber pada pairs dalam satu transaksi.
+func writeComicSimilarities(ctx context.Context, conn *pgxpool.Conn, pairs []similarity.Pair) (int64, error) {
+	var (
+		sources   []int64
+		comicIDs  = make([]int64, len(pairs))
+		similarID = make([]int64, len(pairs))
+		scores    = make([]float64, len(pairs))
+		coReaders = make([]int32, len(pairs))
+	)
+	for i, p := range pairs {
+		if i == 0 || p.ComicID != pairs[i-1].ComicID {
+			sources = append(sources, p.ComicID)
+		}
+		comicIDs[i], similarID[i], scores[i], coReaders[i] = p.ComicID, p.SimilarComicID, p.Score, int32(p.CoReaders)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("gagal memulai transaksi kemiripan komik: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	if _, err := tx.Exec(ctx, "DELETE FROM comic_similarities WHERE comic_id = ANY($1)", sources); err != nil {
+		return 0, fmt.Errorf("gagal menghapus kemiripan komik lama: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO comic_similarities (comic_id, similar_comic_id, score, co_readers)
+		SELECT * FROM unnest($1::bigint[], $2::bigint[], $3::float8[], $4::int[])`,
+		comicIDs, similarID, scores, coReaders)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menyimpan kemiripan komik: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("gagal commit transaksi kemiripan komik: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// userSignalsQuery menghitung bobot minat pengguna $1 pada setiap komik yang pernah disentuhnya:
+// follow 3, bookmark 1.5, membaca 1 + ln(jumlah chapter) dengan batas 3, dan rating dikurangi 2.5
+// sehingga rating 1-2 menjadi sinyal negatif.
+const userSignalsQuery = `
+	SELECT comic_id, SUM(weight) AS weight FROM (
+		SELECT comic_id, CASE WHEN following THEN 3.0 ELSE 1.5 END AS weight
+		FROM library_entries WHERE user_id = $1::uuid
+		UNION ALL
+		SELECT comic_id, LEAST(1 + ln(COUNT(*)), 3.0)
+		FROM reading_history WHERE user_id = $1::uuid GROUP BY comic_id
+		UNION ALL
+		SELECT comic_id, rating - 2.5
+		FROM comic_reviews WHERE user_id = $1::uuid
+	) s
+	GROUP BY comic_id`
+
+// ListRecommendations mengambil rekomendasi komik untuk pengguna: komik yang mirip dengan minat pengguna lebih dulu,
+// lalu jika kurang dari limit, diisi komik trending mingguan dan komik terpopuler. Komik yang sudah ada di pustaka,
+// pernah dibaca, atau pernah dinilai pengguna tidak direkomendasikan.
+func ListRecommendations(ctx context.Context, userID string, limit int) ([]models.Recommendation, error) {
+	comicColumns := fmt.Sprintf(`
+		c.id, c.title, c.description, c.author_name,
+		%s AS genres, %s AS tags,
+		c.cover_image_url, c.view_count, c.rating_average, c.rating_count,
+		c.status, c.published_at, c.created_at, c.updated_at`, comicGenresColumn("c"), comicTagsColumn("c"))
+
+	// Skor kandidat adalah jumlah bobot minat x kemiripan dari semua komik pengguna; komik yang
+	// paling besar sumbangannya menjadi alasan rekomendasi.
+	query := fmt.Sprintf(`
+		WITH profile AS (%s
+		), candidates AS (
+			SELECT
+				s.similar_comic_id AS comic_id,
+				SUM(p.weight * s.score) AS score,
+				(ARRAY_AGG(s.comic_id ORDER BY p.weight * s.score DESC))[1] AS seed_id
+			FROM profile p
+			JOIN comic_similarities s ON s.comic_id = p.comic_id
+			WHERE NOT EXISTS (SELECT 1 FROM profile x WHERE x.comic_id = s.similar_comic_id)
+			GROUP BY s.similar_comic_id
+			HAVING SUM(p.weight * s.score) > 0
+		)
+		SELECT %s, cand.score, seed.id, seed.title
+		FROM candidates cand
+		JOIN comics c ON c.id = cand.comic_id
+		LEFT JOIN comics seed ON seed.id = cand.seed_id
+		WHERE c.deleted_at IS NULL AND c.status = 'published'
+		ORDER BY cand.score DESC, c.id
+		LIMIT $2;
+	`, userSignalsQuery, comicColumns)
+
+	recs, err := queryRecommendations(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query rekomendasi personal: %w", err)
+	}
+	if len(recs) >= limit {
+		return recs, nil
+	}
+
+	exclude := make([]int64, len(recs))
+	for i, r := range recs {
+		exclude[i] = r.ID
+	}
+	fallbackQuery := fmt.Sprintf(`
+		WITH profile AS (%s
+		)
+		SELECT %s, 0::float8, NULL::bigint, NULL::text
+		FROM comics c
+		LEFT JOIN comic_trending t ON t.comic_id = c.id AND t.period = 'weekly'
+		WHERE c.deleted_at IS NULL AND c.status = 'published'
+			AND c.id <> ALL($3::bigint[])
+			AND NOT EXISTS (SELECT 1 FROM profile p WHERE p.comic_id = c.id)
+		ORDER BY t.rank NULLS LAST, c.view_count DESC, c.id
+		LIMIT $2;
+	`, userSignalsQuery, comicColumns)
+
+	trending, err := queryRecommendations(ctx, fallbackQuery, userID, limit-len(recs), exclude)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query rekomendasi trending: %w", err)
+	}
+	return append(recs, trending...), nil
+}
+
+// queryRecommendations menjalankan query rekomendasi dan membaca hasilnya. Baris tanpa komik alasan
+// dianggap rekomendasi trending.
+func queryRecommendations(ctx context.Context, query string, args ...interface{}) ([]models.Recommendation, error) {
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recs := []models.Recommendation{}
+	for rows.Next() {
+		r, err := scanRecommendation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris rekomendasi: %w", err)
+		}
+		recs = append(recs, *r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris rekomendasi: %w", err)
+	}
+	return recs, nil
+}
+
+// scanRecommendation membaca satu baris rekomendasi.
+func scanRecommendation(row pgx.Row) (*models.Recommendation, error) {
+	var (
+		r         models.Recommendation
+		seedID    *int64
+		seedTitle *string
+	)
+	err := row.Scan(
+		&r.ID,
+		&r.Title,
+		&r.Description,
+		&r.AuthorName,
+		&r.Genres,
+		&r.Tags,
+		&r.CoverImageURL,
+		&r.ViewCount,
+		&r.RatingAverage,
+		&r.RatingCount,
+		&r.Status,
+		&r.PublishedAt,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+		&r.Score,
+		&seedID,
+		&seedTitle,
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.Reason = models.RecommendationTrending
+	if seedID != nil {
+		r.Reason = models.RecommendationSimilar
+		r.BecauseOf = &models.ComicRef{ID: *seedID, Title: *seedTitle}
+	}
+	return &r, nil
+}
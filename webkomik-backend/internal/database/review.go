@@ -0,0 +1,294 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Opsi pengurutan ulasan komik.
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortOldest  = "oldest"
+	ReviewSortHighest = "highest"
+	ReviewSortLowest  = "lowest"
+)
+
+// reviewSortOrders memetakan opsi sort ulasan ke klausa ORDER BY yang aman dipakai di query.
+var reviewSortOrders = map[string]string{
+	ReviewSortNewest:  "r.created_at DESC, r.id DESC",
+	ReviewSortOldest:  "r.created_at ASC, r.id ASC",
+	ReviewSortHighest: "r.rating DESC, r.created_at DESC, r.id DESC",
+	ReviewSortLowest:  "r.rating ASC, r.created_at DESC, r.id DESC",
+}
+
+// reviewColumns adalah kolom ulasan yang dipilih, dengan alias r untuk comic_reviews.
+const reviewColumns = `
+	r.id, r.comic_id, (SELECT title FROM comics WHERE id = r.comic_id), r.user_id::text, r.rating, r.body,
+	r.status, r.moderation_note, r.report_count, r.created_at, r.updated_at, r.moderated_at`
+
+// scanReview membaca satu baris hasil query yang memakai reviewColumns.
+func scanReview(row pgx.Row) (*models.ComicReview, error) {
+	var r models.ComicReview
+	err := row.Scan(
+		&r.ID,
+		&r.ComicID,
+		&r.ComicTitle,
+		&r.UserID,
+		&r.Rating,
+		&r.Body,
+		&r.Status,
+		&r.ModerationNote,
+		&r.ReportCount,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+		&r.ModeratedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// getReview menjalankan query satu ulasan. Mengembalikan nil, nil jika ulasan tidak ditemukan.
+func getReview(ctx context.Context, query string, args ...interface{}) (*models.ComicReview, error) {
+	review, err := scanReview(DB.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query ulasan komik: %w", err)
+	}
+	return review, nil
+}
+
+// queryReviews menjalankan query ulasan dan membaca seluruh barisnya.
+func queryReviews(ctx context.Context, query string, args ...interface{}) ([]models.ComicReview, error) {
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal query ulasan komik: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []models.ComicReview{}
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal scan baris ulasan: %w", err)
+		}
+		reviews = append(reviews, *r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterasi baris ulasan: %w", err)
+	}
+	return reviews, nil
+}
+
+// GetComicReview mengambil satu ulasan berdasarkan ID. Mengembalikan nil, nil jika tidak ditemukan.
+func GetComicReview(ctx context.Context, id int64) (*models.ComicReview, error) {
+	return getReview(ctx, fmt.Sprintf("SELECT %s FROM comic_reviews r WHERE r.id = $1;", reviewColumns), id)
+}
+
+// GetUserComicReview mengambil ulasan milik pengguna untuk sebuah komik. Mengembalikan nil, nil jika belum ada.
+func GetUserComicReview(ctx context.Context, comicID int64, userID string) (*models.ComicReview, error) {
+	query := fmt.Sprintf("SELECT %s FROM comic_reviews r WHERE r.comic_id = $1 AND r.user_id = $2::uuid;", reviewColumns)
+	return getReview(ctx, query, comicID, userID)
+}
+
+// lockComicRating mengunci baris komik sehingga perubahan ulasan pada komik yang sama berjalan berurutan
+// dan agregat rating tetap sesuai dengan isi comic_reviews.
+func lockComicRating(ctx context.Context, tx pgx.Tx, comicID int64) error {
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM comics WHERE id = $1 FOR UPDATE", comicID); err != nil {
+		return fmt.Errorf("gagal mengunci komik: %w", err)
+	}
+	return nil
+}
+
+// UpsertComicReview membuat atau memperbarui rating dan ulasan pengguna untuk sebuah komik,
+// lalu menyesuaikan agregat rating komik dengan selisihnya. created bernilai true jika ulasan baru dibuat.
+// Status moderasi ulasan yang sudah ada tidak berubah.
+func UpsertComicReview(ctx context.Context, comicID int64, userID string, rating int, body *string) (review *models.ComicReview, created bool, err error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("gagal memulai transaksi UpsertComicReview: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	if err := lockComicRating(ctx, tx, comicID); err != nil {
+		return nil, false, err
+	}
+
+	var oldRating int
+	err = tx.QueryRow(ctx, "SELECT rating FROM comic_reviews WHERE comic_id = $1 AND user_id = $2::uuid", comicID, userID).Scan(&oldRating)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, false, fmt.Errorf("gagal membaca rating sebelumnya: %w", err)
+	}
+	created = err == pgx.ErrNoRows
+
+	query := fmt.Sprintf(`
+		INSERT INTO comic_reviews AS r (comic_id, user_id, rating, body)
+		VALUES ($1, $2::uuid, $3, $4)
+		ON CONFLICT (comic_id, user_id) DO UPDATE
+			SET rating = EXCLUDED.rating, body = EXCLUDED.body, updated_at = NOW()
+		RETURNING %s;
+	`, reviewColumns)
+	review, err = scanReview(tx.QueryRow(ctx, query, comicID, userID, rating, body))
+	if err != nil {
+		return nil, false, fmt.Errorf("gagal menyimpan ulasan komik: %w", err)
+	}
+
+	countDelta := 0
+	if created {
+		countDelta = 1
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE comics SET rating_sum = rating_sum + $2, rating_count = rating_count + $3 WHERE id = $1;
+	`, comicID, rating-oldRating, countDelta)
+	if err != nil {
+		return nil, false, fmt.Errorf("gagal memperbarui agregat rating komik: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("gagal commit ulasan komik: %w", err)
+	}
+	return review, created, nil
+}
+
+// DeleteComicReview menghapus ulasan beserta rating-nya dari agregat komik.
+// Mengembalikan false jika ulasan tidak ditemukan.
+func DeleteComicReview(ctx context.Context, reviewID int64) (bool, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi DeleteComicReview: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	// Komik dikunci sebelum baris ulasan agar urutan penguncian sama dengan UpsertComicReview
+	var comicID int64
+	if err := tx.QueryRow(ctx, "SELECT comic_id FROM comic_reviews WHERE id = $1", reviewID).Scan(&comicID); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("gagal membaca ulasan komik: %w", err)
+	}
+	if err := lockComicRating(ctx, tx, comicID); err != nil {
+		return false, err
+	}
+
+	var rating int
+	if err := tx.QueryRow(ctx, "DELETE FROM comic_reviews WHERE id = $1 RETURNING rating", reviewID).Scan(&rating); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil // Sudah dihapus oleh transaksi lain
+		}
+		return false, fmt.Errorf("gagal menghapus ulasan komik: %w", err)
+	}
+	_, err = tx.Exec(ctx, "UPDATE comics SET rating_sum = rating_sum - $2, rating_count = rating_count - 1 WHERE id = $1", comicID, rating)
+	if err != nil {
+		return false, fmt.Errorf("gagal memperbarui agregat rating komik: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit penghapusan ulasan: %w", err)
+	}
+	return true, nil
+}
+
+// ListComicReviews mengambil ulasan berteks yang tampil untuk publik pada sebuah komik beserta totalnya.
+func ListComicReviews(ctx context.Context, comicID int64, sort string, limit, offset int) ([]models.ComicReview, int64, error) {
+	orderBy, ok := reviewSortOrders[sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("opsi sort ulasan tidak dikenal: %s", sort)
+	}
+	const filter = "r.comic_id = $1 AND r.status = 'published' AND r.body IS NOT NULL"
+
+	var total int64
+	if err := DB.QueryRow(ctx, "SELECT COUNT(*) FROM comic_reviews r WHERE "+filter, comicID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total ulasan: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM comic_reviews r
+		WHERE %s
+		ORDER BY %s
+		LIMIT $2 OFFSET $3;
+	`, reviewColumns, filter, orderBy)
+	reviews, err := queryReviews(ctx, query, comicID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// ListReviewsForModeration mengambil ulasan berteks untuk moderator, yang paling banyak dilaporkan lebih dulu.
+// status kosong berarti semua status; reportedOnly membatasi ke ulasan yang pernah dilaporkan.
+func ListReviewsForModeration(ctx context.Context, status string, reportedOnly bool, limit, offset int) ([]models.ComicReview, int64, error) {
+	conditions := []string{"r.body IS NOT NULL"}
+	args := []interface{}{}
+	if status != "" {
+		args = append(args, status)
+		conditions = append(conditions, fmt.Sprintf("r.status = $%d", len(args)))
+	}
+	if reportedOnly {
+		conditions = append(conditions, "r.report_count > 0")
+	}
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := DB.QueryRow(ctx, "SELECT COUNT(*) FROM comic_reviews r WHERE "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total ulasan: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT %s FROM comic_reviews r
+		WHERE %s
+		ORDER BY r.report_count DESC, r.created_at DESC, r.id DESC
+		LIMIT $%d OFFSET $%d;
+	`, reviewColumns, whereClause, len(args)-1, len(args))
+	reviews, err := queryReviews(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// ModerateComicReview menyembunyikan (hide = true, dengan alasan note) atau memulihkan teks ulasan.
+// Mengembalikan nil, nil jika ulasan tidak ditemukan.
+func ModerateComicReview(ctx context.Context, reviewID int64, hide bool, moderatorID string, note *string) (*models.ComicReview, error) {
+	status := models.ReviewStatusPublished
+	if hide {
+		status = models.ReviewStatusHidden
+	}
+	query := fmt.Sprintf(`
+		UPDATE comic_reviews AS r
+		SET status = $2, moderation_note = $3, moderated_by = $4::uuid, moderated_at = NOW()
+		WHERE r.id = $1
+		RETURNING %s;
+	`, reviewColumns)
+	return getReview(ctx, query, reviewID, status, note, moderatorID)
+}
+
+// ReportComicReview mencatat laporan pengguna atas sebuah ulasan. Laporan berulang dari pengguna yang sama
+// diabaikan sehingga report_count menghitung jumlah pelapor. Mengembalikan false jika ulasan tidak ditemukan.
+func ReportComicReview(ctx context.Context, reviewID int64, userID string, reason *string) (bool, error) {
+	var found bool
+	err := DB.QueryRow(ctx, `
+		WITH reported AS (
+			INSERT INTO comic_review_reports (review_id, user_id, reason)
+			SELECT id, $2::uuid, $3 FROM comic_reviews WHERE id = $1
+			ON CONFLICT (review_id, user_id) DO NOTHING
+			RETURNING review_id
+		), counted AS (
+			UPDATE comic_reviews SET report_count = report_count + 1
+			WHERE id IN (SELECT review_id FROM reported)
+		)
+		SELECT EXISTS (SELECT 1 FROM comic_reviews WHERE id = $1);
+	`, reviewID, userID, reason).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("gagal menyimpan laporan ulasan: %w", err)
+	}
+	return found, nil
+}
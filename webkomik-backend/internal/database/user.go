@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// userColumns adalah kolom akun pengguna yang dipilih. Suspend yang sudah lewat waktunya
+// langsung dianggap aktif tanpa perlu job terpisah.
+const userColumns = `
+	u.id::text, u.email, u.role,
+	CASE WHEN u.status = 'suspended' AND u.suspended_until <= NOW() THEN 'active' ELSE u.status END AS status,
+	CASE WHEN u.status = 'suspended' AND u.suspended_until <= NOW() THEN NULL ELSE u.suspended_until END AS suspended_until,
+	CASE WHEN u.status = 'suspended' AND u.suspended_until <= NOW() THEN NULL ELSE u.status_reason END AS status_reason,
+	u.created_at, u.updated_at, u.last_seen_at`
+
+// userComicCountColumn menghitung komik aktif yang timnya diikuti pengguna. Kolom ini hanya dipilih di
+// listing dan detail admin, bukan di SyncUserAccount yang berjalan pada setiap request.
+const userComicCountColumn = `
+	(SELECT COUNT(*) FROM comic_members cm JOIN comics c ON c.id = cm.comic_id
+	 WHERE cm.user_id = u.id AND c.deleted_at IS NULL) AS comic_count`
+
+// UserListParams berisi parameter filter dan paginasi untuk ListUsers.
+type UserListParams struct {
+	Limit  int
+	Offset int
+	Query  string // Dicocokkan dengan email atau ID pengguna
+	Role   string
+	Status string
+}
+
+// scanUser membaca satu baris hasil query yang memakai userColumns. extra menampung kolom tambahan
+// yang dipilih setelah userColumns.
+func scanUser(row pgx.Row, extra ...interface{}) (*models.User, error) {
+	var user models.User
+	dest := []interface{}{
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.Status,
+		&user.SuspendedUntil,
+		&user.StatusReason,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.LastSeenAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// scanUserWithComicCount membaca satu baris hasil query yang memakai userColumns diikuti userComicCountColumn.
+func scanUserWithComicCount(row pgx.Row) (*models.User, error) {
+	var comicCount int64
+	user, err := scanUser(row, &comicCount)
+	if err != nil {
+		return nil, err
+	}
+	user.ComicCount = comicCount
+	return user, nil
+}
+
+// lastSeenResolution adalah selang minimal pembaruan last_seen_at, agar request berturut-turut dari
+// pengguna yang sama tidak menulis ke user_accounts setiap kali.
+const lastSeenResolution = 5 * time.Minute
+
+// SyncUserAccount mencatat pengguna yang baru saja terotentikasi dan mengembalikan data akunnya.
+// Akun hanya ditulis saat pertama kali terlihat, saat email atau peran dari token berubah, atau saat
+// last_seen_at sudah lebih lama dari lastSeenResolution. Peran dari JWT hanya dipakai jika belum pernah
+// diubah admin dan token diterbitkan setelah perubahan peran terakhir, sehingga token lama maupun
+// token yang di-refresh tidak menimpa peran yang diatur di aplikasi.
+func SyncUserAccount(ctx context.Context, userID, email, tokenRole string, issuedAt time.Time) (*models.User, error) {
+	var (
+		roleFromAdmin bool
+		roleUpdatedAt time.Time
+	)
+	query := fmt.Sprintf("SELECT %s, u.role_source = 'admin', u.role_updated_at FROM user_accounts u WHERE u.id = $1::uuid;", userColumns)
+	user, err := scanUser(DB.QueryRow(ctx, query, userID), &roleFromAdmin, &roleUpdatedAt)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("gagal membaca akun pengguna: %w", err)
+	}
+
+	var emailArg *string
+	if email != "" {
+		emailArg = &email
+	}
+
+	if user == nil {
+		// ON CONFLICT menangani request paralel pertama dari pengguna yang sama
+		query = fmt.Sprintf(`
+			WITH inserted AS (
+				INSERT INTO user_accounts AS u (id, email, role, role_updated_at, last_seen_at)
+				VALUES ($1, $2, $3, $4, NOW())
+				ON CONFLICT (id) DO UPDATE SET last_seen_at = NOW()
+				RETURNING u.*
+			)
+			SELECT %s FROM inserted u;
+		`, userColumns)
+		user, err = scanUser(DB.QueryRow(ctx, query, userID, emailArg, tokenRole, issuedAt))
+		if err != nil {
+			return nil, fmt.Errorf("gagal mendaftarkan akun pengguna: %w", err)
+		}
+		return user, nil
+	}
+
+	roleChanged := !roleFromAdmin && tokenRole != user.Role && issuedAt.After(roleUpdatedAt)
+	emailChanged := email != "" && (user.Email == nil || *user.Email != email)
+	seenRecently := user.LastSeenAt != nil && time.Since(*user.LastSeenAt) < lastSeenResolution
+	if !roleChanged && !emailChanged && seenRecently {
+		return user, nil
+	}
+
+	query = fmt.Sprintf(`
+		WITH updated AS (
+			UPDATE user_accounts u SET
+				email = COALESCE($2, u.email),
+				role = CASE WHEN u.role_source = 'token' AND $4 > u.role_updated_at THEN $3 ELSE u.role END,
+				role_updated_at = CASE WHEN u.role_source = 'token' AND $4 > u.role_updated_at THEN $4 ELSE u.role_updated_at END,
+				last_seen_at = NOW()
+			WHERE u.id = $1::uuid
+			RETURNING u.*
+		)
+		SELECT %s FROM updated u;
+	`, userColumns)
+	user, err = scanUser(DB.QueryRow(ctx, query, userID, emailArg, tokenRole, issuedAt))
+	if err != nil {
+		return nil, fmt.Errorf("gagal sinkronisasi akun pengguna: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers mengambil daftar akun pengguna dengan filter dan paginasi offset, beserta total hasil.
+func ListUsers(ctx context.Context, params UserListParams) ([]models.User, int64, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if params.Query != "" {
+		args = append(args, "%"+escapeLike(strings.ToLower(params.Query))+"%")
+		conditions = append(conditions, fmt.Sprintf("(lower(u.email) LIKE $%d OR u.id::text LIKE $%d)", len(args), len(args)))
+	}
+	if params.Role != "" {
+		addCondition("u.role = $%d", params.Role)
+	}
+	switch params.Status {
+	case models.UserStatusActive:
+		conditions = append(conditions, "(u.status = 'active' OR (u.status = 'suspended' AND u.suspended_until <= NOW()))")
+	case models.UserStatusSuspended:
+		conditions = append(conditions, "(u.status = 'suspended' AND u.suspended_until > NOW())")
+	case models.UserStatusBanned:
+		conditions = append(conditions, "u.status = 'banned'")
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := DB.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) FROM user_accounts u %s;", whereClause), args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung total pengguna: %w", err)
+	}
+
+	args = append(args, params.Limit, params.Offset)
+	query := fmt.Sprintf(`
+		SELECT %s, %s FROM user_accounts u
+		%s
+		ORDER BY u.created_at DESC, u.id
+		LIMIT $%d OFFSET $%d;
+	`, userColumns, userComicCountColumn, whereClause, len(args)-1, len(args))
+
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal query ListUsers: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUserWithComicCount(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal scan baris pengguna: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterasi baris pengguna: %w", err)
+	}
+	return users, total, nil
+}
+
+// GetUserByID mengambil satu akun pengguna. Mengembalikan nil jika tidak ditemukan.
+func GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	query := fmt.Sprintf("SELECT %s, %s FROM user_accounts u WHERE u.id = $1::uuid;", userColumns, userComicCountColumn)
+	user, err := scanUserWithComicCount(DB.QueryRow(ctx, query, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal query GetUserByID: %w", err)
+	}
+	return user, nil
+}
+
+// UpdateUserRole mengubah peran pengguna. Peran yang diubah admin tidak lagi ditimpa oleh peran dari token
+// (lihat SyncUserAccount). Mengembalikan nil jika pengguna tidak ditemukan.
+func UpdateUserRole(ctx context.Context, userID, role string) (*models.User, error) {
+	tag, err := DB.Exec(ctx, `
+		UPDATE user_accounts SET role = $2, role_source = 'admin', role_updated_at = NOW(), updated_at = NOW()
+		WHERE id = $1::uuid;
+	`, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengubah peran pengguna: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return GetUserByID(ctx, userID)
+}
+
+// UpdateUserStatus mengubah status akun pengguna (active, suspended, atau banned).
+// suspendedUntil hanya dipakai untuk status suspended. Mengembalikan nil jika pengguna tidak ditemukan.
+func UpdateUserStatus(ctx context.Context, userID, status string, suspendedUntil *time.Time, reason *string) (*models.User, error) {
+	if status != models.UserStatusSuspended {
+		suspendedUntil = nil
+	}
+	if status == models.UserStatusActive {
+		reason = nil
+	}
+	tag, err := DB.Exec(ctx, `
+		UPDATE user_accounts SET status = $2, suspended_until = $3, status_reason = $4, updated_at = NOW()
+		WHERE id = $1::uuid;
+	`, userID, status, suspendedUntil, reason)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengubah status pengguna: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return GetUserByID(ctx, userID)
+}
+
+// escapeLike meng-escape karakter wildcard LIKE (%, _, dan \) pada input pengguna.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// trendingPeriodHours memetakan periode trending ke panjang jendelanya dalam jam (jumlah counter per jam).
+var trendingPeriodHours = map[string]int{
+	models.TrendingDaily:   24,
+	models.TrendingWeekly:  7 * 24,
+	models.TrendingMonthly: 30 * 24,
+}
+
+// viewBucketRetention adalah umur maksimum counter per jam; sama dengan periode trending terpanjang.
+const viewBucketRetention = 30 * 24 * time.Hour
+
+// trendingLockKey adalah kunci advisory lock agar hanya satu instance server yang menghitung ulang trending
+// pada satu waktu.
+const trendingLockKey = "comic_trending"
+
+// RecordChapterView menghitung satu view chapter dari viewerKey, kecuali pengunjung yang sama sudah dihitung
+// untuk chapter ini dalam jendela window terakhir. View yang dihitung menambah counter per jam komiknya dan
+// comics.view_count di perintah yang sama. Chapter yang tidak ada atau belum rilis untuk publik diabaikan.
+// Mengembalikan true jika view dihitung.
+func RecordChapterView(ctx context.Context, chapterID int64, viewerKey string, window time.Duration) (bool, error) {
+	query := fmt.Sprintf(`
+		WITH valid AS (
+			SELECT ch.id, ch.comic_id FROM chapters ch
+			JOIN comics c ON c.id = ch.comic_id
+			WHERE ch.id = $1 AND %s AND c.deleted_at IS NULL AND c.status = 'published'
+		), mark AS (
+			INSERT INTO chapter_view_marks AS m (chapter_id, viewer_key)
+			SELECT id, $2 FROM valid
+			ON CONFLICT (chapter_id, viewer_key) DO UPDATE SET counted_at = NOW()
+				WHERE m.counted_at <= NOW() - make_interval(secs => $3)
+			RETURNING chapter_id
+		), bucket AS (
+			INSERT INTO comic_view_buckets AS b (comic_id, bucket_start, views)
+			SELECT comic_id, date_trunc('hour', NOW(), 'UTC'), 1 FROM valid WHERE EXISTS (SELECT 1 FROM mark)
+			ON CONFLICT (comic_id, bucket_start) DO UPDATE SET views = b.views + 1
+		)
+		UPDATE comics SET view_count = view_count + 1
+		WHERE id = (SELECT comic_id FROM valid) AND EXISTS (SELECT 1 FROM mark);
+	`, releasedChapterCondition)
+
+	tag, err := DB.Exec(ctx, query, chapterID, viewerKey, window.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("gagal mencatat view chapter: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RefreshTrending menghitung ulang peringkat semua periode trending dari counter per jam dan menyimpan
+// paling banyak size komik per periode. Jika instance lain sedang menghitung ulang, pemanggilan ini dilewati
+// dan mengembalikan false.
+func RefreshTrending(ctx context.Context, size int) (bool, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi RefreshTrending: %w", err)
+	}
+	defer tx.Rollback(ctx) // Aman dipanggil setelah Commit
+
+	var locked bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock(hashtext($1))", trendingLockKey).Scan(&locked); err != nil {
+		return false, fmt.Errorf("gagal mengambil lock trending: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+
+	for period, hours := range trendingPeriodHours {
+		if _, err := tx.Exec(ctx, "DELETE FROM comic_trending WHERE period = $1", period); err != nil {
+			return false, fmt.Errorf("gagal menghapus trending %s lama: %w", period, err)
+		}
+		// Jendela mencakup jam berjalan ditambah hours-1 jam penuh sebelumnya
+		_, err := tx.Exec(ctx, `
+			INSERT INTO comic_trending (period, comic_id, rank, views, computed_at)
+			SELECT $1, b.comic_id, ROW_NUMBER() OVER (ORDER BY SUM(b.views) DESC, b.comic_id), SUM(b.views), NOW()
+			FROM comic_view_buckets b
+			JOIN comics c ON c.id = b.comic_id
+			WHERE b.bucket_start >= date_trunc('hour', NOW(), 'UTC') - make_interval(hours => $2 - 1)
+				AND c.deleted_at IS NULL AND c.status = 'published'
+			GROUP BY b.comic_id
+			ORDER BY SUM(b.views) DESC, b.comic_id
+			LIMIT $3;
+		`, period, hours, size)
+		if err != nil {
+			return false, fmt.Errorf("gagal menghitung trending %s: %w", period, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit transaksi RefreshTrending: %w", err)
+	}
+	return true, nil
+}
+
+// PruneViewData menghapus penanda deduplikasi yang sudah lewat dari jendela dedupWindow dan counter per jam
+// yang lebih tua dari periode trending terpanjang. Mengembalikan jumlah baris yang dihapus dari masing-masing tabel.
+func PruneViewData(ctx context.Context, dedupWindow time.Duration) (marks int64, buckets int64, err error) {
+	tag, err := DB.Exec(ctx, "DELETE FROM chapter_view_marks WHERE counted_at < NOW() - make_interval(secs => $1)", dedupWindow.Seconds())
+	if err != nil {
+		return 0, 0, fmt.Errorf("gagal menghapus penanda view lama: %w", err)
+	}
+	marks = tag.RowsAffected()
+
+	tag, err = DB.Exec(ctx, "DELETE FROM comic_view_buckets WHERE bucket_start < date_trunc('hour', NOW(), 'UTC') - make_interval(secs => $1)", viewBucketRetention.Seconds())
+	if err != nil {
+		return marks, 0, fmt.Errorf("gagal menghapus counter view lama: %w", err)
+	}
+	return marks, tag.RowsAffected(), nil
+}
+
+// ListTrendingComics mengambil peringkat komik yang dipublikasikan untuk sebuah periode, beserta waktu
+// perhitungan terakhirnya. Periode all_time dibaca langsung dari comics.view_count sehingga computedAt bernilai nil.
+// Rank dihitung ulang dari urutan hasil, karena komik yang disembunyikan setelah perhitungan tidak ditampilkan.
+func ListTrendingComics(ctx context.Context, period string, limit int) (comics []models.TrendingComic, computedAt *time.Time, err error) {
+	comicColumns := fmt.Sprintf(`
+		c.id, c.title, c.description, c.author_name,
+		%s AS genres, %s AS tags,
+		c.cover_image_url, c.view_count, c.rating_average, c.rating_count,
+		c.status, c.published_at, c.created_at, c.updated_at`, comicGenresColumn("c"), comicTagsColumn("c"))
+
+	var (
+		query string
+		args  []interface{}
+	)
+	switch {
+	case period == models.TrendingAllTime:
+		query = fmt.Sprintf(`
+			SELECT %s, c.view_count, NULL::timestamptz
+			FROM comics c
+			WHERE c.deleted_at IS NULL AND c.status = 'published'
+			ORDER BY c.view_count DESC, c.id DESC
+			LIMIT $1;
+		`, comicColumns)
+		args = []interface{}{limit}
+	case trendingPeriodHours[period] > 0:
+		query = fmt.Sprintf(`
+			SELECT %s, t.views, t.computed_at
+			FROM comic_trending t
+			JOIN comics c ON c.id = t.comic_id
+			WHERE t.period = $1 AND c.deleted_at IS NULL AND c.status = 'published'
+			ORDER BY t.rank
+			LIMIT $2;
+		`, comicColumns)
+		args = []interface{}{period, limit}
+	default:
+		return nil, nil, fmt.Errorf("periode trending tidak dikenal: %s", period)
+	}
+
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal query ListTrendingComics: %w", err)
+	}
+	defer rows.Close()
+
+	comics = []models.TrendingComic{}
+	for rows.Next() {
+		var tc models.TrendingComic
+		err := rows.Scan(
+			&tc.ID,
+			&tc.Title,
+			&tc.Description,
+			&tc.AuthorName,
+			&tc.Genres,
+			&tc.Tags,
+			&tc.CoverImageURL,
+			&tc.ViewCount,
+			&tc.RatingAverage,
+			&tc.RatingCount,
+			&tc.Status,
+			&tc.PublishedAt,
+			&tc.CreatedAt,
+			&tc.UpdatedAt,
+			&tc.PeriodViews,
+			&computedAt,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gagal scan baris trending: %w", err)
+		}
+		tc.Rank = len(comics) + 1
+		comics = append(comics, tc)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterasi baris trending: %w", err)
+	}
+	return comics, computedAt, nil
+}
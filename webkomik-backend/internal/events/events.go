@@ -0,0 +1,146 @@
+// Package events berisi event domain dan bus sederhana untuk menyebarkannya ke subscriber di dalam proses,
+// misalnya pengumuman chapter baru yang dipakai oleh notifikasi.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// Nama event yang dikenal aplikasi.
+const (
+	ChapterPublishedEvent = "chapter.published"
+	CommentRepliedEvent   = "comment.replied"
+	ContentModeratedEvent = "content.moderated"
+)
+
+// Event adalah peristiwa domain yang disebarkan lewat Bus.
+type Event interface {
+	EventName() string
+}
+
+// ChapterPublished dikirim saat sebuah chapter pertama kali tampil untuk publik,
+// baik karena disetujui peninjau maupun karena jadwal rilisnya tiba.
+type ChapterPublished struct {
+	ComicID       int64     `json:"comic_id"`
+	ComicTitle    string    `json:"comic_title"`
+	ChapterID     int64     `json:"chapter_id"`
+	ChapterNumber float32   `json:"chapter_number"`
+	ChapterTitle  *string   `json:"chapter_title,omitempty"`
+	PublishedAt   time.Time `json:"published_at"`
+	Scheduled     bool      `json:"scheduled"` // true jika dirilis oleh scheduler
+}
+
+// EventName mengembalikan ChapterPublishedEvent.
+func (ChapterPublished) EventName() string { return ChapterPublishedEvent }
+
+// NewChapterPublished membentuk event ChapterPublished dari chapter yang baru rilis.
+func NewChapterPublished(comicTitle string, ch *models.Chapter, scheduled bool) ChapterPublished {
+	event := ChapterPublished{
+		ComicID:       ch.ComicID,
+		ComicTitle:    comicTitle,
+		ChapterID:     ch.ID,
+		ChapterNumber: ch.ChapterNumber,
+		ChapterTitle:  ch.Title,
+		PublishedAt:   time.Now(),
+		Scheduled:     scheduled,
+	}
+	if ch.PublishedAt != nil {
+		event.PublishedAt = *ch.PublishedAt
+	}
+	return event
+}
+
+// CommentReplied dikirim saat pengguna membalas komentar milik pengguna lain.
+type CommentReplied struct {
+	ReplyID      int64  `json:"reply_id"`
+	ParentID     int64  `json:"parent_id"`
+	ParentUserID string `json:"parent_user_id"` // Penulis komentar yang dibalas
+	ReplierID    string `json:"replier_id"`
+	ComicID      int64  `json:"comic_id"`
+	ChapterID    int64  `json:"chapter_id"`
+	Body         string `json:"body,omitempty"` // Kosong jika balasan ditandai spoiler
+	IsSpoiler    bool   `json:"is_spoiler"`
+}
+
+// EventName mengembalikan CommentRepliedEvent.
+func (CommentReplied) EventName() string { return CommentRepliedEvent }
+
+// Jenis konten dan aksi pada event ContentModerated.
+const (
+	ModeratedComment = "comment"
+	ModeratedReview  = "review"
+
+	ModerationHidden   = "hidden"
+	ModerationRestored = "restored"
+	ModerationDeleted  = "deleted"
+)
+
+// ContentModerated dikirim saat moderator menyembunyikan, memulihkan, atau menghapus konten milik pengguna.
+type ContentModerated struct {
+	OwnerID     string  `json:"owner_id"`     // Pemilik konten yang dimoderasi
+	ContentType string  `json:"content_type"` // Salah satu Moderated*
+	ContentID   int64   `json:"content_id"`
+	Action      string  `json:"action"` // Salah satu Moderation*
+	Reason      *string `json:"reason,omitempty"`
+	ComicID     int64   `json:"comic_id"`
+	ChapterID   *int64  `json:"chapter_id,omitempty"` // Diisi untuk komentar chapter
+}
+
+// EventName mengembalikan ContentModeratedEvent.
+func (ContentModerated) EventName() string { return ContentModeratedEvent }
+
+// Handler memproses satu event. Handler dipanggil di goroutine milik Bus dan tidak boleh memblokir lama;
+// pekerjaan berat sebaiknya diteruskan ke antrean milik subscriber sendiri.
+type Handler func(ctx context.Context, event Event)
+
+// Bus menyebarkan event ke handler yang berlangganan berdasarkan nama event.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus membuat Bus kosong.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe mendaftarkan handler untuk event bernama name.
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], h)
+}
+
+// Publish mengirim event ke semua handler secara berurutan di goroutine terpisah sehingga pemanggil
+// (misalnya handler HTTP) tidak ikut menunggu. Context pemanggil tidak ikut dibatalkan bersama request.
+// Panic pada handler dicatat dan tidak menghentikan handler lain.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers[event.EventName()]...)
+	b.mu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		for _, h := range handlers {
+			dispatch(ctx, h, event)
+		}
+	}()
+}
+
+// dispatch memanggil satu handler dan menangkap panic-nya.
+func dispatch(ctx context.Context, h Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Peringatan: Handler event %s panic: %v\n", event.EventName(), r)
+		}
+	}()
+	h(ctx, event)
+}
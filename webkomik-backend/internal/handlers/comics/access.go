@@ -0,0 +1,165 @@
+package comics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/middleware"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID mengambil userID yang di-set oleh AuthMiddleware.
+// Jika tidak ada, response error sudah ditulis dan fungsi mengembalikan false.
+func currentUserID(c *gin.Context) (string, bool) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "UserID tidak ditemukan di context"})
+		return "", false
+	}
+	userID, ok := userIDVal.(string)
+	if !ok || userID == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Format UserID tidak valid"})
+		return "", false
+	}
+	return userID, true
+}
+
+// loadComic mengambil komik dari parameter URL ":id" tanpa memeriksa hak akses.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadComic(c *gin.Context) (*models.Comic, bool) {
+	comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+		return nil, false
+	}
+
+	comic, err := database.GetComicByID(c.Request.Context(), comicID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil detail komik"})
+		return nil, false
+	}
+	if comic == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
+		return nil, false
+	}
+	return comic, true
+}
+
+// loadManagedComic mengambil komik dari parameter URL ":id" dan memastikan user saat ini diizinkan oleh policy,
+// misalnya anggota tim hanya boleh mengelola komik tempat ia tergabung sedangkan admin boleh mengelola semua komik.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadManagedComic(c *gin.Context, policy authz.OwnershipPolicy) (*models.Comic, string, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, "", false
+	}
+	comic, ok := loadComic(c)
+	if !ok {
+		return nil, "", false
+	}
+
+	allowed, ok := authorizeComic(c, policy, comic)
+	if !ok {
+		return nil, "", false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk mengelola komik ini"})
+		return nil, "", false
+	}
+	return comic, userID, true
+}
+
+// authorizeComic memuat anggota tim komik lalu memeriksa policy untuk pengguna saat ini.
+// Jika ok bernilai false, response error sudah ditulis.
+func authorizeComic(c *gin.Context, policy authz.OwnershipPolicy, comic *models.Comic) (allowed bool, ok bool) {
+	members, err := database.GetComicMembers(c.Request.Context(), comic.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggota tim komik"})
+		return false, false
+	}
+	comic.Members = members
+	return middleware.CanAccessOwned(c, policy, comic)
+}
+
+// canPreviewComic memeriksa apakah pengguna saat ini boleh melihat konten komik yang belum dipublikasikan,
+// yaitu anggota tim komik atau peninjau. Pengunjung anonim selalu mendapat false.
+// Jika ok bernilai false, response error sudah ditulis.
+func canPreviewComic(c *gin.Context, comic *models.Comic) (allowed bool, ok bool) {
+	if c.GetString("userID") == "" {
+		return false, true
+	}
+	return authorizeComic(c, authz.ComicPreviewPolicy, comic)
+}
+
+// loadVisibleComic mengambil komik dari parameter URL ":id" dan memastikan komik boleh dilihat pengguna saat ini.
+// Komik yang belum dipublikasikan dianggap tidak ditemukan kecuali untuk anggota tim dan peninjau.
+// preview menandakan pengguna boleh melihat konten yang belum dipublikasikan.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadVisibleComic(c *gin.Context) (comic *models.Comic, preview bool, ok bool) {
+	comic, ok = loadComic(c)
+	if !ok {
+		return nil, false, false
+	}
+	if preview, ok = canPreviewComic(c, comic); !ok {
+		return nil, false, false
+	}
+	if comic.Status != models.PublicationPublished && !preview {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
+		return nil, false, false
+	}
+	return comic, preview, true
+}
+
+// loadVisibleChapterByID mengambil chapter berdasarkan ID beserta komiknya dan memastikan keduanya
+// boleh dilihat pengguna saat ini, seperti loadVisibleChapter.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadVisibleChapterByID(c *gin.Context, chapterID int64) (*models.Chapter, *models.Comic, bool) {
+	chapter, err := database.GetChapterByID(c.Request.Context(), chapterID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter"})
+		return nil, nil, false
+	}
+	if chapter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter tidak ditemukan"})
+		return nil, nil, false
+	}
+	comic, _, ok := loadVisibleChapter(c, chapter)
+	if !ok {
+		return nil, nil, false
+	}
+	return chapter, comic, true
+}
+
+// loadVisibleChapter mengambil komik dari sebuah chapter dan memastikan keduanya boleh dilihat pengguna saat ini.
+// Chapter atau komik yang belum dipublikasikan, termasuk chapter terjadwal yang belum tiba waktunya,
+// dianggap tidak ditemukan kecuali untuk anggota tim dan peninjau.
+// preview menandakan pengguna boleh melihat konten yang belum dipublikasikan.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadVisibleChapter(c *gin.Context, chapter *models.Chapter) (comic *models.Comic, preview bool, ok bool) {
+	comic, err := database.GetComicByID(c.Request.Context(), chapter.ComicID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil detail komik"})
+		return nil, false, false
+	}
+	if comic == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
+		return nil, false, false
+	}
+	if preview, ok = canPreviewComic(c, comic); !ok {
+		return nil, false, false
+	}
+	published := comic.Status == models.PublicationPublished && chapter.IsReleased(time.Now())
+	if !published && !preview {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter tidak ditemukan"})
+		return nil, false, false
+	}
+	return comic, preview, true
+}
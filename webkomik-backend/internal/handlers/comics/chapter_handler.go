@@ -0,0 +1,304 @@
+package comics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// parseChapterNumber mengambil nomor chapter dari parameter URL ":number" (contoh: 10 atau 10.5).
+func parseChapterNumber(c *gin.Context) (float32, bool) {
+	number, err := strconv.ParseFloat(c.Param("number"), 32)
+	if err != nil || number < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nomor chapter tidak valid"})
+		return 0, false
+	}
+	return float32(number), true
+}
+
+// loadManagedChapter mengambil chapter dari parameter URL ":number" milik komik yang boleh dikelola user saat ini.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadManagedChapter(c *gin.Context) (*models.Comic, *models.Chapter, bool) {
+	comic, _, ok := loadManagedComic(c, authz.ChapterPublishPolicy)
+	if !ok {
+		return nil, nil, false
+	}
+	chapter, ok := loadComicChapter(c, comic)
+	if !ok {
+		return nil, nil, false
+	}
+	return comic, chapter, true
+}
+
+// loadComicChapter mengambil chapter dari parameter URL ":number" pada komik tertentu tanpa memeriksa hak akses.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadComicChapter(c *gin.Context, comic *models.Comic) (*models.Chapter, bool) {
+	chapterNumber, ok := parseChapterNumber(c)
+	if !ok {
+		return nil, false
+	}
+
+	chapter, err := database.GetChapterByNumber(c.Request.Context(), comic.ID, chapterNumber)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter"})
+		return nil, false
+	}
+	if chapter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter tidak ditemukan"})
+		return nil, false
+	}
+	return chapter, true
+}
+
+// validPublishAt memastikan jadwal rilis berada di masa depan. Jika tidak, response error sudah ditulis
+// dan fungsi mengembalikan false.
+func validPublishAt(c *gin.Context, publishAt *time.Time) bool {
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": "publish_at harus berada di masa depan"})
+		return false
+	}
+	return true
+}
+
+// CreateChapterHandler menangani pembuatan chapter baru untuk sebuah komik.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func CreateChapterHandler(c *gin.Context) {
+	comic, userID, ok := loadManagedComic(c, authz.ChapterPublishPolicy)
+	if !ok {
+		return
+	}
+
+	var input CreateChapterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	if !validPublishAt(c, input.PublishAt) {
+		return
+	}
+
+	chapterData := models.Chapter{
+		ChapterNumber: *input.ChapterNumber,
+		Title:         input.Title,
+		PublishAt:     input.PublishAt,
+	}
+
+	createdChapter, err := database.CreateChapter(c.Request.Context(), comic.ID, chapterData)
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateChapterNumber) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Nomor chapter sudah digunakan untuk komik ini"})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat membuat chapter untuk komik ID %d: %v\nInput: %+v\nUserID: %s\n", comic.ID, err, input, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan chapter baru"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": createdChapter})
+}
+
+// UpdateChapterHandler menangani pembaruan chapter berdasarkan nomor chapternya.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func UpdateChapterHandler(c *gin.Context) {
+	comic, chapter, ok := loadManagedChapter(c)
+	if !ok {
+		return
+	}
+
+	var input UpdateChapterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	// Hanya masukkan field yang ada di request
+	updates := make(map[string]interface{})
+	if input.ChapterNumber != nil {
+		updates["chapter_number"] = *input.ChapterNumber
+	}
+	if input.Title != nil {
+		updates["title"] = input.Title
+	}
+	if input.PublishAt != nil || input.ClearPublishAt {
+		// Jadwal chapter yang sudah rilis tidak bisa diubah agar chapter tidak tiba-tiba hilang dari publik
+		if chapter.PublishedAt != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Chapter sudah rilis, jadwal rilis tidak bisa diubah"})
+			return
+		}
+		if !validPublishAt(c, input.PublishAt) {
+			return
+		}
+		if input.PublishAt != nil {
+			updates["publish_at"] = input.PublishAt
+		}
+	}
+
+	if len(updates) == 0 && !input.ClearPublishAt {
+		c.JSON(http.StatusOK, gin.H{"data": chapter, "message": "Tidak ada perubahan yang dilakukan"})
+		return
+	}
+
+	updatedChapter := chapter
+	if len(updates) > 0 {
+		var err error
+		updatedChapter, err = database.UpdateChapter(c.Request.Context(), chapter.ID, updates)
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateChapterNumber) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Nomor chapter sudah digunakan untuk komik ini"})
+				return
+			}
+			c.Error(err)
+			log.Printf("Error saat memperbarui chapter ID %d (komik ID %d): %v\nInput: %+v\n", chapter.ID, comic.ID, err, input)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui chapter"})
+			return
+		}
+	}
+
+	if input.ClearPublishAt {
+		// Chapter yang sudah disetujui langsung rilis; notifikasinya dikirim scheduler dari outbox rilis
+		if _, err := database.ClearChapterSchedule(c.Request.Context(), chapter.ID); err != nil {
+			if errors.Is(err, database.ErrInvalidTransition) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Chapter sudah rilis, jadwal rilis tidak bisa diubah"})
+				return
+			}
+			c.Error(err)
+			log.Printf("Error saat menghapus jadwal rilis chapter ID %d (komik ID %d): %v\n", chapter.ID, comic.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus jadwal rilis chapter"})
+			return
+		}
+		var err error
+		updatedChapter, err = database.GetChapterByID(c.Request.Context(), chapter.ID)
+		if err != nil || updatedChapter == nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter yang telah diperbarui"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updatedChapter})
+}
+
+// DeleteChapterHandler menangani penghapusan chapter beserta seluruh halaman dan file gambarnya.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func DeleteChapterHandler(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comic, chapter, ok := loadManagedChapter(c)
+		if !ok {
+			return
+		}
+
+		imageURLs, err := database.DeleteChapter(c.Request.Context(), chapter.ID)
+		if err != nil {
+			c.Error(err)
+			log.Printf("Error saat menghapus chapter ID %d (komik ID %d): %v\n", chapter.ID, comic.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus chapter"})
+			return
+		}
+		deletePageImages(store, imageURLs)
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// GetChapterHandler menangani permintaan satu chapter beserta seluruh halamannya.
+// Chapter yang belum dipublikasikan hanya tampil untuk anggota tim komik dan peninjau.
+func GetChapterHandler(c *gin.Context) {
+	chapterID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID chapter tidak valid"})
+		return
+	}
+
+	chapter, err := database.GetChapterByID(c.Request.Context(), chapterID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter"})
+		return
+	}
+	if chapter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter tidak ditemukan"})
+		return
+	}
+	if _, _, ok := loadVisibleChapter(c, chapter); !ok {
+		return
+	}
+
+	pages, err := database.GetPagesByChapterID(c.Request.Context(), chapter.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil halaman chapter"})
+		return
+	}
+	if pages == nil {
+		pages = []models.Page{}
+	}
+	chapter.Pages = pages
+	chapter.PageCount = len(pages)
+
+	c.JSON(http.StatusOK, gin.H{"data": chapter})
+}
+
+// ReadChapterHandler menangani pembacaan satu chapter berdasarkan nomor chapternya:
+// chapter beserta halaman yang terurut, serta referensi chapter sebelumnya dan berikutnya.
+// Chapter yang belum dipublikasikan hanya tampil (dan masuk navigasi) untuk anggota tim komik dan peninjau.
+func ReadChapterHandler(c *gin.Context) {
+	comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+		return
+	}
+	chapterNumber, ok := parseChapterNumber(c)
+	if !ok {
+		return
+	}
+
+	chapter, err := database.GetChapterByNumber(c.Request.Context(), comicID, chapterNumber)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter"})
+		return
+	}
+	if chapter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter tidak ditemukan"})
+		return
+	}
+	_, preview, ok := loadVisibleChapter(c, chapter)
+	if !ok {
+		return
+	}
+
+	pages, err := database.GetPagesByChapterID(c.Request.Context(), chapter.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil halaman chapter"})
+		return
+	}
+	if pages == nil {
+		pages = []models.Page{}
+	}
+	chapter.Pages = pages
+	chapter.PageCount = len(pages)
+
+	prev, next, err := database.GetAdjacentChapters(c.Request.Context(), chapter, !preview)
+	if err != nil {
+		// Tidak fatal, pembaca tetap bisa membaca chapter tanpa navigasi
+		c.Error(err)
+		log.Printf("Peringatan: Gagal mengambil navigasi untuk chapter ID %d: %v\n", chapter.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         chapter,
+		"prev_chapter": prev,
+		"next_chapter": next,
+	})
+}
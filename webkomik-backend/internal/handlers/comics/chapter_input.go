@@ -0,0 +1,29 @@
+package comics
+
+import "time"
+
+// CreateChapterInput adalah struct untuk validasi input saat membuat chapter baru.
+// chapter_number berupa float agar chapter ekstra seperti 10.5 bisa dibuat.
+// publish_at (RFC3339) menjadwalkan rilis chapter setelah disetujui; kosong berarti rilis saat disetujui.
+type CreateChapterInput struct {
+	ChapterNumber *float32   `json:"chapter_number" binding:"required,gte=0"`
+	Title         *string    `json:"title" binding:"omitempty,max=255"` // Opsional
+	PublishAt     *time.Time `json:"publish_at"`                        // Opsional
+}
+
+// UpdateChapterInput adalah struct untuk validasi input saat memperbarui chapter.
+// Semua field bersifat opsional karena ini adalah operasi update partial.
+// clear_publish_at menghapus jadwal rilis; chapter yang sudah disetujui langsung rilis.
+type UpdateChapterInput struct {
+	ChapterNumber  *float32   `json:"chapter_number" binding:"omitempty,gte=0"`
+	Title          *string    `json:"title" binding:"omitempty,max=255"`
+	PublishAt      *time.Time `json:"publish_at"`                                         // Hanya bisa diubah sebelum chapter rilis
+	ClearPublishAt bool       `json:"clear_publish_at" binding:"excluded_with=PublishAt"` // Hanya bisa sebelum chapter rilis
+}
+
+// ImportChapterInput adalah field form multipart yang menyertai upload arsip CBZ/ZIP.
+type ImportChapterInput struct {
+	ChapterNumber *float32   `form:"chapter_number" binding:"required,gte=0"`
+	Title         *string    `form:"title" binding:"omitempty,max=255"`
+	PublishAt     *time.Time `form:"publish_at"` // Opsional, RFC3339
+}
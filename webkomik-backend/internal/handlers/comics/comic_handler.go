@@ -1,84 +1,226 @@
 package comics
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
 	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
-	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/middleware" // Import middleware for role checks
 	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models" // Import models
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/slug"
 	"github.com/gin-gonic/gin"
 )
 
-// GetAllComicsHandler menangani permintaan untuk mendapatkan semua komik.
+// Batas default dan maksimum jumlah komik per halaman listing.
+const (
+	defaultComicPageLimit = 20
+	maxComicPageLimit     = 100
+)
+
+// GetAllComicsHandler menangani permintaan daftar komik dengan filter, sort, dan paginasi.
+// Mendukung paginasi offset (limit/offset) maupun keyset (cursor dari next_cursor/prev_cursor).
+// Hanya komik berstatus published yang ditampilkan.
 func GetAllComicsHandler(c *gin.Context) {
-	comicsList, err := database.GetAllComics(c.Request.Context()) // Menggunakan context dari request Gin
+	respondComicList(c, func(params *database.ComicListParams) {
+		params.Statuses = []string{models.PublicationPublished}
+	})
+}
+
+// respondComicList menjalankan listing komik berdasarkan query string dan menulis response berisi data dan paginasi.
+// configure dipanggil setelah parameter dibentuk dari query string, misalnya untuk membatasi status
+// atau menampilkan komik di sampah.
+func respondComicList(c *gin.Context, configure func(params *database.ComicListParams)) {
+	var query ListComicsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+
+	params, err := buildComicListParams(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+
+	configure(&params)
+
+	result, err := database.ListComics(c.Request.Context(), params) // Menggunakan context dari request Gin
 	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor tidak valid untuk sort/order yang diminta"})
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data komik"})
 		return
 	}
 
-	if comicsList == nil {
-		comicsList = []models.Comic{} // Gunakan models.Comic
+	c.JSON(http.StatusOK, gin.H{
+		"data": result.Comics,
+		"pagination": gin.H{
+			"total":       result.Total,
+			"limit":       params.Limit,
+			"offset":      params.Offset,
+			"has_more":    result.HasMore,
+			"next_cursor": result.NextCursor,
+			"prev_cursor": result.PrevCursor,
+		},
+	})
+}
+
+// buildComicListParams mengubah query string yang sudah di-bind menjadi parameter database.
+func buildComicListParams(query ListComicsQuery) (database.ComicListParams, error) {
+	params := database.ComicListParams{
+		Limit:         query.Limit,
+		Offset:        query.Offset,
+		Cursor:        query.Cursor,
+		GenreMatchAll: query.GenreMatch == "all",
+		TagMatchAll:   query.TagMatch == "all",
+		Sort:          query.Sort,
+	}
+	if params.Limit == 0 {
+		params.Limit = defaultComicPageLimit
+	}
+	if params.Limit > maxComicPageLimit {
+		params.Limit = maxComicPageLimit
+	}
+	if params.Cursor != "" {
+		params.Offset = 0 // Offset tidak berlaku untuk keyset pagination
+	}
+	if params.Sort == "" {
+		params.Sort = database.ComicSortCreatedAt
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": comicsList})
+	// Judul diurutkan A-Z secara default, sort lain dari yang terbaru/terbesar
+	params.Desc = params.Sort != database.ComicSortTitle
+	if query.Order != "" {
+		params.Desc = query.Order == "desc"
+	}
+
+	if author := strings.TrimSpace(query.AuthorName); author != "" {
+		params.AuthorName = &author
+	}
+	if query.Status != "" {
+		params.Statuses = []string{query.Status}
+	}
+
+	var err error
+	if params.GenreIDs, err = parseIDList("genre_ids", query.GenreIDs); err != nil {
+		return params, err
+	}
+	if query.GenreID != nil && !slices.Contains(params.GenreIDs, *query.GenreID) {
+		params.GenreIDs = append(params.GenreIDs, *query.GenreID)
+	}
+	for _, tag := range strings.Split(query.Tags, ",") {
+		if s := slug.Make(tag); s != "" && !slices.Contains(params.TagSlugs, s) {
+			params.TagSlugs = append(params.TagSlugs, s)
+		}
+	}
+
+	if params.CreatedFrom, err = parseDateParam("created_from", query.CreatedFrom, false); err != nil {
+		return params, err
+	}
+	if params.CreatedTo, err = parseDateParam("created_to", query.CreatedTo, true); err != nil {
+		return params, err
+	}
+	if params.UpdatedFrom, err = parseDateParam("updated_from", query.UpdatedFrom, false); err != nil {
+		return params, err
+	}
+	if params.UpdatedTo, err = parseDateParam("updated_to", query.UpdatedTo, true); err != nil {
+		return params, err
+	}
+	return params, nil
 }
 
-// GetComicDetailHandler menangani permintaan untuk mendapatkan detail satu komik.
-func GetComicDetailHandler(c *gin.Context) {
-	comicIDStr := c.Param("id") // Mengambil "id" dari URL path, contoh: /comics/123
-	comicID, err := strconv.ParseInt(comicIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
-		return
+// parseIDList mengurai daftar ID positif yang dipisah koma, contoh: "1,4,7". Duplikat diabaikan.
+func parseIDList(name, value string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%s harus berisi daftar ID yang dipisah koma", name)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
 	}
+	return ids, nil
+}
 
-	// 1. Ambil detail komik dasar
-	comic, err := database.GetComicByID(c.Request.Context(), comicID)
+// parseDateParam mengurai tanggal format YYYY-MM-DD atau RFC3339.
+// Jika endOfRange bernilai true dan format yang dipakai YYYY-MM-DD, hasilnya digeser ke awal hari berikutnya
+// sehingga batas akhir bersifat inklusif terhadap tanggal tersebut.
+func parseDateParam(name, value string, endOfRange bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
 	if err != nil {
-		c.Error(err) // Log error server
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil detail komik"})
-		return
+		return nil, fmt.Errorf("%s harus berformat YYYY-MM-DD atau RFC3339", name)
 	}
-	if comic == nil { // Komik tidak ditemukan (GetComicByID mengembalikan nil, nil)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
-		return
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
 	}
+	return &t, nil
+}
 
-	// 2. Ambil chapters untuk komik ini
-	chapters, err := database.GetChaptersByComicID(c.Request.Context(), comicID)
+// GetComicDetailHandler menangani permintaan untuk mendapatkan detail satu komik.
+// Komik dan chapter yang belum dipublikasikan hanya tampil untuk anggota tim komik dan peninjau.
+func GetComicDetailHandler(c *gin.Context) {
+	// 1. Ambil detail komik dasar dan pastikan boleh dilihat
+	comic, preview, ok := loadVisibleComic(c)
+	if !ok {
+		return
+	}
+	// 2. Ambil ringkasan chapter (tanpa halaman) dalam satu query
+	chapters, err := database.GetChaptersByComicID(c.Request.Context(), comic.ID, !preview)
 	if err != nil {
 		c.Error(err)
-		// Mungkin tidak fatal jika chapter gagal diambil, tergantung kebutuhan
-		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter komik"})
-		// return
-		log.Printf("Peringatan: Gagal mengambil chapters untuk comic ID %d: %v\n", comicID, err)
-		// Tetap lanjutkan dengan chapters kosong jika ada error, atau Anda bisa memilih untuk gagal total.
-		comic.Chapters = []models.Chapter{}
-	} else {
-		// 3. Untuk setiap chapter, ambil halamannya (pages)
-		for i, chapter := range chapters {
-			pages, err := database.GetPagesByChapterID(c.Request.Context(), chapter.ID)
-			if err != nil {
-				c.Error(err)
-				log.Printf("Peringatan: Gagal mengambil pages untuk chapter ID %d: %v\n", chapter.ID, err)
-				// chapters[i].Pages tetap nil atau []models.Page{} (default)
-				chapters[i].Pages = []models.Page{} // Pastikan array kosong jika gagal
-			} else {
-				chapters[i].Pages = pages
-			}
+		// Tidak fatal, detail komik tetap dikembalikan dengan daftar chapter kosong
+		log.Printf("Peringatan: Gagal mengambil chapters untuk comic ID %d: %v\n", comic.ID, err)
+		chapters = []models.Chapter{}
+	}
+	if chapters == nil {
+		chapters = []models.Chapter{}
+	}
+	comic.Chapters = chapters
+
+	// 3. Sertakan status pustaka dan posisi baca jika pengunjung sedang login
+	if userID := c.GetString("userID"); userID != "" {
+		state, err := database.GetLibraryState(c.Request.Context(), userID, comic.ID)
+		if err != nil {
+			c.Error(err)
+			// Tidak fatal, detail komik tetap dikembalikan tanpa status pustaka
+			log.Printf("Peringatan: Gagal mengambil status pustaka untuk comic ID %d: %v\n", comic.ID, err)
 		}
-		comic.Chapters = chapters
+		comic.Library = state
+
+		progress, err := database.GetReadingProgress(c.Request.Context(), userID, comic.ID)
+		if err != nil {
+			c.Error(err)
+			log.Printf("Peringatan: Gagal mengambil progres baca untuk comic ID %d: %v\n", comic.ID, err)
+		}
+		comic.Progress = progress
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": comic})
 }
 
 // CreateComicHandler menangani pembuatan komik baru.
-// Dapat diakses oleh admin dan creator.
+// Membutuhkan izin comic:create.
 func CreateComicHandler(c *gin.Context) {
 	var input CreateComicInput // Struct untuk binding dan validasi
 
@@ -106,13 +248,17 @@ func CreateComicHandler(c *gin.Context) {
 		Title:         input.Title,
 		Description:   input.Description,
 		AuthorName:    input.AuthorName,
-		GenreID:       input.GenreID,
+		Tags:          input.Tags,
 		CoverImageURL: input.CoverImageURL,
 		// UploadedByAdminID akan diisi oleh fungsi database dari parameter userID
 	}
 
-	createdComic, err := database.CreateComic(c.Request.Context(), comicData, userID)
+	createdComic, err := database.CreateComic(c.Request.Context(), comicData, input.GenreIDs, userID)
 	if err != nil {
+		if errors.Is(err, database.ErrUnknownGenre) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Genre tidak valid", "details": err.Error()})
+			return
+		}
 		c.Error(err)
 		log.Printf("Error saat membuat komik: %v\nInput: %+v\nUserID: %s\n", err, input, userID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan komik baru"})
@@ -123,7 +269,7 @@ func CreateComicHandler(c *gin.Context) {
 }
 
 // UpdateComicHandler menangani pembaruan komik yang sudah ada.
-// Dapat diakses oleh admin dan creator, dengan tambahan pemeriksaan creator hanya bisa update komik mereka sendiri.
+// Membutuhkan izin comic:update:any, atau comic:update:own untuk owner/editor di tim komik.
 func UpdateComicHandler(c *gin.Context) {
 	// 1. Ambil ID komik dari URL
 	comicIDStr := c.Param("id")
@@ -157,13 +303,14 @@ func UpdateComicHandler(c *gin.Context) {
 		return
 	}
 
-	// 4. Pemeriksaan hak akses: admin dapat update semua komik, creator hanya miliknya
-	if !middleware.UserHasRole(c, middleware.RoleAdmin) {
-		// Jika bukan admin, periksa apakah user adalah pemilik komik
-		if existingComic.UploadedByAdminID == nil || *existingComic.UploadedByAdminID != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk memperbarui komik ini"})
-			return
-		}
+	// 4. Pemeriksaan hak akses: comic:update:any untuk semua komik, comic:update:own untuk owner/editor di tim komik
+	allowed, ok := authorizeComic(c, authz.ComicUpdatePolicy, existingComic)
+	if !ok {
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk memperbarui komik ini"})
+		return
 	}
 
 	// 5. Bind dan validasi input
@@ -186,8 +333,11 @@ func UpdateComicHandler(c *gin.Context) {
 	if input.AuthorName != nil {
 		updates["author_name"] = input.AuthorName
 	}
-	if input.GenreID != nil {
-		updates["genre_id"] = input.GenreID
+	if input.GenreIDs != nil {
+		updates["genre_ids"] = *input.GenreIDs
+	}
+	if input.Tags != nil {
+		updates["tags"] = *input.Tags
 	}
 	if input.CoverImageURL != nil {
 		updates["cover_image_url"] = input.CoverImageURL
@@ -203,6 +353,10 @@ func UpdateComicHandler(c *gin.Context) {
 	// 8. Lakukan update di database
 	updatedComic, err := database.UpdateComic(c.Request.Context(), comicID, updates, userID)
 	if err != nil {
+		if errors.Is(err, database.ErrUnknownGenre) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Genre tidak valid", "details": err.Error()})
+			return
+		}
 		c.Error(err)
 		log.Printf("Error saat memperbarui komik ID %d: %v\nInput: %+v\nUserID: %s\n", comicID, err, input, userID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui komik"})
@@ -3,21 +3,53 @@ package comics
 // CreateComicInput adalah struct untuk validasi input saat membuat komik baru.
 // Tag `binding:"required"` digunakan oleh Gin untuk validasi.
 type CreateComicInput struct {
-	Title         string  `json:"title" binding:"required,min=3,max=255"`
-	Description   *string `json:"description"`     // Opsional
-	AuthorName    *string `json:"author_name"`     // Opsional
-	GenreID       *int64  `json:"genre_id"`        // Opsional, tapi sebaiknya ada jika ingin dikategorikan
-	CoverImageURL *string `json:"cover_image_url"` // Opsional
+	Title         string   `json:"title" binding:"required,min=3,max=255"`
+	Description   *string  `json:"description"`                                       // Opsional
+	AuthorName    *string  `json:"author_name"`                                       // Opsional
+	GenreIDs      []int64  `json:"genre_ids" binding:"omitempty,max=10,dive,gt=0"`    // Opsional, tapi sebaiknya ada jika ingin dikategorikan
+	Tags          []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"` // Opsional, tag baru dibuat otomatis
+	CoverImageURL *string  `json:"cover_image_url"`                                   // Opsional
 	// Tambahkan validasi lain jika perlu, misal untuk URL
 }
 
 // UpdateComicInput adalah struct untuk validasi input saat memperbarui komik yang sudah ada.
 // Semua field bersifat opsional karena pengguna mungkin ingin memperbarui hanya beberapa field.
 type UpdateComicInput struct {
-	Title         *string `json:"title" binding:"omitempty,min=3,max=255"`
-	Description   *string `json:"description"`     // Opsional
-	AuthorName    *string `json:"author_name"`     // Opsional
-	GenreID       *int64  `json:"genre_id"`        // Opsional
-	CoverImageURL *string `json:"cover_image_url"` // Opsional
+	Title         *string   `json:"title" binding:"omitempty,min=3,max=255"`
+	Description   *string   `json:"description"`                                       // Opsional
+	AuthorName    *string   `json:"author_name"`                                       // Opsional
+	GenreIDs      *[]int64  `json:"genre_ids" binding:"omitempty,max=10,dive,gt=0"`    // Opsional, menggantikan seluruh genre
+	Tags          *[]string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"` // Opsional, menggantikan seluruh tag
+	CoverImageURL *string   `json:"cover_image_url"`                                   // Opsional
 	// Semua field opsional karena ini adalah operasi update partial
 }
+
+// ListComicsQuery adalah struct untuk binding query string pada GET /api/comics.
+// Tanggal bisa berformat YYYY-MM-DD atau RFC3339; batas "_to" bersifat inklusif untuk format tanggal.
+// genre_ids dan tags berupa daftar dipisah koma; genre_match/tag_match menentukan apakah komik cukup
+// memiliki salah satu (any, default) atau harus memiliki semuanya (all).
+type ListComicsQuery struct {
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset      int    `form:"offset" binding:"omitempty,min=0"`
+	Cursor      string `form:"cursor"`
+	GenreID     *int64 `form:"genre_id"` // Alias satu genre, digabung dengan genre_ids
+	GenreIDs    string `form:"genre_ids"`
+	GenreMatch  string `form:"genre_match" binding:"omitempty,oneof=any all"`
+	Tags        string `form:"tags"`
+	TagMatch    string `form:"tag_match" binding:"omitempty,oneof=any all"`
+	AuthorName  string `form:"author_name"`
+	CreatedFrom string `form:"created_from"`
+	CreatedTo   string `form:"created_to"`
+	UpdatedFrom string `form:"updated_from"`
+	UpdatedTo   string `form:"updated_to"`
+	Sort        string `form:"sort" binding:"omitempty,oneof=created_at updated_at title popularity"`
+	Order       string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Status      string `form:"status" binding:"omitempty,oneof=draft in_review published hidden"` // Diabaikan pada listing publik
+}
+
+// SearchComicsQuery adalah struct untuk binding query string pada GET /api/comics/search.
+type SearchComicsQuery struct {
+	Q      string `form:"q" binding:"required,min=2,max=100"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,551 @@
+package comics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/middleware"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ListChapterCommentsHandler menampilkan komentar teratas pada sebuah chapter dengan cursor pagination.
+// Balasan diambil terpisah lewat ListCommentRepliesHandler; reply_count menunjukkan jumlahnya.
+func ListChapterCommentsHandler(c *gin.Context) {
+	chapterID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID chapter tidak valid"})
+		return
+	}
+	var query CommentListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	if _, _, ok := loadVisibleChapterByID(c, chapterID); !ok {
+		return
+	}
+
+	params := database.CommentListParams{
+		ChapterID:  chapterID,
+		PageNumber: query.Page,
+		ViewerID:   c.GetString("userID"),
+		Sort:       query.Sort,
+		Cursor:     query.Cursor,
+		Limit:      query.Limit,
+	}
+	if params.Sort == "" {
+		params.Sort = database.CommentSortNewest
+	}
+	if params.Limit == 0 {
+		params.Limit = defaultComicPageLimit
+	}
+
+	comments, nextCursor, err := database.ListChapterComments(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor tidak valid untuk sort yang diminta"})
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komentar chapter"})
+		return
+	}
+	respondCommentList(c, comments, params.Limit, nextCursor)
+}
+
+// ListCommentRepliesHandler menampilkan balasan dalam thread sebuah komentar teratas, yang terlama lebih dulu.
+func ListCommentRepliesHandler(c *gin.Context) {
+	var query CommentRepliesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	root, _, ok := loadVisibleComment(c)
+	if !ok {
+		return
+	}
+	if root.RootID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Balasan hanya bisa diambil dari komentar teratas", "root_id": *root.RootID})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	replies, nextCursor, err := database.ListCommentReplies(c.Request.Context(), root.ID, c.GetString("userID"), query.Cursor, limit)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor tidak valid"})
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil balasan komentar"})
+		return
+	}
+	respondCommentList(c, replies, limit, nextCursor)
+}
+
+// respondCommentList menulis daftar komentar publik beserta informasi cursor pagination.
+func respondCommentList(c *gin.Context, comments []models.Comment, limit int, nextCursor *string) {
+	for i := range comments {
+		redactComment(&comments[i])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": comments,
+		"pagination": gin.H{
+			"limit":       limit,
+			"has_more":    nextCursor != nil,
+			"next_cursor": nextCursor,
+		},
+	})
+}
+
+// redactComment mengosongkan isi komentar yang disembunyikan atau dihapus sebelum ditampilkan untuk publik.
+// Catatan moderasi hanya untuk moderator.
+func redactComment(cm *models.Comment) {
+	if cm.Status != models.CommentStatusPublished {
+		cm.Body = nil
+	}
+	cm.ModerationNote = nil
+}
+
+// CreateCommentHandler menulis komentar baru pada chapter yang sudah rilis untuk publik.
+// Membutuhkan izin comment:create.
+func CreateCommentHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	chapterID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID chapter tidak valid"})
+		return
+	}
+	var input CreateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	chapter, comic, ok := loadVisibleChapterByID(c, chapterID)
+	if !ok {
+		return
+	}
+	// Pratinjau anggota tim dan peninjau tidak bisa dikomentari
+	if comic.Status != models.PublicationPublished || !chapter.IsReleased(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chapter belum rilis untuk publik"})
+		return
+	}
+	createComment(c, chapter.ID, userID, input, input.PageNumber, nil)
+}
+
+// ReplyCommentHandler menulis balasan untuk sebuah komentar yang masih tampil.
+// Balasan untuk balasan tetap berada di thread komentar teratas yang sama, dan penulis komentar yang dibalas
+// mendapat notifikasi lewat bus. Membutuhkan izin comment:create.
+func ReplyCommentHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		var input CreateCommentInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		parent, chapter, ok := loadVisibleComment(c)
+		if !ok {
+			return
+		}
+		if parent.Status != models.CommentStatusPublished {
+			c.JSON(http.StatusConflict, gin.H{"error": "Komentar yang dibalas sudah tidak tampil"})
+			return
+		}
+
+		reply := createComment(c, parent.ChapterID, userID, input, parent.PageNumber, parent)
+		if reply != nil && bus != nil && parent.UserID != userID {
+			event := events.CommentReplied{
+				ReplyID:      reply.ID,
+				ParentID:     parent.ID,
+				ParentUserID: parent.UserID,
+				ReplierID:    userID,
+				ComicID:      chapter.ComicID,
+				ChapterID:    chapter.ID,
+				IsSpoiler:    reply.IsSpoiler,
+			}
+			// Isi balasan spoiler tidak ikut ke notifikasi, email, maupun web push
+			if !reply.IsSpoiler {
+				event.Body = *reply.Body
+			}
+			bus.Publish(c.Request.Context(), event)
+		}
+	}
+}
+
+// createComment menyimpan komentar atau balasan lalu menulis komentar baru sebagai response 201.
+// Mengembalikan komentar baru, atau nil jika response error sudah ditulis.
+func createComment(c *gin.Context, chapterID int64, userID string, input CreateCommentInput, pageNumber *int, parent *models.Comment) *models.Comment {
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Isi komentar tidak boleh kosong"})
+		return nil
+	}
+
+	cm, err := database.CreateComment(c.Request.Context(), chapterID, userID, body, input.IsSpoiler, pageNumber, parent)
+	if err != nil {
+		if errors.Is(err, database.ErrCommentPageNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nomor halaman tidak ditemukan di chapter ini"})
+			return nil
+		}
+		c.Error(err)
+		log.Printf("Error saat menyimpan komentar untuk chapter ID %d: %v\nUserID: %s\n", chapterID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan komentar"})
+		return nil
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": cm})
+	return cm
+}
+
+// UpdateCommentHandler mengubah isi dan/atau penanda spoiler komentar. Hanya penulis komentar yang bisa menyunting,
+// dan hanya selama komentar masih tampil. Membutuhkan izin comment:create.
+func UpdateCommentHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	commentID, ok := parseCommentID(c)
+	if !ok {
+		return
+	}
+	var input UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	if input.Body != nil {
+		trimmed := strings.TrimSpace(*input.Body)
+		if trimmed == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Isi komentar tidak boleh kosong"})
+			return
+		}
+		input.Body = &trimmed
+	}
+
+	cm, err := database.GetComment(c.Request.Context(), commentID, userID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komentar"})
+		return
+	}
+	if cm == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komentar tidak ditemukan"})
+		return
+	}
+	if cm.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya penulis yang dapat menyunting komentar ini"})
+		return
+	}
+	if cm.Status != models.CommentStatusPublished {
+		c.JSON(http.StatusConflict, gin.H{"error": "Komentar yang disembunyikan atau dihapus tidak bisa disunting", "status": cm.Status})
+		return
+	}
+
+	updated, err := database.UpdateComment(c.Request.Context(), commentID, userID, input.Body, input.IsSpoiler)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat memperbarui komentar ID %d: %v\nUserID: %s\n", commentID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui komentar"})
+		return
+	}
+	if updated == nil {
+		// Komentar disembunyikan atau dihapus di antara pemeriksaan dan penyuntingan
+		c.JSON(http.StatusConflict, gin.H{"error": "Komentar yang disembunyikan atau dihapus tidak bisa disunting"})
+		return
+	}
+	updated.ModerationNote = nil
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// DeleteCommentHandler menghapus komentar. Isi komentar yang masih memiliki balasan diganti penanda "dihapus"
+// agar thread tidak terputus. Membutuhkan izin comment:delete:own untuk komentar sendiri
+// atau comment:moderate untuk komentar pengguna lain; penulisnya mendapat notifikasi jika dihapus moderator.
+func DeleteCommentHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		commentID, ok := parseCommentID(c)
+		if !ok {
+			return
+		}
+
+		cm, err := database.GetComment(c.Request.Context(), commentID, userID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komentar"})
+			return
+		}
+		if cm == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Komentar tidak ditemukan"})
+			return
+		}
+		allowed, ok := middleware.CanAccessOwned(c, authz.CommentDeletePolicy, cm)
+		if !ok {
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki izin untuk menghapus komentar ini"})
+			return
+		}
+
+		// Penghapusan oleh penulis sendiri tidak dicatat sebagai aksi moderasi
+		var moderatorID *string
+		if cm.UserID != userID {
+			moderatorID = &userID
+		}
+		if !changeCommentStatus(c, commentID, models.CommentStatusDeleted, moderatorID, nil) {
+			return
+		}
+		publishCommentModerated(c, bus, cm, events.ModerationDeleted, nil)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SetCommentLikeHandler menyukai komentar yang masih tampil untuk pengguna saat ini.
+func SetCommentLikeHandler(c *gin.Context) {
+	setCommentLike(c, true)
+}
+
+// UnsetCommentLikeHandler membatalkan suka pengguna saat ini pada sebuah komentar.
+func UnsetCommentLikeHandler(c *gin.Context) {
+	setCommentLike(c, false)
+}
+
+// setCommentLike mengubah status suka komentar lalu menulis jumlah suka terbaru sebagai response.
+func setCommentLike(c *gin.Context, like bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cm, _, ok := loadVisibleComment(c)
+	if !ok {
+		return
+	}
+	if like && cm.Status != models.CommentStatusPublished {
+		c.JSON(http.StatusConflict, gin.H{"error": "Komentar sudah tidak tampil"})
+		return
+	}
+
+	likeCount, found, err := database.SetCommentLike(c.Request.Context(), cm.ID, userID, like)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menyimpan suka komentar ID %d: %v\nUserID: %s\n", cm.ID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan suka komentar"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komentar tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"like_count": likeCount, "liked": like}})
+}
+
+// ListCommentModerationHandler menampilkan komentar untuk moderator, yang terbaru lebih dulu.
+// Membutuhkan izin comment:moderate.
+func ListCommentModerationHandler(c *gin.Context) {
+	var query CommentModerationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	comments, total, err := database.ListCommentsForModeration(c.Request.Context(), query.Status, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil antrean moderasi komentar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": comments,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(comments)) < total,
+		},
+	})
+}
+
+// HideCommentHandler menyembunyikan komentar beserta alasannya, lalu memberi tahu penulisnya lewat bus.
+// Membutuhkan izin comment:moderate.
+func HideCommentHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commentID, ok := parseCommentID(c)
+		if !ok {
+			return
+		}
+		var input CommentModerationInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		userID := c.GetString("userID")
+		if !changeCommentStatus(c, commentID, models.CommentStatusHidden, &userID, &input.Reason) {
+			return
+		}
+		if cm := respondModeratedComment(c, commentID); cm != nil {
+			publishCommentModerated(c, bus, cm, events.ModerationHidden, &input.Reason)
+		}
+	}
+}
+
+// RestoreCommentHandler menampilkan kembali komentar yang disembunyikan, lalu memberi tahu penulisnya lewat bus.
+// Membutuhkan izin comment:moderate.
+func RestoreCommentHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commentID, ok := parseCommentID(c)
+		if !ok {
+			return
+		}
+		userID := c.GetString("userID")
+		if !changeCommentStatus(c, commentID, models.CommentStatusPublished, &userID, nil) {
+			return
+		}
+		if cm := respondModeratedComment(c, commentID); cm != nil {
+			publishCommentModerated(c, bus, cm, events.ModerationRestored, nil)
+		}
+	}
+}
+
+// SetCommentSpoilerHandler menandai atau melepas penanda spoiler pada komentar pengguna lain.
+// Membutuhkan izin comment:moderate.
+func SetCommentSpoilerHandler(c *gin.Context) {
+	commentID, ok := parseCommentID(c)
+	if !ok {
+		return
+	}
+	var input CommentSpoilerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	found, err := database.SetCommentSpoiler(c.Request.Context(), commentID, *input.IsSpoiler)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah penanda spoiler komentar"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komentar tidak ditemukan"})
+		return
+	}
+	respondModeratedComment(c, commentID)
+}
+
+// changeCommentStatus mengubah status komentar.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func changeCommentStatus(c *gin.Context, commentID int64, status string, moderatorID, note *string) bool {
+	found, err := database.SetCommentStatus(c.Request.Context(), commentID, status, moderatorID, note)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidTransition) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Aksi tidak bisa dilakukan pada status komentar saat ini"})
+			return false
+		}
+		c.Error(err)
+		log.Printf("Error saat mengubah status komentar ID %d menjadi %s: %v\n", commentID, status, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah status komentar"})
+		return false
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komentar tidak ditemukan"})
+		return false
+	}
+	return true
+}
+
+// respondModeratedComment menulis komentar terbaru, lengkap dengan catatan moderasi, sebagai response.
+// Mengembalikan komentar tersebut, atau nil jika response error sudah ditulis.
+func respondModeratedComment(c *gin.Context, commentID int64) *models.Comment {
+	cm, err := database.GetComment(c.Request.Context(), commentID, c.GetString("userID"))
+	if err != nil || cm == nil {
+		if err != nil {
+			c.Error(err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komentar terbaru"})
+		return nil
+	}
+	c.JSON(http.StatusOK, gin.H{"data": cm})
+	return cm
+}
+
+// publishCommentModerated mengirim event moderasi komentar ke bus agar penulisnya mendapat notifikasi.
+// Tidak ada event jika bus nil atau moderator memoderasi komentarnya sendiri.
+func publishCommentModerated(c *gin.Context, bus *events.Bus, cm *models.Comment, action string, reason *string) {
+	if bus == nil || cm.UserID == c.GetString("userID") {
+		return
+	}
+	chapter, err := database.GetChapterByID(c.Request.Context(), cm.ChapterID)
+	if err != nil || chapter == nil {
+		log.Printf("Peringatan: Event moderasi komentar ID %d tidak dikirim, chapter tidak ditemukan: %v\n", cm.ID, err)
+		return
+	}
+	bus.Publish(c.Request.Context(), events.ContentModerated{
+		OwnerID:     cm.UserID,
+		ContentType: events.ModeratedComment,
+		ContentID:   cm.ID,
+		Action:      action,
+		Reason:      reason,
+		ComicID:     chapter.ComicID,
+		ChapterID:   &chapter.ID,
+	})
+}
+
+// loadVisibleComment mengambil komentar dari parameter URL ":id" beserta chapter-nya dan memastikan chapter
+// tersebut boleh dilihat pengguna saat ini. Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadVisibleComment(c *gin.Context) (*models.Comment, *models.Chapter, bool) {
+	commentID, ok := parseCommentID(c)
+	if !ok {
+		return nil, nil, false
+	}
+	cm, err := database.GetComment(c.Request.Context(), commentID, c.GetString("userID"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komentar"})
+		return nil, nil, false
+	}
+	if cm == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komentar tidak ditemukan"})
+		return nil, nil, false
+	}
+	chapter, _, ok := loadVisibleChapterByID(c, cm.ChapterID)
+	if !ok {
+		return nil, nil, false
+	}
+	return cm, chapter, true
+}
+
+// parseCommentID membaca ID komentar dari parameter URL ":id".
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func parseCommentID(c *gin.Context) (int64, bool) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komentar tidak valid"})
+		return 0, false
+	}
+	return commentID, true
+}
@@ -0,0 +1,47 @@
+package comics
+
+// CreateCommentInput adalah struct untuk validasi input saat menulis komentar atau balasan.
+// page_number opsional untuk mengomentari halaman tertentu; balasan selalu mengikuti halaman komentar induknya.
+type CreateCommentInput struct {
+	Body       string `json:"body" binding:"required,max=5000"`
+	IsSpoiler  bool   `json:"is_spoiler"`
+	PageNumber *int   `json:"page_number" binding:"omitempty,min=1"`
+}
+
+// UpdateCommentInput adalah struct untuk validasi input saat penulis menyunting komentarnya.
+type UpdateCommentInput struct {
+	Body      *string `json:"body" binding:"omitempty,max=5000"`
+	IsSpoiler *bool   `json:"is_spoiler"`
+}
+
+// CommentListQuery adalah struct untuk binding query string pada daftar komentar sebuah chapter.
+type CommentListQuery struct {
+	Sort   string `form:"sort" binding:"omitempty,oneof=newest top"`
+	Page   *int   `form:"page" binding:"omitempty,min=1"` // Hanya komentar pada nomor halaman ini
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// CommentRepliesQuery adalah struct untuk binding query string pada daftar balasan sebuah thread.
+type CommentRepliesQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// CommentModerationQuery adalah struct untuk binding query string pada daftar komentar untuk moderator.
+type CommentModerationQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=published hidden deleted"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// CommentModerationInput adalah struct untuk validasi alasan moderator saat menyembunyikan komentar.
+// Alasan ikut dikirim ke penulis komentar lewat notifikasi.
+type CommentModerationInput struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
+
+// CommentSpoilerInput adalah struct untuk validasi input saat moderator mengubah penanda spoiler.
+type CommentSpoilerInput struct {
+	IsSpoiler *bool `json:"is_spoiler" binding:"required"`
+}
@@ -0,0 +1,187 @@
+package comics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/archive"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/media"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/pdf"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// openPageImage membuka gambar halaman dari storage aplikasi. URL yang tidak dikelola storage ditolak; server
+// tidak pernah mengambil URL gambar sembarang lewat HTTP karena route unduhan bisa diakses publik.
+func openPageImage(ctx context.Context, store storage.Storage, imageURL string) (io.ReadCloser, error) {
+	key, ok := store.KeyFromURL(imageURL)
+	if !ok {
+		return nil, fmt.Errorf("gambar %s tidak disimpan di storage aplikasi", imageURL)
+	}
+	return store.Open(ctx, key)
+}
+
+// downloadFilename membentuk nama file unduhan yang aman, contoh: "Judul Komik - Chapter 10.5.cbz".
+func downloadFilename(comic *models.Comic, chapter *models.Chapter, ext string) string {
+	name := fmt.Sprintf("%s - Chapter %s", comic.Title, strconv.FormatFloat(float64(chapter.ChapterNumber), 'f', -1, 32))
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, name)
+	return name + ext
+}
+
+// DownloadChapterHandler menangani unduhan satu chapter sebagai CBZ (default) atau PDF.
+// File dibentuk dan dikirim secara streaming halaman demi halaman tanpa menampung seluruh file di memori.
+// Chapter yang belum dipublikasikan hanya bisa diunduh oleh anggota tim komik dan peninjau.
+func DownloadChapterHandler(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID chapter tidak valid"})
+			return
+		}
+		format := c.DefaultQuery("format", "cbz")
+		if format != "cbz" && format != "pdf" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format harus 'cbz' atau 'pdf'"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		chapter, err := database.GetChapterByID(ctx, chapterID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter"})
+			return
+		}
+		if chapter == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chapter tidak ditemukan"})
+			return
+		}
+		comic, _, ok := loadVisibleChapter(c, chapter)
+		if !ok {
+			return
+		}
+		pages, err := database.GetPagesByChapterID(ctx, chapter.ID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil halaman chapter"})
+			return
+		}
+		if len(pages) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chapter belum memiliki halaman"})
+			return
+		}
+
+		// Halaman dengan gambar di luar storage aplikasi tidak bisa dimasukkan ke file unduhan
+		for _, page := range pages {
+			if _, ok := store.KeyFromURL(page.ImageURL); !ok {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Chapter ini berisi halaman yang tidak disimpan di storage aplikasi sehingga tidak bisa diunduh"})
+				return
+			}
+		}
+
+		// Decoder WebP tidak tersedia, jadi chapter berisi WebP hanya bisa diunduh sebagai CBZ. Begitu juga
+		// gambar PNG/GIF yang melebihi batas konversi PDF.
+		if format == "pdf" {
+			for _, page := range pages {
+				if strings.EqualFold(path.Ext(page.ImageURL), ".webp") {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Chapter ini berisi gambar WebP yang belum didukung untuk PDF, gunakan format=cbz"})
+					return
+				}
+				// Gambar non-JPEG harus di-decode penuh; yang terlalu besar ditolak sebelum header dikirim
+				if pdf.NeedsConversion(path.Ext(page.ImageURL)) && page.Width != nil && page.Height != nil &&
+					int64(*page.Width)*int64(*page.Height) > pdf.MaxConvertPixels {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Chapter ini berisi gambar PNG/GIF yang terlalu besar untuk PDF, gunakan format=cbz"})
+					return
+				}
+			}
+		}
+
+		// Header dikirim sebelum isi; setelah ini error hanya bisa dicatat dan koneksi diputus
+		if format == "pdf" {
+			c.Header("Content-Type", "application/pdf")
+			c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": downloadFilename(comic, chapter, ".pdf")}))
+			err = streamChapterPDF(ctx, c.Writer, store, comic, pages)
+		} else {
+			c.Header("Content-Type", "application/vnd.comicbook+zip")
+			c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": downloadFilename(comic, chapter, ".cbz")}))
+			err = streamChapterCBZ(ctx, c.Writer, store, comic, chapter, pages)
+		}
+		if err != nil {
+			c.Error(err)
+			log.Printf("Error saat streaming unduhan chapter ID %d (%s): %v\n", chapter.ID, format, err)
+			c.Abort()
+		}
+	}
+}
+
+// streamChapterCBZ menulis arsip CBZ berisi ComicInfo.xml dan seluruh halaman ke w.
+func streamChapterCBZ(ctx context.Context, w io.Writer, store storage.Storage, comic *models.Comic, chapter *models.Chapter, pages []models.Page) error {
+	cbz, err := archive.NewCBZWriter(w, archive.NewComicInfo(comic, chapter, pages), chapter.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Lebar penomoran file mengikuti jumlah halaman agar urutan tetap benar di pembaca yang tidak natural-sort
+	width := len(strconv.Itoa(len(pages)))
+	for i, page := range pages {
+		if err := func() error {
+			rc, err := openPageImage(ctx, store, page.ImageURL)
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+
+			br := bufio.NewReader(io.LimitReader(rc, media.MaxImageBytes))
+			ext := path.Ext(page.ImageURL)
+			if head, _ := br.Peek(512); len(head) > 0 {
+				if sniffed := media.ExtensionFor(http.DetectContentType(head)); sniffed != "" {
+					ext = sniffed
+				}
+			}
+			return cbz.AddPage(fmt.Sprintf("%0*d%s", width, i+1, ext), br)
+		}(); err != nil {
+			return err
+		}
+	}
+	return cbz.Close()
+}
+
+// streamChapterPDF menulis dokumen PDF dengan satu halaman per gambar ke w.
+func streamChapterPDF(ctx context.Context, w io.Writer, store storage.Storage, comic *models.Comic, pages []models.Page) error {
+	author := ""
+	if comic.AuthorName != nil {
+		author = *comic.AuthorName
+	}
+	doc, err := pdf.NewWriter(w, len(pages), comic.Title, author)
+	if err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		if err := func() error {
+			rc, err := openPageImage(ctx, store, page.ImageURL)
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			return doc.AddImagePage(io.LimitReader(rc, media.MaxImageBytes))
+		}(); err != nil {
+			return err
+		}
+	}
+	return doc.Close()
+}
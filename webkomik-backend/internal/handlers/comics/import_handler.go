@@ -0,0 +1,135 @@
+package comics
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/archive"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/media"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// maxArchiveEntries adalah jumlah gambar maksimum dalam satu arsip chapter.
+const maxArchiveEntries = 500
+
+// ImportChapterHandler menangani pembuatan satu chapter utuh dari arsip CBZ/ZIP (multipart, field "archive").
+// Gambar diurutkan secara natural berdasarkan nama file; jika satu entri saja gagal divalidasi atau disimpan,
+// chapter dan semua halaman dibatalkan serta file yang sudah tersimpan dihapus.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func ImportChapterHandler(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comic, userID, ok := loadManagedComic(c, authz.ChapterPublishPolicy)
+		if !ok {
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBodySize)
+		var input ImportChapterInput
+		if err := c.ShouldBind(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		if !validPublishAt(c, input.PublishAt) {
+			return
+		}
+
+		fh, err := c.FormFile("archive")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File arsip CBZ/ZIP harus diunggah pada field 'archive'"})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file arsip"})
+			return
+		}
+		defer f.Close()
+
+		entries, err := archive.ImageEntries(f, fh.Size, maxArchiveEntries)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Arsip ditolak", "details": err.Error()})
+			return
+		}
+
+		// Cek lebih awal agar tidak perlu mengunggah gambar jika nomor chapter sudah dipakai
+		existing, err := database.GetChapterByNumber(c.Request.Context(), comic.ID, *input.ChapterNumber)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa chapter"})
+			return
+		}
+		if existing != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Nomor chapter sudah digunakan untuk komik ini"})
+			return
+		}
+
+		chapterData := models.Chapter{
+			ChapterNumber: *input.ChapterNumber,
+			Title:         input.Title,
+			PublishAt:     input.PublishAt,
+		}
+
+		// Gambar diunggah lebih dulu dengan ID chapter yang sudah dipesan, lalu chapter dan halamannya
+		// disimpan dalam transaksi singkat; koneksi database tidak tertahan selama upload.
+		chapterID, err := database.ReserveChapterID(c.Request.Context())
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengimpor chapter"})
+			return
+		}
+		var createdChapter *models.Chapter
+		pages, storedKeys, err := storeArchivePages(c.Request.Context(), store, comic.ID, chapterID, entries)
+		if err == nil {
+			createdChapter, err = database.CreateChapterWithPages(c.Request.Context(), chapterID, comic.ID, chapterData, pages)
+		}
+		if err != nil {
+			deleteStoredKeys(store, storedKeys)
+			switch {
+			case errors.Is(err, archive.ErrInvalidArchive):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Arsip ditolak", "details": err.Error()})
+			case errors.Is(err, database.ErrDuplicateChapterNumber):
+				c.JSON(http.StatusConflict, gin.H{"error": "Nomor chapter sudah digunakan untuk komik ini"})
+			default:
+				c.Error(err)
+				log.Printf("Error saat import chapter untuk komik ID %d: %v\nUserID: %s\n", comic.ID, err, userID)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengimpor chapter"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"data": createdChapter})
+	}
+}
+
+// storeArchivePages memvalidasi dan mengunggah setiap gambar arsip sesuai urutannya sebagai halaman chapterID.
+// Key yang sudah tersimpan selalu dikembalikan, juga saat gagal, agar bisa dihapus oleh pemanggil.
+func storeArchivePages(ctx context.Context, store storage.Storage, comicID, chapterID int64, entries []*zip.File) ([]models.Page, []string, error) {
+	pages := make([]models.Page, 0, len(entries))
+	storedKeys := []string{}
+	for i, entry := range entries {
+		data, err := archive.ReadEntry(entry, media.MaxImageBytes)
+		if err != nil {
+			return nil, storedKeys, err
+		}
+		page, key, err := storePageImage(ctx, store, comicID, chapterID, data, data.Size())
+		if err != nil {
+			if errors.Is(err, media.ErrInvalidImage) {
+				return nil, storedKeys, fmt.Errorf("%w: %s: %v", archive.ErrInvalidArchive, entry.Name, err)
+			}
+			return nil, storedKeys, err
+		}
+		storedKeys = append(storedKeys, key)
+		page.PageNumber = i + 1
+		pages = append(pages, *page)
+	}
+	return pages, storedKeys, nil
+}
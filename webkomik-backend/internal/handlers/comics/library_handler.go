@@ -0,0 +1,157 @@
+package comics
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// FollowComicHandler menambahkan komik ke pustaka pengguna sebagai komik yang diikuti.
+func FollowComicHandler(c *gin.Context) {
+	addToLibrary(c, models.LibraryKindFollow)
+}
+
+// UnfollowComicHandler berhenti mengikuti komik. Komik tetap di pustaka jika masih ditandai.
+func UnfollowComicHandler(c *gin.Context) {
+	removeFromLibrary(c, models.LibraryKindFollow)
+}
+
+// BookmarkComicHandler menambahkan komik ke pustaka pengguna sebagai komik yang ditandai.
+func BookmarkComicHandler(c *gin.Context) {
+	addToLibrary(c, models.LibraryKindBookmark)
+}
+
+// UnbookmarkComicHandler menghapus tanda komik. Komik tetap di pustaka jika masih diikuti.
+func UnbookmarkComicHandler(c *gin.Context) {
+	removeFromLibrary(c, models.LibraryKindBookmark)
+}
+
+// addToLibrary menyalakan flag pustaka kind untuk komik dari parameter URL ":id".
+// Hanya komik yang boleh dilihat pengguna yang bisa ditambahkan.
+func addToLibrary(c *gin.Context, kind string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	comic, _, ok := loadVisibleComic(c)
+	if !ok {
+		return
+	}
+
+	state, err := database.SetLibraryFlag(c.Request.Context(), userID, comic.ID, kind, true)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menambahkan komik ID %d ke pustaka (%s): %v\nUserID: %s\n", comic.ID, kind, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui pustaka"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": state})
+}
+
+// removeFromLibrary mematikan flag pustaka kind untuk komik dari parameter URL ":id".
+// Komik tidak harus masih terlihat sehingga komik yang sudah disembunyikan tetap bisa dikeluarkan dari pustaka.
+func removeFromLibrary(c *gin.Context, kind string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+		return
+	}
+
+	if _, err := database.SetLibraryFlag(c.Request.Context(), userID, comicID, kind, false); err != nil {
+		c.Error(err)
+		log.Printf("Error saat menghapus komik ID %d dari pustaka (%s): %v\nUserID: %s\n", comicID, kind, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui pustaka"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkComicReadHandler menandai chapter komik di pustaka sudah dibaca sampai chapter_number tertentu,
+// atau sampai chapter terbaru jika chapter_number tidak diberikan.
+func MarkComicReadHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+		return
+	}
+	var input MarkReadInput
+	// Body boleh kosong untuk menandai semua chapter
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+	}
+
+	state, err := database.MarkLibraryRead(c.Request.Context(), userID, comicID, input.ChapterNumber)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menandai chapter dibaca untuk komik ID %d: %v\nUserID: %s\n", comicID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui pustaka"})
+		return
+	}
+	if state == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ada di pustaka Anda"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": state})
+}
+
+// ListLibraryHandler menampilkan pustaka pengguna beserta jumlah chapter yang belum dibaca.
+// Default diurutkan berdasarkan rilis chapter terbaru; query "kind" menyaring komik yang diikuti atau ditandai.
+func ListLibraryHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var query LibraryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+
+	params := database.LibraryListParams{
+		UserID:     userID,
+		Kind:       query.Kind,
+		UnreadOnly: query.UnreadOnly,
+		Sort:       query.Sort,
+		Limit:      query.Limit,
+		Offset:     query.Offset,
+	}
+	if params.Sort == "" {
+		params.Sort = database.LibrarySortUpdated
+	}
+	if params.Limit == 0 {
+		params.Limit = defaultComicPageLimit
+	}
+
+	entries, total, err := database.ListLibrary(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat mengambil pustaka pengguna: %v\nUserID: %s\n", err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pustaka"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    params.Limit,
+			"offset":   params.Offset,
+			"has_more": int64(params.Offset+len(entries)) < total,
+		},
+	})
+}
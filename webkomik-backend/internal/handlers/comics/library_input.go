@@ -0,0 +1,16 @@
+package comics
+
+// LibraryQuery adalah struct untuk binding query string pada daftar pustaka pembaca.
+type LibraryQuery struct {
+	Kind       string `form:"kind" binding:"omitempty,oneof=follow bookmark"`
+	Sort       string `form:"sort" binding:"omitempty,oneof=updated added title unread"`
+	UnreadOnly bool   `form:"unread_only"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// MarkReadInput adalah struct untuk validasi input saat menandai chapter sudah dibaca.
+// chapter_number kosong berarti semua chapter yang sudah rilis ditandai dibaca.
+type MarkReadInput struct {
+	ChapterNumber *float32 `json:"chapter_number" binding:"omitempty,gte=0"`
+}
@@ -0,0 +1,272 @@
+package comics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/identity"
+	"github.com/gin-gonic/gin"
+)
+
+// GetComicMembersHandler menampilkan anggota tim sebuah komik.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func GetComicMembersHandler(c *gin.Context) {
+	comic, _, ok := loadManagedComic(c, authz.ChapterPublishPolicy)
+	if !ok {
+		return
+	}
+	// Anggota sudah dimuat saat pemeriksaan akses
+	c.JSON(http.StatusOK, gin.H{"data": comic.Members})
+}
+
+// UpdateComicMemberHandler mengubah peran anggota tim. Owner terakhir tidak bisa diturunkan.
+// Membutuhkan izin comic:update:any, atau comic:update:own untuk owner komik.
+func UpdateComicMemberHandler(c *gin.Context) {
+	comic, _, ok := loadManagedComic(c, authz.ComicMembersPolicy)
+	if !ok {
+		return
+	}
+	var input UpdateMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	memberID, ok := parseMemberID(c)
+	if !ok {
+		return
+	}
+	found, err := database.UpdateComicMemberRole(c.Request.Context(), comic.ID, memberID, input.Role)
+	if !respondMemberChange(c, found, err) {
+		return
+	}
+	members, err := database.GetComicMembers(c.Request.Context(), comic.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggota tim komik"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// RemoveComicMemberHandler mengeluarkan anggota dari tim. Owner boleh mengeluarkan siapa pun,
+// sedangkan anggota lain hanya boleh keluar sendiri. Owner terakhir tidak bisa dikeluarkan.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func RemoveComicMemberHandler(c *gin.Context) {
+	comic, userID, ok := loadManagedComic(c, authz.ChapterPublishPolicy)
+	if !ok {
+		return
+	}
+
+	memberID, ok := parseMemberID(c)
+	if !ok {
+		return
+	}
+	if memberID != userID {
+		allowed, ok := authorizeComic(c, authz.ComicMembersPolicy, comic)
+		if !ok {
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Hanya owner yang dapat mengeluarkan anggota lain"})
+			return
+		}
+	}
+
+	found, err := database.RemoveComicMember(c.Request.Context(), comic.ID, memberID)
+	if !respondMemberChange(c, found, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// parseMemberID mengambil ID anggota dari parameter URL ":userId".
+// Jika tidak valid, response error sudah ditulis dan fungsi mengembalikan false.
+func parseMemberID(c *gin.Context) (string, bool) {
+	var uri MemberURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggota tidak valid"})
+		return "", false
+	}
+	return uri.UserID, true
+}
+
+// respondMemberChange menulis response error untuk hasil perubahan anggota tim.
+// Mengembalikan true jika perubahan berhasil dan handler boleh melanjutkan.
+func respondMemberChange(c *gin.Context, found bool, err error) bool {
+	if err != nil {
+		if errors.Is(err, database.ErrLastOwner) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Komik harus memiliki minimal satu owner"})
+			return false
+		}
+		c.Error(err)
+		log.Printf("Error saat mengubah anggota tim komik: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah anggota tim komik"})
+		return false
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anggota tim tidak ditemukan"})
+		return false
+	}
+	return true
+}
+
+// CreateComicInvitationHandler mengundang pengguna (lewat ID atau email) untuk bergabung ke tim komik.
+// Membutuhkan izin comic:update:any, atau comic:update:own untuk owner komik.
+func CreateComicInvitationHandler(c *gin.Context) {
+	comic, userID, ok := loadManagedComic(c, authz.ComicMembersPolicy)
+	if !ok {
+		return
+	}
+	var input CreateInvitationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	invitation, err := database.CreateComicInvitation(c.Request.Context(), comic.ID, input.UserID, input.Email, input.Role, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrAlreadyMember):
+			c.JSON(http.StatusConflict, gin.H{"error": "Pengguna sudah menjadi anggota tim komik"})
+		case errors.Is(err, database.ErrDuplicateInvitation):
+			c.JSON(http.StatusConflict, gin.H{"error": "Pengguna ini masih memiliki undangan yang belum dijawab"})
+		default:
+			c.Error(err)
+			log.Printf("Error saat membuat undangan untuk komik ID %d: %v\n", comic.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat undangan"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": invitation})
+}
+
+// GetComicInvitationsHandler menampilkan undangan yang masih menunggu jawaban untuk sebuah komik.
+// Membutuhkan izin comic:update:any, atau comic:update:own untuk owner komik.
+func GetComicInvitationsHandler(c *gin.Context) {
+	comic, _, ok := loadManagedComic(c, authz.ComicMembersPolicy)
+	if !ok {
+		return
+	}
+	invitations, err := database.ListComicInvitations(c.Request.Context(), comic.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil undangan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// RevokeComicInvitationHandler membatalkan undangan yang belum dijawab.
+// Membutuhkan izin comic:update:any, atau comic:update:own untuk owner komik.
+func RevokeComicInvitationHandler(c *gin.Context) {
+	comic, _, ok := loadManagedComic(c, authz.ComicMembersPolicy)
+	if !ok {
+		return
+	}
+	invitationID, err := strconv.ParseInt(c.Param("invitationId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID undangan tidak valid"})
+		return
+	}
+
+	revoked, err := database.RevokeComicInvitation(c.Request.Context(), comic.ID, invitationID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membatalkan undangan"})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan atau sudah dijawab"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetMyInvitationsHandler menampilkan undangan tim komik yang ditujukan ke pengguna saat ini.
+func GetMyInvitationsHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		email, ok := confirmedEmail(c, idp, userID)
+		if !ok {
+			return
+		}
+		invitations, err := database.ListUserInvitations(c.Request.Context(), userID, email)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil undangan"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": invitations})
+	}
+}
+
+// AcceptInvitationHandler menerima undangan dan menjadikan pengguna saat ini anggota tim komik.
+func AcceptInvitationHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondInvitation(c, idp, true)
+	}
+}
+
+// DeclineInvitationHandler menolak undangan tim komik.
+func DeclineInvitationHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondInvitation(c, idp, false)
+	}
+}
+
+// confirmedEmail mengambil email pengguna yang sudah dikonfirmasi dari identity provider, atau string kosong
+// jika belum. Undangan yang ditujukan ke alamat email hanya cocok lewat email ini, bukan email atau
+// user_metadata di token yang bisa diubah pengguna sendiri, agar undangan tidak bisa diambil alih dengan
+// mendaftar memakai email orang lain. Jika gagal, response sudah ditulis dan fungsi mengembalikan false.
+func confirmedEmail(c *gin.Context, idp identity.Provider, userID string) (string, bool) {
+	email, err := idp.ConfirmedEmail(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat membaca email terkonfirmasi pengguna %s: %v\n", userID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal memeriksa email pengguna di identity provider"})
+		return "", false
+	}
+	return email, true
+}
+
+// respondInvitation menjawab undangan dari parameter URL ":id" atas nama pengguna saat ini.
+func respondInvitation(c *gin.Context, idp identity.Provider, accept bool) {
+	invitationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID undangan tidak valid"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	email, ok := confirmedEmail(c, idp, userID)
+	if !ok {
+		return
+	}
+
+	invitation, err := database.RespondComicInvitation(c.Request.Context(), invitationID, userID, email, accept)
+	if err != nil {
+		if errors.Is(err, database.ErrInvitationClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Undangan sudah dijawab, dibatalkan, atau kedaluwarsa"})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat menjawab undangan ID %d: %v\n", invitationID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menjawab undangan"})
+		return
+	}
+	if invitation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": invitation})
+}
@@ -0,0 +1,19 @@
+package comics
+
+// CreateInvitationInput adalah struct untuk validasi input undangan anggota tim.
+// Salah satu dari user_id atau email wajib diisi.
+type CreateInvitationInput struct {
+	UserID *string `json:"user_id" binding:"required_without=Email,omitempty,uuid"`
+	Email  *string `json:"email" binding:"required_without=UserID,omitempty,email,max=255"`
+	Role   string  `json:"role" binding:"required,oneof=owner editor uploader"`
+}
+
+// UpdateMemberInput adalah struct untuk validasi input perubahan peran anggota tim.
+type UpdateMemberInput struct {
+	Role string `json:"role" binding:"required,oneof=owner editor uploader"`
+}
+
+// MemberURI adalah struct untuk binding parameter URL ":userId" pada endpoint anggota tim.
+type MemberURI struct {
+	UserID string `uri:"userId" binding:"required,uuid"`
+}
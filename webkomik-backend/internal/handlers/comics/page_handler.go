@@ -0,0 +1,175 @@
+package comics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/media"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// Batasan upload halaman per request.
+const (
+	maxPagesPerUpload = 200
+	maxUploadBodySize = 500 << 20 // 500 MB
+)
+
+// pageImageKey membuat key storage yang unik untuk gambar halaman sebuah chapter.
+func pageImageKey(comicID, chapterID int64, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("gagal membuat nama file acak: %w", err)
+	}
+	return fmt.Sprintf("comics/%d/chapters/%d/%s%s", comicID, chapterID, hex.EncodeToString(buf), ext), nil
+}
+
+// storePageImage memvalidasi gambar lalu menyimpannya ke storage.
+// Mengembalikan data halaman (tanpa page_number) dan key storage untuk keperluan rollback.
+func storePageImage(ctx context.Context, store storage.Storage, comicID, chapterID int64, r io.ReadSeeker, size int64) (*models.Page, string, error) {
+	if size > media.MaxImageBytes {
+		return nil, "", fmt.Errorf("%w: ukuran %d byte melebihi batas %d byte", media.ErrInvalidImage, size, media.MaxImageBytes)
+	}
+	info, err := media.InspectImage(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := pageImageKey(comicID, chapterID, info.Ext)
+	if err != nil {
+		return nil, "", err
+	}
+	url, err := store.Put(ctx, key, r, size, info.ContentType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	width, height := info.Width, info.Height
+	return &models.Page{ImageURL: url, Width: &width, Height: &height}, key, nil
+}
+
+// deleteStoredKeys menghapus file yang sudah terlanjur disimpan ketika proses upload gagal.
+func deleteStoredKeys(store storage.Storage, keys []string) {
+	for _, key := range keys {
+		// Gunakan context baru karena context request mungkin sudah dibatalkan
+		if err := store.Delete(context.Background(), key); err != nil {
+			log.Printf("Peringatan: Gagal menghapus file %s setelah upload gagal: %v\n", key, err)
+		}
+	}
+}
+
+// deletePageImages menghapus file gambar halaman yang datanya sudah terhapus dari database.
+// Kegagalan hanya dicatat agar bisa dibersihkan manual; URL di luar storage aplikasi dilewati.
+func deletePageImages(store storage.Storage, imageURLs []string) {
+	for _, url := range imageURLs {
+		key, ok := store.KeyFromURL(url)
+		if !ok {
+			continue // File di luar storage aplikasi (URL eksternal)
+		}
+		// Gunakan context baru karena context request mungkin sudah dibatalkan
+		if err := store.Delete(context.Background(), key); err != nil {
+			log.Printf("Peringatan: Gagal menghapus file %s: %v\n", key, err)
+		}
+	}
+}
+
+// UploadPagesHandler menangani upload gambar halaman (multipart, field "images") untuk sebuah chapter.
+// Halaman ditambahkan berurutan setelah halaman terakhir, atau mulai dari "start_page" jika diberikan.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func UploadPagesHandler(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comic, chapter, ok := loadManagedChapter(c)
+		if !ok {
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBodySize)
+		form, err := c.MultipartForm()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Form multipart tidak valid", "details": err.Error()})
+			return
+		}
+		files := form.File["images"]
+		if len(files) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal satu file gambar harus diunggah pada field 'images'"})
+			return
+		}
+		if len(files) > maxPagesPerUpload {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Maksimal %d gambar per upload", maxPagesPerUpload)})
+			return
+		}
+
+		// Tentukan nomor halaman pertama
+		startPage := 0
+		if startStr := c.PostForm("start_page"); startStr != "" {
+			startPage, err = strconv.Atoi(startStr)
+			if err != nil || startPage < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "start_page harus berupa angka >= 1"})
+				return
+			}
+		} else {
+			maxPage, err := database.GetMaxPageNumber(c.Request.Context(), chapter.ID)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca halaman chapter"})
+				return
+			}
+			startPage = maxPage + 1
+		}
+
+		// Validasi dan simpan setiap file, batalkan semua jika ada satu yang gagal
+		pages := make([]models.Page, 0, len(files))
+		storedKeys := make([]string, 0, len(files))
+		for i, fh := range files {
+			page, key, err := storeUploadedPage(c.Request.Context(), store, comic.ID, chapter.ID, fh)
+			if err != nil {
+				deleteStoredKeys(store, storedKeys)
+				if errors.Is(err, media.ErrInvalidImage) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File %q ditolak", fh.Filename), "details": err.Error()})
+					return
+				}
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan gambar halaman"})
+				return
+			}
+			page.PageNumber = startPage + i
+			pages = append(pages, *page)
+			storedKeys = append(storedKeys, key)
+		}
+
+		createdPages, err := database.CreatePages(c.Request.Context(), chapter.ID, pages)
+		if err != nil {
+			deleteStoredKeys(store, storedKeys)
+			if errors.Is(err, database.ErrDuplicatePageNumber) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Nomor halaman bertabrakan dengan halaman yang sudah ada"})
+				return
+			}
+			c.Error(err)
+			log.Printf("Error saat menyimpan halaman untuk chapter ID %d: %v\n", chapter.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan halaman"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"data": createdPages})
+	}
+}
+
+// storeUploadedPage membuka file multipart lalu meneruskannya ke storePageImage.
+func storeUploadedPage(ctx context.Context, store storage.Storage, comicID, chapterID int64, fh *multipart.FileHeader) (*models.Page, string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "", fmt.Errorf("gagal membuka file upload %s: %w", fh.Filename, err)
+	}
+	defer f.Close()
+	return storePageImage(ctx, store, comicID, chapterID, f, fh.Size)
+}
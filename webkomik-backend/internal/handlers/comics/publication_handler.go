@@ -0,0 +1,222 @@
+package comics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitComicHandler mengajukan komik berstatus draft (atau hidden) untuk ditinjau.
+// Membutuhkan izin comic:update:any, atau comic:update:own untuk owner/editor di tim komik.
+func SubmitComicHandler(c *gin.Context) {
+	comic, userID, ok := loadManagedComic(c, authz.ComicUpdatePolicy)
+	if !ok {
+		return
+	}
+	changeComicStatus(c, comic, database.PublicationSubmit, userID, nil)
+}
+
+// ApproveComicHandler mempublikasikan komik yang sedang ditinjau atau disembunyikan.
+// Membutuhkan izin content:review.
+func ApproveComicHandler(c *gin.Context) {
+	comic, ok := loadComic(c)
+	if !ok {
+		return
+	}
+	changeComicStatus(c, comic, database.PublicationApprove, c.GetString("userID"), nil)
+}
+
+// RejectComicHandler mengembalikan komik yang sedang ditinjau ke draft beserta alasannya.
+// Membutuhkan izin content:review.
+func RejectComicHandler(c *gin.Context) {
+	reviewComicWithReason(c, database.PublicationReject)
+}
+
+// HideComicHandler menurunkan komik dari publik beserta alasannya. Membutuhkan izin content:review.
+func HideComicHandler(c *gin.Context) {
+	reviewComicWithReason(c, database.PublicationHide)
+}
+
+// reviewComicWithReason menjalankan aksi peninjau yang wajib disertai alasan pada komik dari parameter URL ":id".
+func reviewComicWithReason(c *gin.Context, action string) {
+	comic, ok := loadComic(c)
+	if !ok {
+		return
+	}
+	var input ReviewDecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	changeComicStatus(c, comic, action, c.GetString("userID"), &input.Reason)
+}
+
+// changeComicStatus menjalankan aksi publikasi pada komik lalu menulis komik terbaru sebagai response.
+func changeComicStatus(c *gin.Context, comic *models.Comic, action, userID string, note *string) {
+	err := database.ChangeComicStatus(c.Request.Context(), comic.ID, action, userID, note)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidTransition) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Aksi tidak bisa dilakukan pada status komik saat ini", "status": comic.Status})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat menjalankan aksi %s pada komik ID %d: %v\nUserID: %s\n", action, comic.ID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah status komik"})
+		return
+	}
+
+	updated, err := database.GetComicByID(c.Request.Context(), comic.ID)
+	if err != nil || updated == nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komik yang telah diperbarui"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// SubmitChapterHandler mengajukan chapter berstatus draft (atau hidden) untuk ditinjau.
+// Membutuhkan izin chapter:publish:any, atau chapter:publish:own untuk anggota tim komik.
+func SubmitChapterHandler(c *gin.Context) {
+	comic, chapter, ok := loadManagedChapter(c)
+	if !ok {
+		return
+	}
+	changeChapterStatus(c, nil, comic, chapter, database.PublicationSubmit, c.GetString("userID"), nil)
+}
+
+// ApproveChapterHandler mempublikasikan chapter yang sedang ditinjau atau disembunyikan.
+// Chapter tanpa jadwal langsung rilis dan event chapter baru dikirim ke bus; chapter dengan publish_at
+// di masa depan dirilis oleh scheduler saat jadwalnya tiba. Membutuhkan izin content:review.
+func ApproveChapterHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comic, chapter, ok := loadReviewChapter(c)
+		if !ok {
+			return
+		}
+		changeChapterStatus(c, bus, comic, chapter, database.PublicationApprove, c.GetString("userID"), nil)
+	}
+}
+
+// RejectChapterHandler mengembalikan chapter yang sedang ditinjau ke draft beserta alasannya.
+// Membutuhkan izin content:review.
+func RejectChapterHandler(c *gin.Context) {
+	reviewChapterWithReason(c, database.PublicationReject)
+}
+
+// HideChapterHandler menurunkan chapter dari publik beserta alasannya. Membutuhkan izin content:review.
+func HideChapterHandler(c *gin.Context) {
+	reviewChapterWithReason(c, database.PublicationHide)
+}
+
+// loadReviewChapter mengambil komik dan chapter dari parameter URL ":id" dan ":number" untuk peninjau.
+func loadReviewChapter(c *gin.Context) (*models.Comic, *models.Chapter, bool) {
+	comic, ok := loadComic(c)
+	if !ok {
+		return nil, nil, false
+	}
+	chapter, ok := loadComicChapter(c, comic)
+	if !ok {
+		return nil, nil, false
+	}
+	return comic, chapter, true
+}
+
+// reviewChapterWithReason menjalankan aksi peninjau yang wajib disertai alasan pada chapter dari parameter URL.
+func reviewChapterWithReason(c *gin.Context, action string) {
+	comic, chapter, ok := loadReviewChapter(c)
+	if !ok {
+		return
+	}
+	var input ReviewDecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	changeChapterStatus(c, nil, comic, chapter, action, c.GetString("userID"), &input.Reason)
+}
+
+// changeChapterStatus menjalankan aksi publikasi pada chapter lalu menulis chapter terbaru sebagai response.
+// Jika chapter baru saja rilis dan bus tidak nil, event chapter baru dikirim ke bus.
+func changeChapterStatus(c *gin.Context, bus *events.Bus, comic *models.Comic, chapter *models.Chapter, action, userID string, note *string) {
+	released, err := database.ChangeChapterStatus(c.Request.Context(), chapter.ID, action, userID, note)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidTransition) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Aksi tidak bisa dilakukan pada status chapter saat ini", "status": chapter.Status})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat menjalankan aksi %s pada chapter ID %d: %v\nUserID: %s\n", action, chapter.ID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah status chapter"})
+		return
+	}
+
+	updated, err := database.GetChapterByID(c.Request.Context(), chapter.ID)
+	if err != nil || updated == nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil chapter yang telah diperbarui"})
+		return
+	}
+	if released && bus != nil {
+		bus.Publish(c.Request.Context(), events.NewChapterPublished(comic.Title, updated, false))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// ListReviewComicsHandler menampilkan antrean komik yang menunggu review dengan filter dan paginasi
+// yang sama seperti GET /comics, yang paling lama diajukan lebih dulu. Membutuhkan izin content:review.
+func ListReviewComicsHandler(c *gin.Context) {
+	respondComicList(c, func(params *database.ComicListParams) {
+		params.Statuses = []string{models.PublicationInReview}
+		params.Sort = database.ComicSortSubmittedAt
+		params.Desc = false
+	})
+}
+
+// ListReviewChaptersHandler menampilkan antrean chapter yang menunggu review, yang paling lama diajukan lebih dulu.
+// Membutuhkan izin content:review.
+func ListReviewChaptersHandler(c *gin.Context) {
+	var query ReviewQueueQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	chapters, total, err := database.ListChaptersByStatus(c.Request.Context(), models.PublicationInReview, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil antrean review chapter"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": chapters,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(chapters)) < total,
+		},
+	})
+}
+
+// ListMyComicsHandler menampilkan komik tempat pengguna saat ini menjadi anggota tim, termasuk draft
+// dan komik yang sedang ditinjau. Query "status" bisa dipakai untuk menyaring status publikasi.
+func ListMyComicsHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	respondComicList(c, func(params *database.ComicListParams) {
+		params.MemberID = &userID
+	})
+}
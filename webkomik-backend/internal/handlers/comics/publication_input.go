@@ -0,0 +1,12 @@
+package comics
+
+// ReviewDecisionInput adalah struct untuk validasi alasan dari peninjau saat menolak atau menyembunyikan konten.
+type ReviewDecisionInput struct {
+	Reason string `json:"reason" binding:"required,min=3,max=1000"`
+}
+
+// ReviewQueueQuery adalah struct untuk binding query string pada antrean review chapter.
+type ReviewQueueQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,131 @@
+package comics
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultContinueReadingLimit adalah jumlah default komik pada daftar lanjutkan membaca.
+const defaultContinueReadingLimit = 10
+
+// RecordProgressHandler menyimpan batch laporan posisi baca pengguna.
+// Semua laporan disimpan dalam satu perintah SQL; laporan untuk chapter yang tidak tersedia diabaikan.
+func RecordProgressHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var input RecordProgressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	// Waktu dari klien tidak boleh melebihi waktu server agar tidak mengunci progres di masa depan
+	now := time.Now()
+	updates := make([]models.ProgressUpdate, len(input.Entries))
+	for i, entry := range input.Entries {
+		readAt := now
+		if entry.ReadAt != nil && entry.ReadAt.Before(now) {
+			readAt = *entry.ReadAt
+		}
+		updates[i] = models.ProgressUpdate{ChapterID: entry.ChapterID, PageNumber: entry.PageNumber, ReadAt: readAt}
+	}
+
+	accepted, err := database.RecordReadingProgress(c.Request.Context(), userID, updates)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menyimpan %d progres baca: %v\nUserID: %s\n", len(updates), err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan progres baca"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"received": len(updates), "accepted": accepted}})
+}
+
+// ContinueReadingHandler menampilkan komik yang sedang dibaca pengguna beserta posisi terakhir
+// dan chapter berikutnya, yang terakhir dibaca lebih dulu.
+func ContinueReadingHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var query ContinueReadingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultContinueReadingLimit
+	}
+
+	entries, err := database.ListContinueReading(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar lanjutkan membaca"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+// ReadingHistoryHandler menampilkan riwayat chapter yang dibaca pengguna, yang terakhir dibaca lebih dulu.
+// Query "comic_id" bisa dipakai untuk melihat riwayat satu komik saja.
+func ReadingHistoryHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var query ReadingHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	entries, total, err := database.ListReadingHistory(c.Request.Context(), userID, query.ComicID, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat baca"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(entries)) < total,
+		},
+	})
+}
+
+// ClearReadingHistoryHandler menghapus riwayat baca pengguna, atau hanya riwayat satu komik
+// jika query "comic_id" diberikan.
+func ClearReadingHistoryHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var query ReadingHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+
+	if err := database.ClearReadingHistory(c.Request.Context(), userID, query.ComicID); err != nil {
+		c.Error(err)
+		log.Printf("Error saat menghapus riwayat baca: %v\nUserID: %s\n", err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus riwayat baca"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,29 @@
+package comics
+
+import "time"
+
+// RecordProgressInput adalah struct untuk validasi batch laporan posisi baca dari pembaca.
+// Klien dianjurkan mengumpulkan beberapa laporan lalu mengirimnya sekaligus (maksimal 100 per request).
+type RecordProgressInput struct {
+	Entries []ProgressEntryInput `json:"entries" binding:"required,min=1,max=100,dive"`
+}
+
+// ProgressEntryInput adalah satu laporan posisi baca.
+// read_at (RFC3339) adalah waktu halaman dibaca di klien; kosong berarti waktu request diterima.
+type ProgressEntryInput struct {
+	ChapterID  int64      `json:"chapter_id" binding:"required,min=1"`
+	PageNumber int        `json:"page_number" binding:"required,min=1"`
+	ReadAt     *time.Time `json:"read_at"`
+}
+
+// ContinueReadingQuery adalah struct untuk binding query string pada daftar lanjutkan membaca.
+type ContinueReadingQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// ReadingHistoryQuery adalah struct untuk binding query string pada riwayat baca.
+type ReadingHistoryQuery struct {
+	ComicID *int64 `form:"comic_id" binding:"omitempty,min=1"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset  int    `form:"offset" binding:"omitempty,min=0"`
+}
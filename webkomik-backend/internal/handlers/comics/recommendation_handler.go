@@ -0,0 +1,39 @@
+package comics
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRecommendationLimit adalah jumlah default komik yang direkomendasikan.
+const defaultRecommendationLimit = 20
+
+// RecommendationsHandler menampilkan rekomendasi komik untuk pengguna saat ini berdasarkan komik yang diikuti,
+// dibaca, dan dinilainya. Pengguna baru tanpa riwayat mendapat komik trending.
+func RecommendationsHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var query RecommendationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultRecommendationLimit
+	}
+
+	recs, err := database.ListRecommendations(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat mengambil rekomendasi: %v\nUserID: %s\n", err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil rekomendasi komik"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": recs})
+}
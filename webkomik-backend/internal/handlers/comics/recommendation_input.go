@@ -0,0 +1,6 @@
+package comics
+
+// RecommendationQuery adalah struct untuk binding query string pada rekomendasi komik.
+type RecommendationQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
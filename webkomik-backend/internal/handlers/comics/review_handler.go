@@ -0,0 +1,345 @@
+package comics
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetComicReviewsHandler menampilkan ulasan berteks yang tampil untuk publik pada sebuah komik.
+// Agregat rating tersedia di detail komik (rating_average dan rating_count).
+func GetComicReviewsHandler(c *gin.Context) {
+	comic, _, ok := loadVisibleComic(c)
+	if !ok {
+		return
+	}
+	var query ReviewListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	if query.Sort == "" {
+		query.Sort = database.ReviewSortNewest
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	reviews, total, err := database.ListComicReviews(c.Request.Context(), comic.ID, query.Sort, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil ulasan komik"})
+		return
+	}
+	// Jumlah laporan hanya untuk moderator
+	for i := range reviews {
+		reviews[i].ReportCount = 0
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": reviews,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(reviews)) < total,
+		},
+	})
+}
+
+// GetMyComicReviewHandler menampilkan rating dan ulasan pengguna saat ini untuk sebuah komik.
+func GetMyComicReviewHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	comic, ok := loadComic(c)
+	if !ok {
+		return
+	}
+
+	review, err := database.GetUserComicReview(c.Request.Context(), comic.ID, userID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil ulasan"})
+		return
+	}
+	if review == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anda belum memberi rating untuk komik ini"})
+		return
+	}
+	review.ReportCount = 0
+	c.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+// UpsertComicReviewHandler membuat atau mengubah rating dan ulasan pengguna saat ini untuk sebuah komik.
+// Hanya komik yang sudah dipublikasikan yang bisa diulas, dan anggota tim tidak bisa mengulas komiknya sendiri.
+func UpsertComicReviewHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	comic, ok := loadComic(c)
+	if !ok {
+		return
+	}
+	if comic.Status != models.PublicationPublished {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
+		return
+	}
+	members, err := database.GetComicMembers(c.Request.Context(), comic.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggota tim komik"})
+		return
+	}
+	comic.Members = members
+	if comic.MemberRole(userID) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anggota tim tidak dapat mengulas komiknya sendiri"})
+		return
+	}
+
+	var input ReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	// Ulasan kosong disimpan sebagai rating saja
+	if input.Body != nil {
+		trimmed := strings.TrimSpace(*input.Body)
+		input.Body = &trimmed
+		if trimmed == "" {
+			input.Body = nil
+		}
+	}
+
+	review, created, err := database.UpsertComicReview(c.Request.Context(), comic.ID, userID, input.Rating, input.Body)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menyimpan ulasan untuk komik ID %d: %v\nUserID: %s\n", comic.ID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan ulasan"})
+		return
+	}
+	review.ReportCount = 0
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"data": review})
+}
+
+// DeleteMyComicReviewHandler menghapus rating dan ulasan pengguna saat ini untuk sebuah komik.
+func DeleteMyComicReviewHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+		return
+	}
+
+	review, err := database.GetUserComicReview(c.Request.Context(), comicID, userID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil ulasan"})
+		return
+	}
+	if review == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anda belum memberi rating untuk komik ini"})
+		return
+	}
+	deleteReview(c, review.ID)
+}
+
+// DeleteComicReviewHandler menghapus ulasan pengguna lain beserta rating-nya, lalu memberi tahu penulisnya
+// lewat bus. Membutuhkan izin review:moderate.
+func DeleteComicReviewHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewID, ok := parseReviewID(c)
+		if !ok {
+			return
+		}
+		review, err := database.GetComicReview(c.Request.Context(), reviewID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil ulasan"})
+			return
+		}
+		if review == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ulasan tidak ditemukan"})
+			return
+		}
+		if deleteReview(c, reviewID) {
+			publishReviewModerated(c, bus, review, events.ModerationDeleted, nil)
+		}
+	}
+}
+
+// deleteReview menghapus ulasan lalu menulis response 204.
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func deleteReview(c *gin.Context, reviewID int64) bool {
+	found, err := database.DeleteComicReview(c.Request.Context(), reviewID)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menghapus ulasan ID %d: %v\n", reviewID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus ulasan"})
+		return false
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ulasan tidak ditemukan"})
+		return false
+	}
+	c.Status(http.StatusNoContent)
+	return true
+}
+
+// ReportComicReviewHandler mencatat laporan pengguna saat ini atas ulasan yang tidak pantas.
+func ReportComicReviewHandler(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	reviewID, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+	var input ReportInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+	}
+
+	found, err := database.ReportComicReview(c.Request.Context(), reviewID, userID, input.Reason)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan laporan"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ulasan tidak ditemukan"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListReviewModerationHandler menampilkan ulasan untuk moderator, yang paling banyak dilaporkan lebih dulu.
+// Membutuhkan izin review:moderate.
+func ListReviewModerationHandler(c *gin.Context) {
+	var query ReviewModerationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	reviews, total, err := database.ListReviewsForModeration(c.Request.Context(), query.Status, query.ReportedOnly, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil antrean moderasi ulasan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": reviews,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(reviews)) < total,
+		},
+	})
+}
+
+// HideComicReviewHandler menyembunyikan teks ulasan beserta alasannya; rating tetap dihitung.
+// Penulisnya mendapat notifikasi lewat bus. Membutuhkan izin review:moderate.
+func HideComicReviewHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewID, ok := parseReviewID(c)
+		if !ok {
+			return
+		}
+		var input ReviewDecisionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		if review := moderateReview(c, reviewID, true, &input.Reason); review != nil {
+			publishReviewModerated(c, bus, review, events.ModerationHidden, &input.Reason)
+		}
+	}
+}
+
+// RestoreComicReviewHandler menampilkan kembali ulasan yang disembunyikan, lalu memberi tahu penulisnya lewat bus.
+// Membutuhkan izin review:moderate.
+func RestoreComicReviewHandler(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewID, ok := parseReviewID(c)
+		if !ok {
+			return
+		}
+		if review := moderateReview(c, reviewID, false, nil); review != nil {
+			publishReviewModerated(c, bus, review, events.ModerationRestored, nil)
+		}
+	}
+}
+
+// moderateReview mengubah status moderasi ulasan lalu menulis ulasan terbaru sebagai response.
+// Mengembalikan ulasan tersebut, atau nil jika response error sudah ditulis.
+func moderateReview(c *gin.Context, reviewID int64, hide bool, note *string) *models.ComicReview {
+	userID := c.GetString("userID")
+	review, err := database.ModerateComicReview(c.Request.Context(), reviewID, hide, userID, note)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat memoderasi ulasan ID %d: %v\nUserID: %s\n", reviewID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memoderasi ulasan"})
+		return nil
+	}
+	if review == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ulasan tidak ditemukan"})
+		return nil
+	}
+	c.JSON(http.StatusOK, gin.H{"data": review})
+	return review
+}
+
+// publishReviewModerated mengirim event moderasi ulasan ke bus agar penulisnya mendapat notifikasi.
+// Tidak ada event jika bus nil atau moderator memoderasi ulasannya sendiri.
+func publishReviewModerated(c *gin.Context, bus *events.Bus, review *models.ComicReview, action string, reason *string) {
+	if bus == nil || review.UserID == c.GetString("userID") {
+		return
+	}
+	bus.Publish(c.Request.Context(), events.ContentModerated{
+		OwnerID:     review.UserID,
+		ContentType: events.ModeratedReview,
+		ContentID:   review.ID,
+		Action:      action,
+		Reason:      reason,
+		ComicID:     review.ComicID,
+	})
+}
+
+// parseReviewID membaca ID ulasan dari parameter URL ":id".
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func parseReviewID(c *gin.Context) (int64, bool) {
+	reviewID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID ulasan tidak valid"})
+		return 0, false
+	}
+	return reviewID, true
+}
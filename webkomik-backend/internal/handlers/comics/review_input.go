@@ -0,0 +1,28 @@
+package comics
+
+// ReviewInput adalah struct untuk validasi rating dan ulasan pengguna untuk sebuah komik.
+// body bersifat opsional sehingga pengguna bisa memberi rating tanpa menulis ulasan.
+type ReviewInput struct {
+	Rating int     `json:"rating" binding:"required,min=1,max=5"`
+	Body   *string `json:"body" binding:"omitempty,max=5000"`
+}
+
+// ReviewListQuery adalah struct untuk binding query string pada daftar ulasan sebuah komik.
+type ReviewListQuery struct {
+	Sort   string `form:"sort" binding:"omitempty,oneof=newest oldest highest lowest"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ReviewModerationQuery adalah struct untuk binding query string pada antrean moderasi ulasan.
+type ReviewModerationQuery struct {
+	Status       string `form:"status" binding:"omitempty,oneof=published hidden"`
+	ReportedOnly bool   `form:"reported"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset       int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ReportInput adalah struct untuk validasi laporan pembaca atas konten yang tidak pantas.
+type ReportInput struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
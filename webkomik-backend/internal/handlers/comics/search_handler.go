@@ -0,0 +1,47 @@
+package comics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchComicsHandler menangani pencarian komik berdasarkan judul, deskripsi, dan nama penulis.
+// Hasil diurutkan berdasarkan relevansi dan menyertakan cuplikan dengan kata kunci yang disorot.
+func SearchComicsHandler(c *gin.Context) {
+	var query SearchComicsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter pencarian tidak valid", "details": err.Error()})
+		return
+	}
+
+	term := strings.TrimSpace(query.Q)
+	if len(term) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kata kunci pencarian minimal 2 karakter"})
+		return
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultComicPageLimit
+	}
+
+	results, total, err := database.SearchComics(c.Request.Context(), term, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal melakukan pencarian komik"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": results,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(results)) < total,
+		},
+	})
+}
@@ -0,0 +1,105 @@
+package comics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteComicHandler memindahkan komik ke sampah (soft delete).
+// Komik di sampah tidak muncul di endpoint publik dan masih bisa dipulihkan oleh admin.
+// Membutuhkan izin comic:delete:any, atau comic:delete:own untuk owner komik.
+func DeleteComicHandler(c *gin.Context) {
+	comic, userID, ok := loadManagedComic(c, authz.ComicDeletePolicy)
+	if !ok {
+		return
+	}
+
+	deleted, err := database.SoftDeleteComic(c.Request.Context(), comic.ID)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat memindahkan komik ID %d ke sampah: %v\nUserID: %s\n", comic.ID, err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus komik"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListTrashedComicsHandler menampilkan daftar komik di sampah dengan filter dan paginasi yang sama seperti GET /comics.
+// Membutuhkan izin comic:trash:manage.
+func ListTrashedComicsHandler(c *gin.Context) {
+	respondComicList(c, func(params *database.ComicListParams) {
+		params.Trashed = true
+	})
+}
+
+// RestoreComicHandler memulihkan komik dari sampah. Membutuhkan izin comic:trash:manage.
+func RestoreComicHandler(c *gin.Context) {
+	comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+		return
+	}
+
+	restored, err := database.RestoreComic(c.Request.Context(), comicID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan komik"})
+		return
+	}
+	if !restored {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan di sampah"})
+		return
+	}
+
+	comic, err := database.GetComicByID(c.Request.Context(), comicID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil detail komik"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comic})
+}
+
+// PurgeComicHandler menghapus permanen komik dari sampah beserta chapter, halaman, dan file gambarnya di storage.
+// Membutuhkan izin comic:trash:manage.
+func PurgeComicHandler(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID komik tidak valid"})
+			return
+		}
+
+		found, imageURLs, err := database.PurgeComic(c.Request.Context(), comicID)
+		if err != nil {
+			if errors.Is(err, database.ErrComicNotTrashed) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Komik harus dipindahkan ke sampah sebelum dihapus permanen"})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus permanen komik"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Komik tidak ditemukan"})
+			return
+		}
+
+		// Data di database sudah terhapus; kegagalan menghapus file hanya dicatat agar bisa dibersihkan manual
+		deletePageImages(store, imageURLs)
+
+		c.Status(http.StatusNoContent)
+	}
+}
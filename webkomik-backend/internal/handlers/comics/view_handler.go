@@ -0,0 +1,95 @@
+package comics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRankingLimit adalah jumlah default komik pada peringkat trending dan sepanjang masa.
+const defaultRankingLimit = 20
+
+// RecordChapterViewHandler mencatat satu view chapter dari pengunjung saat ini. Pengunjung dikenali dari
+// userID jika login, atau dari hash alamat IP jika anonim, dan hanya dihitung sekali per chapter dalam jendela window.
+// View dari anggota tim yang melihat chapter belum rilis tidak dihitung.
+func RecordChapterViewHandler(window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID chapter tidak valid"})
+			return
+		}
+		if _, _, ok := loadVisibleChapterByID(c, chapterID); !ok {
+			return
+		}
+
+		counted, err := database.RecordChapterView(c.Request.Context(), chapterID, viewerKey(c), window)
+		if err != nil {
+			c.Error(err)
+			log.Printf("Error saat mencatat view chapter ID %d: %v\n", chapterID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencatat view chapter"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"counted": counted}})
+	}
+}
+
+// viewerKey mengembalikan identitas pengunjung untuk deduplikasi view. Alamat IP disimpan sebagai hash;
+// X-Forwarded-For hanya dipakai jika koneksi berasal dari proxy di TRUSTED_PROXIES.
+func viewerKey(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "u:" + userID
+	}
+	sum := sha256.Sum256([]byte(c.ClientIP()))
+	return "ip:" + hex.EncodeToString(sum[:16])
+}
+
+// TrendingComicsHandler menampilkan komik dengan view terbanyak dalam periode daily (24 jam), weekly (7 hari),
+// atau monthly (30 hari) terakhir. Peringkat dihitung ulang berkala oleh scheduler, bukan per request.
+func TrendingComicsHandler(c *gin.Context) {
+	var query TrendingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	period := query.Period
+	if period == "" {
+		period = models.TrendingWeekly
+	}
+	respondRanking(c, period, query.Limit)
+}
+
+// TopComicsHandler menampilkan komik dengan view terbanyak sepanjang masa.
+func TopComicsHandler(c *gin.Context) {
+	var query TopComicsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	respondRanking(c, models.TrendingAllTime, query.Limit)
+}
+
+// respondRanking menulis peringkat komik untuk period sebagai response.
+func respondRanking(c *gin.Context, period string, limit int) {
+	if limit == 0 {
+		limit = defaultRankingLimit
+	}
+	comics, computedAt, err := database.ListTrendingComics(c.Request.Context(), period, limit)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil peringkat komik"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        comics,
+		"period":      period,
+		"computed_at": computedAt,
+	})
+}
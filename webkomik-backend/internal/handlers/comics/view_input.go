@@ -0,0 +1,13 @@
+package comics
+
+// TrendingQuery adalah struct untuk binding query string pada peringkat trending.
+// period kosong berarti weekly.
+type TrendingQuery struct {
+	Period string `form:"period" binding:"omitempty,oneof=daily weekly monthly"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// TopComicsQuery adalah struct untuk binding query string pada peringkat sepanjang masa.
+type TopComicsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
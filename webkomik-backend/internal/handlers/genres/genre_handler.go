@@ -0,0 +1,140 @@
+package genres
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/slug"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAllGenresHandler menampilkan seluruh genre beserta jumlah komik aktif di setiap genre.
+func GetAllGenresHandler(c *gin.Context) {
+	genres, err := database.ListGenres(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data genre"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": genres})
+}
+
+// CreateGenreHandler membuat genre baru. Membutuhkan izin genre:manage.
+func CreateGenreHandler(c *gin.Context) {
+	var input CreateGenreInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	genreSlug := input.Slug
+	if genreSlug == "" {
+		genreSlug = input.Name
+	}
+	genreSlug = slug.Make(genreSlug)
+	if genreSlug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug genre tidak boleh kosong, gunakan huruf atau angka"})
+		return
+	}
+
+	genre, err := database.CreateGenre(c.Request.Context(), models.Genre{
+		Name:         input.Name,
+		Slug:         genreSlug,
+		Description:  input.Description,
+		DisplayOrder: input.DisplayOrder,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateGenreSlug) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug genre sudah dipakai"})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat membuat genre: %v\nInput: %+v\n", err, input)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan genre baru"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": genre})
+}
+
+// UpdateGenreHandler memperbarui sebagian data genre. Membutuhkan izin genre:manage.
+func UpdateGenreHandler(c *gin.Context) {
+	genreID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID genre tidak valid"})
+		return
+	}
+
+	var input UpdateGenreInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if input.Name != nil {
+		updates["name"] = *input.Name
+	}
+	if input.Slug != nil {
+		genreSlug := slug.Make(*input.Slug)
+		if genreSlug == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Slug genre tidak boleh kosong, gunakan huruf atau angka"})
+			return
+		}
+		updates["slug"] = genreSlug
+	}
+	if input.Description != nil {
+		updates["description"] = input.Description
+	}
+	if input.DisplayOrder != nil {
+		updates["display_order"] = *input.DisplayOrder
+	}
+
+	genre, err := database.UpdateGenre(c.Request.Context(), genreID, updates)
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateGenreSlug) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug genre sudah dipakai"})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat memperbarui genre ID %d: %v\nInput: %+v\n", genreID, err, input)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui genre"})
+		return
+	}
+	if genre == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Genre tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": genre})
+}
+
+// DeleteGenreHandler menghapus genre yang sudah tidak dipakai oleh komik mana pun. Membutuhkan izin genre:manage.
+func DeleteGenreHandler(c *gin.Context) {
+	genreID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID genre tidak valid"})
+		return
+	}
+
+	deleted, err := database.DeleteGenre(c.Request.Context(), genreID)
+	if err != nil {
+		if errors.Is(err, database.ErrGenreInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Genre masih dipakai oleh komik, lepaskan genre dari komik terlebih dahulu"})
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus genre"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Genre tidak ditemukan"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,18 @@
+package genres
+
+// CreateGenreInput adalah struct untuk validasi input saat membuat genre baru.
+// Jika slug kosong, slug dibentuk otomatis dari nama.
+type CreateGenreInput struct {
+	Name         string  `json:"name" binding:"required,min=2,max=50"`
+	Slug         string  `json:"slug" binding:"omitempty,max=60"`
+	Description  *string `json:"description" binding:"omitempty,max=1000"`
+	DisplayOrder int     `json:"display_order"`
+}
+
+// UpdateGenreInput adalah struct untuk validasi input saat memperbarui genre. Semua field opsional.
+type UpdateGenreInput struct {
+	Name         *string `json:"name" binding:"omitempty,min=2,max=50"`
+	Slug         *string `json:"slug" binding:"omitempty,max=60"`
+	Description  *string `json:"description" binding:"omitempty,max=1000"`
+	DisplayOrder *int    `json:"display_order"`
+}
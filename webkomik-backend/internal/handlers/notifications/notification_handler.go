@@ -0,0 +1,186 @@
+package notifications
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultNotificationLimit adalah jumlah default notifikasi per halaman.
+const defaultNotificationLimit = 20
+
+// ListNotificationsHandler menampilkan notifikasi pengguna saat ini, yang terbaru lebih dulu.
+func ListNotificationsHandler(c *gin.Context) {
+	var query NotificationListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultNotificationLimit
+	}
+
+	notifications, total, err := database.ListNotifications(c.Request.Context(), c.GetString("userID"), query.UnreadOnly, limit, query.Offset)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil notifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": notifications,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   query.Offset,
+			"has_more": int64(query.Offset+len(notifications)) < total,
+		},
+	})
+}
+
+// UnreadCountHandler menampilkan jumlah notifikasi pengguna saat ini yang belum dibaca.
+func UnreadCountHandler(c *gin.Context) {
+	count, err := database.CountUnreadNotifications(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung notifikasi belum dibaca"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"unread_count": count}})
+}
+
+// MarkReadHandler menandai notifikasi tertentu, atau semua notifikasi jika body kosong, sudah dibaca.
+func MarkReadHandler(c *gin.Context) {
+	var input MarkReadInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+	}
+
+	userID := c.GetString("userID")
+	updated, err := database.MarkNotificationsRead(c.Request.Context(), userID, input.IDs)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menandai notifikasi dibaca: %v\nUserID: %s\n", err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menandai notifikasi dibaca"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"updated": updated}})
+}
+
+// GetPreferencesHandler menampilkan preferensi kanal notifikasi pengguna saat ini.
+func GetPreferencesHandler(c *gin.Context) {
+	prefs, err := database.GetNotificationPreferences(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil preferensi notifikasi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": prefs})
+}
+
+// UpdatePreferencesHandler mengubah preferensi kanal notifikasi pengguna saat ini.
+func UpdatePreferencesHandler(c *gin.Context) {
+	var input PreferencesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	userID := c.GetString("userID")
+	prefs, err := database.UpdateNotificationPreferences(c.Request.Context(), userID, input.EmailEnabled, input.PushEnabled)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat menyimpan preferensi notifikasi: %v\nUserID: %s\n", err, userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan preferensi notifikasi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": prefs})
+}
+
+// PushPublicKeyHandler menampilkan kunci publik VAPID untuk applicationServerKey saat browser berlangganan.
+func PushPublicKeyHandler(push *notify.WebPushChannel) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if push == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Web push tidak diaktifkan di server ini"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"public_key": push.PublicKey()}})
+	}
+}
+
+// SubscribePushHandler menyimpan langganan Web Push dari browser pengguna saat ini.
+func SubscribePushHandler(push *notify.WebPushChannel) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if push == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Web push tidak diaktifkan di server ini"})
+			return
+		}
+		var input PushSubscriptionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		if err := notify.ValidateEndpoint(input.Endpoint); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Endpoint push tidak valid", "details": err.Error()})
+			return
+		}
+		if err := notify.ValidateSubscription(input.Keys.P256DH, input.Keys.Auth); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Langganan push tidak valid", "details": err.Error()})
+			return
+		}
+
+		userID := c.GetString("userID")
+		sub := models.PushSubscription{
+			UserID:   userID,
+			Endpoint: input.Endpoint,
+			P256DH:   input.Keys.P256DH,
+			Auth:     input.Keys.Auth,
+		}
+		if ua := c.Request.UserAgent(); ua != "" {
+			sub.UserAgent = &ua
+		}
+		saved, err := database.SavePushSubscription(c.Request.Context(), sub)
+		if errors.Is(err, database.ErrPushEndpointTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Endpoint push sudah terdaftar untuk akun lain"})
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			log.Printf("Error saat menyimpan langganan push: %v\nUserID: %s\n", err, userID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan langganan push"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": saved})
+	}
+}
+
+// UnsubscribePushHandler menghapus langganan Web Push milik pengguna saat ini.
+func UnsubscribePushHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID langganan tidak valid"})
+		return
+	}
+
+	found, err := database.DeletePushSubscription(c.Request.Context(), c.GetString("userID"), id)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus langganan push"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Langganan push tidak ditemukan"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
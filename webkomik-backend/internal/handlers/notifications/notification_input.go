@@ -0,0 +1,30 @@
+package notifications
+
+// NotificationListQuery adalah struct untuk binding query string pada daftar notifikasi.
+type NotificationListQuery struct {
+	UnreadOnly bool `form:"unread_only"`
+	Limit      int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     int  `form:"offset" binding:"omitempty,min=0"`
+}
+
+// MarkReadInput adalah struct untuk validasi input saat menandai notifikasi dibaca.
+// ids kosong berarti semua notifikasi yang belum dibaca.
+type MarkReadInput struct {
+	IDs []int64 `json:"ids" binding:"omitempty,max=100,dive,min=1"`
+}
+
+// PreferencesInput adalah struct untuk validasi input saat mengubah preferensi notifikasi. Semua field opsional.
+type PreferencesInput struct {
+	EmailEnabled *bool `json:"email_enabled"`
+	PushEnabled  *bool `json:"push_enabled"`
+}
+
+// PushSubscriptionInput adalah struct untuk validasi langganan Web Push, sesuai hasil
+// PushSubscription.toJSON() di browser.
+type PushSubscriptionInput struct {
+	Endpoint string `json:"endpoint" binding:"required,url,startswith=https://,max=2048"`
+	Keys     struct {
+		P256DH string `json:"p256dh" binding:"required,max=200"`
+		Auth   string `json:"auth" binding:"required,max=100"`
+	} `json:"keys" binding:"required"`
+}
@@ -0,0 +1,157 @@
+package notifications
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/middleware"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/notify"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// heartbeatInterval adalah jeda komentar SSE kosong yang dikirim agar proxy tidak memutus koneksi yang diam.
+	heartbeatInterval = 25 * time.Second
+	// reconnectDelay adalah jeda yang disarankan ke browser sebelum menyambung ulang, dalam milidetik.
+	reconnectDelay = 3000
+	// replayLimit adalah jumlah maksimum notifikasi terlewat yang dikirim ulang saat klien tersambung ulang.
+	// Klien yang tertinggal lebih jauh mengambil sisanya lewat daftar notifikasi biasa.
+	replayLimit = 100
+)
+
+// IssueStreamTicketHandler menerbitkan tiket untuk membuka stream notifikasi dari EventSource, yang tidak bisa
+// mengirim header Authorization. Tiket yang sama dipakai EventSource saat tersambung ulang.
+func IssueStreamTicketHandler(tickets *notify.StreamTickets) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, err := tickets.Issue(c.GetString("userID"))
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat tiket stream"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": gin.H{
+			"ticket":     ticket,
+			"expires_in": int(notify.StreamTicketTTL.Seconds()),
+		}})
+	}
+}
+
+// StreamHandler membuka stream Server-Sent Events berisi notifikasi baru untuk pengguna saat ini.
+// Pengguna dikenali dari header Authorization atau dari query ticket yang diterbitkan IssueStreamTicketHandler.
+// ID setiap event adalah ID notifikasi, sehingga klien yang tersambung ulang dengan Last-Event-ID
+// menerima notifikasi yang terlewat selama terputus.
+func StreamHandler(hub *notify.Hub, tickets *notify.StreamTickets) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		if userID == "" {
+			if ticket := c.Query("ticket"); ticket != "" {
+				var ok bool
+				if userID, ok = tickets.Redeem(ticket); ok {
+					defer tickets.Release(ticket)
+				}
+			}
+			if userID == "" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Login atau tiket stream diperlukan"})
+				return
+			}
+			// Tiket bisa dipakai ulang saat tersambung ulang, jadi status akun diperiksa lagi setiap kali
+			c.Set("userID", userID)
+			if !middleware.CheckAccountStatus(c) {
+				return
+			}
+		}
+
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+		var lastID int64
+		if lastEventID != "" {
+			var err error
+			if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || lastID < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Last-Event-ID tidak valid"})
+				return
+			}
+		}
+
+		// Berlangganan sebelum mengambil notifikasi terlewat agar tidak ada notifikasi yang jatuh di antara keduanya;
+		// notifikasi yang muncul di kedua sumber disaring berdasarkan ID.
+		sub, err := hub.Subscribe(userID)
+		if errors.Is(err, notify.ErrTooManyStreams) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Terlalu banyak stream notifikasi yang terbuka"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Stream notifikasi tidak tersedia"})
+			return
+		}
+		defer hub.Unsubscribe(sub)
+
+		ctx := c.Request.Context()
+		var missed []models.Notification
+		if lastEventID != "" {
+			if missed, err = database.ListNotificationsAfter(ctx, userID, lastID, replayLimit); err != nil {
+				c.Error(err)
+				log.Printf("Error saat mengambil notifikasi terlewat: %v\nUserID: %s\n", err, userID)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil notifikasi terlewat"})
+				return
+			}
+		}
+		unread, err := database.CountUnreadNotifications(ctx, userID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung notifikasi belum dibaca"})
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // Matikan buffering respons di nginx
+		c.Status(http.StatusOK)
+
+		c.Render(-1, sse.Event{Event: "ready", Retry: reconnectDelay, Data: gin.H{"unread_count": unread}})
+		for _, n := range missed {
+			writeNotification(c, n)
+			lastID = n.ID
+		}
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n, ok := <-sub.C():
+				if !ok {
+					// Dilepas oleh hub (server berhenti atau klien terlalu lambat); klien akan menyambung ulang
+					return
+				}
+				if n.ID <= lastID {
+					continue
+				}
+				writeNotification(c, n)
+				lastID = n.ID
+				c.Writer.Flush()
+			case <-heartbeat.C:
+				if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
+// writeNotification menulis satu notifikasi sebagai event SSE dengan nama sesuai jenis notifikasinya.
+func writeNotification(c *gin.Context, n models.Notification) {
+	c.Render(-1, sse.Event{Id: strconv.FormatInt(n.ID, 10), Event: n.Type, Data: n})
+}
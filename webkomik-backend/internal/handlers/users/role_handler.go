@@ -0,0 +1,72 @@
+package users
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRolePermissionsHandler menampilkan seluruh izin yang tersedia dan pemetaan peran ke izinnya.
+// Membutuhkan izin role:manage.
+func GetRolePermissionsHandler(c *gin.Context) {
+	permissions, err := database.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar izin"})
+		return
+	}
+	roles, err := database.GetRolePermissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil izin peran"})
+		return
+	}
+
+	// Pastikan semua peran muncul walaupun belum memiliki izin
+	for _, role := range []string{middleware.RoleUser, middleware.RoleCreator, middleware.RoleAdmin} {
+		if roles[role] == nil {
+			roles[role] = []string{}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"permissions": permissions, "roles": roles}})
+}
+
+// UpdateRolePermissionsHandler mengganti seluruh izin sebuah peran. Membutuhkan izin role:manage.
+func UpdateRolePermissionsHandler(c *gin.Context) {
+	role := c.Param("role")
+	if !middleware.IsValidRole(role) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Peran tidak ditemukan"})
+		return
+	}
+	var input UpdateRolePermissionsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	// Cegah admin kehilangan akses untuk memperbaiki pemetaan izin
+	if role == middleware.RoleAdmin && !slices.Contains(input.Permissions, string(authz.RoleManage)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Peran admin harus tetap memiliki izin role:manage"})
+		return
+	}
+
+	if err := database.SetRolePermissions(c.Request.Context(), role, input.Permissions); err != nil {
+		if errors.Is(err, database.ErrUnknownPermission) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Izin tidak dikenal", "details": err.Error()})
+			return
+		}
+		c.Error(err)
+		log.Printf("Error saat memperbarui izin peran %s: %v\nInput: %+v\n", role, err, input)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui izin peran"})
+		return
+	}
+	authz.Invalidate()
+
+	GetRolePermissionsHandler(c)
+}
@@ -0,0 +1,280 @@
+package users
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/identity"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Batas default dan maksimum jumlah data per halaman listing.
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// GetAllUsersHandler menampilkan daftar pengguna dengan pencarian, filter peran/status, dan paginasi.
+// Membutuhkan izin user:read.
+func GetAllUsersHandler(c *gin.Context) {
+	var query ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+	params := database.UserListParams{
+		Limit:  pageLimit(query.Limit),
+		Offset: query.Offset,
+		Query:  query.Q,
+		Role:   query.Role,
+		Status: query.Status,
+	}
+
+	users, total, err := database.ListUsers(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": users,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    params.Limit,
+			"offset":   params.Offset,
+			"has_more": int64(params.Offset+len(users)) < total,
+		},
+	})
+}
+
+// GetUserDetailHandler menampilkan detail satu pengguna. Membutuhkan izin user:read.
+func GetUserDetailHandler(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// GetUserComicsHandler menampilkan komik aktif yang timnya diikuti seorang pengguna, terbaru lebih dulu.
+// Membutuhkan izin user:read.
+func GetUserComicsHandler(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok {
+		return
+	}
+	var query UserComicsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter query tidak valid", "details": err.Error()})
+		return
+	}
+
+	params := database.ComicListParams{
+		Limit:    pageLimit(query.Limit),
+		Offset:   query.Offset,
+		MemberID: &user.ID,
+		Sort:     database.ComicSortCreatedAt,
+		Desc:     true,
+	}
+	result, err := database.ListComics(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komik pengguna"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result.Comics,
+		"pagination": gin.H{
+			"total":    result.Total,
+			"limit":    params.Limit,
+			"offset":   params.Offset,
+			"has_more": result.HasMore,
+		},
+	})
+}
+
+// UpdateUserRoleHandler mengubah peran pengguna (user, creator, admin). Peran juga diteruskan ke
+// app_metadata di identity provider agar token berikutnya membawa peran yang sama.
+// Membutuhkan izin user:role:update.
+func UpdateUserRoleHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadTargetUser(c)
+		if !ok {
+			return
+		}
+		var input UpdateRoleInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		if input.Role == user.Role {
+			c.JSON(http.StatusOK, gin.H{"data": user, "message": "Tidak ada perubahan yang dilakukan"})
+			return
+		}
+
+		// Identity provider diperbarui lebih dulu; jika gagal, data lokal tidak diubah
+		if err := idp.UpdateAppMetadata(c.Request.Context(), user.ID, map[string]interface{}{"role": input.Role}); err != nil {
+			c.Error(err)
+			log.Printf("Error saat meneruskan peran pengguna %s ke identity provider: %v\n", user.ID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal memperbarui peran di identity provider"})
+			return
+		}
+		updated, err := database.UpdateUserRole(c.Request.Context(), user.ID, input.Role)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui peran pengguna"})
+			return
+		}
+		if updated == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": updated})
+	}
+}
+
+// SuspendUserHandler memblokir pengguna sementara sampai waktu "until". Membutuhkan izin user:ban.
+func SuspendUserHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadTargetUser(c)
+		if !ok {
+			return
+		}
+		var input SuspendUserInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+			return
+		}
+		if !input.Until.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Waktu 'until' harus di masa depan"})
+			return
+		}
+
+		if err := idp.Ban(c.Request.Context(), user.ID, &input.Until); err != nil {
+			c.Error(err)
+			log.Printf("Error saat meneruskan suspend pengguna %s ke identity provider: %v\n", user.ID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal memblokir pengguna di identity provider"})
+			return
+		}
+		respondStatusChange(c, user.ID, models.UserStatusSuspended, &input.Until, input.Reason)
+	}
+}
+
+// BanUserHandler memblokir pengguna tanpa batas waktu. Membutuhkan izin user:ban.
+func BanUserHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadTargetUser(c)
+		if !ok {
+			return
+		}
+		var input BanUserInput
+		// Body opsional, alasan boleh tidak diisi
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+				return
+			}
+		}
+
+		if err := idp.Ban(c.Request.Context(), user.ID, nil); err != nil {
+			c.Error(err)
+			log.Printf("Error saat meneruskan ban pengguna %s ke identity provider: %v\n", user.ID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal memblokir pengguna di identity provider"})
+			return
+		}
+		respondStatusChange(c, user.ID, models.UserStatusBanned, nil, input.Reason)
+	}
+}
+
+// UnbanUserHandler mencabut suspend atau ban pengguna. Membutuhkan izin user:ban.
+func UnbanUserHandler(idp identity.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadTargetUser(c)
+		if !ok {
+			return
+		}
+
+		if err := idp.Unban(c.Request.Context(), user.ID); err != nil {
+			c.Error(err)
+			log.Printf("Error saat meneruskan pencabutan blokir pengguna %s ke identity provider: %v\n", user.ID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal mencabut blokir pengguna di identity provider"})
+			return
+		}
+		respondStatusChange(c, user.ID, models.UserStatusActive, nil, nil)
+	}
+}
+
+// respondStatusChange menyimpan status akun baru lalu menulis response berisi data pengguna.
+func respondStatusChange(c *gin.Context, userID, status string, until *time.Time, reason *string) {
+	updated, err := database.UpdateUserStatus(c.Request.Context(), userID, status, until, reason)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui status pengguna"})
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// loadUser mengambil pengguna dari parameter URL ":id".
+// Jika gagal, response error sudah ditulis dan fungsi mengembalikan false.
+func loadUser(c *gin.Context) (*models.User, bool) {
+	var uri UserURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID pengguna tidak valid"})
+		return nil, false
+	}
+	user, err := database.GetUserByID(c.Request.Context(), uri.ID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
+		return nil, false
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return nil, false
+	}
+	return user, true
+}
+
+// loadTargetUser seperti loadUser, tetapi menolak jika admin mencoba mengubah akunnya sendiri
+// agar admin tidak bisa mengunci dirinya keluar.
+func loadTargetUser(c *gin.Context) (*models.User, bool) {
+	user, ok := loadUser(c)
+	if !ok {
+		return nil, false
+	}
+	if sameUUID(user.ID, c.GetString("userID")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Anda tidak dapat mengubah peran atau status akun Anda sendiri"})
+		return nil, false
+	}
+	return user, true
+}
+
+// sameUUID membandingkan dua UUID berdasarkan nilainya, bukan teksnya, sehingga perbedaan huruf kapital
+// atau format tanpa tanda hubung tidak lolos pemeriksaan. UUID yang tidak valid dianggap berbeda.
+func sameUUID(a, b string) bool {
+	var x, y pgtype.UUID
+	if x.Scan(a) != nil || y.Scan(b) != nil {
+		return false
+	}
+	return x.Bytes == y.Bytes
+}
+
+// pageLimit menerapkan limit default dan maksimum.
+func pageLimit(limit int) int {
+	if limit == 0 {
+		return defaultPageLimit
+	}
+	return min(limit, maxPageLimit)
+}
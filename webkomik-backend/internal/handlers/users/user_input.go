@@ -0,0 +1,44 @@
+package users
+
+import "time"
+
+// UserURI adalah struct untuk binding parameter URL ":id" pada /api/users/:id.
+type UserURI struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// ListUsersQuery adalah struct untuk binding query string pada GET /api/users.
+type ListUsersQuery struct {
+	Q      string `form:"q" binding:"omitempty,max=100"` // Cari berdasarkan email atau ID
+	Role   string `form:"role" binding:"omitempty,oneof=user creator admin"`
+	Status string `form:"status" binding:"omitempty,oneof=active suspended banned"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// UserComicsQuery adalah struct untuk binding query string pada GET /api/users/:id/comics.
+type UserComicsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// UpdateRoleInput adalah struct untuk validasi input perubahan peran pengguna.
+type UpdateRoleInput struct {
+	Role string `json:"role" binding:"required,oneof=user creator admin"`
+}
+
+// SuspendUserInput adalah struct untuk validasi input suspend pengguna sampai waktu tertentu.
+type SuspendUserInput struct {
+	Until  time.Time `json:"until" binding:"required"` // Format RFC3339
+	Reason *string   `json:"reason" binding:"omitempty,max=500"`
+}
+
+// BanUserInput adalah struct untuk validasi input ban pengguna tanpa batas waktu.
+type BanUserInput struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// UpdateRolePermissionsInput adalah struct untuk validasi input penggantian izin sebuah peran.
+type UpdateRolePermissionsInput struct {
+	Permissions []string `json:"permissions" binding:"required,dive,min=1"`
+}
@@ -0,0 +1,124 @@
+package identity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// permanentBanDuration dipakai untuk ban tanpa batas waktu karena Supabase hanya menerima durasi.
+const permanentBanDuration = "876000h" // ~100 tahun
+
+// SupabaseProvider memanggil Admin API Supabase Auth (GoTrue) dengan service role key.
+type SupabaseProvider struct {
+	baseURL    string
+	serviceKey string
+	client     *http.Client
+}
+
+// NewSupabaseProvider membuat Provider untuk project Supabase di projectURL.
+func NewSupabaseProvider(projectURL, serviceKey string) *SupabaseProvider {
+	return &SupabaseProvider{
+		baseURL:    strings.TrimRight(projectURL, "/") + "/auth/v1",
+		serviceKey: serviceKey,
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// UpdateAppMetadata memperbarui app_metadata pengguna. Supabase menggabungkan key baru dengan key yang sudah ada.
+func (p *SupabaseProvider) UpdateAppMetadata(ctx context.Context, userID string, metadata map[string]interface{}) error {
+	return p.updateUser(ctx, userID, map[string]interface{}{"app_metadata": metadata})
+}
+
+// Ban mengatur ban_duration pengguna. Supabase hanya menerima durasi, sehingga until diubah
+// menjadi sisa waktu dari sekarang (dibulatkan ke atas per detik).
+func (p *SupabaseProvider) Ban(ctx context.Context, userID string, until *time.Time) error {
+	duration := permanentBanDuration
+	if until != nil {
+		d := time.Until(*until)
+		if d <= 0 {
+			return p.Unban(ctx, userID)
+		}
+		duration = fmt.Sprintf("%ds", int64(d.Seconds())+1)
+	}
+	return p.updateUser(ctx, userID, map[string]interface{}{"ban_duration": duration})
+}
+
+// Unban mencabut blokir pengguna.
+func (p *SupabaseProvider) Unban(ctx context.Context, userID string) error {
+	return p.updateUser(ctx, userID, map[string]interface{}{"ban_duration": "none"})
+}
+
+// ConfirmedEmail membaca pengguna lewat GET /admin/users/{id} dan mengembalikan email-nya jika
+// email_confirmed_at sudah terisi.
+func (p *SupabaseProvider) ConfirmedEmail(ctx context.Context, userID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userURL(userID), nil)
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat request identity provider: %w", err)
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var user struct {
+		Email            string     `json:"email"`
+		EmailConfirmedAt *time.Time `json:"email_confirmed_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("gagal membaca data pengguna %s dari identity provider: %w", userID, err)
+	}
+	if user.EmailConfirmedAt == nil {
+		return "", nil
+	}
+	return user.Email, nil
+}
+
+// updateUser mengirim PUT /admin/users/{id} dengan body yang diberikan.
+func (p *SupabaseProvider) updateUser(ctx context.Context, userID string, body map[string]interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("gagal menyusun request identity provider: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.userURL(userID), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("gagal membuat request identity provider: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// userURL mengembalikan endpoint Admin API untuk satu pengguna.
+func (p *SupabaseProvider) userURL(userID string) string {
+	return p.baseURL + "/admin/users/" + url.PathEscape(userID)
+}
+
+// do mengirim request dengan service role key. Respons di luar 2xx dikembalikan sebagai error.
+func (p *SupabaseProvider) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+p.serviceKey)
+	req.Header.Set("apikey", p.serviceKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghubungi identity provider: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("identity provider menolak request %s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
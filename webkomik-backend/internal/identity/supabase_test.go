@@ -0,0 +1,170 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// adminRequest adalah request yang diterima fakeAdminAPI.
+type adminRequest struct {
+	method string
+	path   string
+	auth   string
+	apikey string
+	body   map[string]interface{}
+}
+
+// fakeAdminAPI menjalankan Admin API Supabase Auth palsu yang mencatat setiap request dan
+// membalas dengan status dan body yang diberikan.
+func fakeAdminAPI(t *testing.T, status int, response string) (*SupabaseProvider, *[]adminRequest) {
+	t.Helper()
+	var requests []adminRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := adminRequest{
+			method: r.Method,
+			path:   r.URL.EscapedPath(),
+			auth:   r.Header.Get("Authorization"),
+			apikey: r.Header.Get("apikey"),
+		}
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(&req.body); err != nil {
+				t.Errorf("body request bukan JSON: %v", err)
+			}
+		}
+		requests = append(requests, req)
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return NewSupabaseProvider(srv.URL+"/", "service-key"), &requests
+}
+
+func TestSupabaseProviderUpdateAppMetadata(t *testing.T) {
+	p, requests := fakeAdminAPI(t, http.StatusOK, "{}")
+	if err := p.UpdateAppMetadata(context.Background(), "user/1", map[string]interface{}{"role": "creator"}); err != nil {
+		t.Fatalf("UpdateAppMetadata: %v", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("jumlah request = %d, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+	if req.method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", req.method)
+	}
+	if req.path != "/auth/v1/admin/users/user%2F1" {
+		t.Errorf("path = %s, want ID pengguna di-escape", req.path)
+	}
+	if req.auth != "Bearer service-key" || req.apikey != "service-key" {
+		t.Errorf("header otentikasi = %q / %q", req.auth, req.apikey)
+	}
+	meta, _ := req.body["app_metadata"].(map[string]interface{})
+	if meta["role"] != "creator" {
+		t.Errorf("body = %v, want app_metadata.role creator", req.body)
+	}
+}
+
+func TestSupabaseProviderBan(t *testing.T) {
+	future := time.Now().Add(90 * time.Minute)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		until *time.Time
+		check func(t *testing.T, duration string)
+	}{
+		{"permanen", nil, func(t *testing.T, d string) {
+			if d != permanentBanDuration {
+				t.Errorf("ban_duration = %q, want %q", d, permanentBanDuration)
+			}
+		}},
+		{"sementara", &future, func(t *testing.T, d string) {
+			got, err := time.ParseDuration(d)
+			if err != nil {
+				t.Fatalf("ban_duration %q tidak valid: %v", d, err)
+			}
+			if got < 90*time.Minute-time.Minute || got > 90*time.Minute+time.Second {
+				t.Errorf("ban_duration = %s, want sekitar 90 menit", got)
+			}
+		}},
+		{"sudah lewat dianggap unban", &past, func(t *testing.T, d string) {
+			if d != "none" {
+				t.Errorf("ban_duration = %q, want none", d)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, requests := fakeAdminAPI(t, http.StatusOK, "{}")
+			if err := p.Ban(context.Background(), "user-1", tt.until); err != nil {
+				t.Fatalf("Ban: %v", err)
+			}
+			if len(*requests) != 1 {
+				t.Fatalf("jumlah request = %d, want 1", len(*requests))
+			}
+			d, _ := (*requests)[0].body["ban_duration"].(string)
+			tt.check(t, d)
+		})
+	}
+}
+
+func TestSupabaseProviderUnban(t *testing.T) {
+	p, requests := fakeAdminAPI(t, http.StatusOK, "{}")
+	if err := p.Unban(context.Background(), "user-1"); err != nil {
+		t.Fatalf("Unban: %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].body["ban_duration"] != "none" {
+		t.Errorf("requests = %+v, want satu request ban_duration none", *requests)
+	}
+}
+
+func TestSupabaseProviderRejected(t *testing.T) {
+	p, _ := fakeAdminAPI(t, http.StatusNotFound, `{"msg":"User not found"}`)
+	err := p.Unban(context.Background(), "user-1")
+	if err == nil {
+		t.Fatal("Unban seharusnya gagal saat identity provider menolak")
+	}
+	for _, want := range []string{"user-1", "404", "User not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q tidak memuat %q", err, want)
+		}
+	}
+}
+
+func TestSupabaseProviderConfirmedEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"sudah dikonfirmasi", `{"email":"a@example.com","email_confirmed_at":"2024-01-02T03:04:05Z","user_metadata":{"email_verified":true}}`, "a@example.com"},
+		{"belum dikonfirmasi walaupun user_metadata mengaku terverifikasi", `{"email":"a@example.com","email_confirmed_at":null,"user_metadata":{"email_verified":true}}`, ""},
+		{"tanpa email", `{"phone":"62812"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, requests := fakeAdminAPI(t, http.StatusOK, tt.response)
+			got, err := p.ConfirmedEmail(context.Background(), "user-1")
+			if err != nil {
+				t.Fatalf("ConfirmedEmail: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ConfirmedEmail = %q, want %q", got, tt.want)
+			}
+			req := (*requests)[0]
+			if req.method != http.MethodGet || req.path != "/auth/v1/admin/users/user-1" || req.auth != "Bearer service-key" {
+				t.Errorf("request = %+v", req)
+			}
+		})
+	}
+
+	p, _ := fakeAdminAPI(t, http.StatusInternalServerError, "down")
+	if _, err := p.ConfirmedEmail(context.Background(), "user-1"); err == nil {
+		t.Error("ConfirmedEmail seharusnya gagal saat identity provider error")
+	}
+}
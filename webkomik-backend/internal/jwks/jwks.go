@@ -0,0 +1,264 @@
+// Package jwks memuat dan menyimpan cache JSON Web Key Set (RFC 7517) untuk verifikasi JWT
+// yang ditandatangani secara asimetris (RS256, ES256, dll). Key diambil dari URL JWKS atau file lokal
+// dan diperbarui secara berkala sehingga rotasi key di identity provider ikut terbaca.
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Batas waktu pengambilan ulang key.
+const (
+	DefaultRefreshInterval = time.Hour
+	minRefetchInterval     = time.Minute // Jeda minimal refetch karena kid tidak dikenal, mencegah banjir request
+	maxJWKSSize            = 1 << 20
+)
+
+// ErrKeyNotFound dikembalikan jika kid pada token tidak ada di key set.
+var ErrKeyNotFound = errors.New("key dengan kid tersebut tidak ditemukan di JWKS")
+
+// KeySet adalah cache key publik dari satu sumber JWKS. Aman dipakai bersamaan dari banyak goroutine.
+type KeySet struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	group singleflight.Group // Menggabungkan pengambilan ulang yang bersamaan
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewKeySet membuat KeySet dari source berupa URL http(s) atau path file JWKS.
+// Key belum dimuat sampai pertama kali dibutuhkan.
+func NewKeySet(source string, refreshInterval time.Duration) *KeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	return &KeySet{
+		source:          source,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key mengembalikan key publik untuk kid. Jika kid kosong dan key set hanya berisi satu key, key itu yang dipakai.
+// Cache dimuat ulang ketika sudah kedaluwarsa atau ketika kid tidak dikenal (kemungkinan key baru hasil rotasi),
+// paling sering sekali per minRefetchInterval kecuali cache masih kosong. Pengambilan dilakukan di luar lock dan
+// digabung untuk semua request yang bersamaan, sehingga request lain tetap memakai key lama selama menunggu.
+// Jika pemuatan ulang gagal, key lama tetap dipakai.
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	now := time.Now()
+	key, found := ks.lookup(kid)
+	loaded := ks.keys != nil
+	stale := now.Sub(ks.fetchedAt) >= ks.refreshInterval
+	due := !loaded || now.Sub(ks.lastAttempt) >= minRefetchInterval
+	if due && (stale || !found) {
+		ks.lastAttempt = now
+	} else {
+		due = false
+	}
+	ks.mu.Unlock()
+
+	if due {
+		// Pengambilan dipakai bersama, jadi tidak ikut dibatalkan ketika request pemicunya selesai
+		_, err, _ := ks.group.Do("refresh", func() (interface{}, error) {
+			return nil, ks.refresh(context.WithoutCancel(ctx))
+		})
+		if err != nil {
+			if !loaded {
+				return nil, err
+			}
+			log.Printf("Peringatan: Gagal memuat ulang JWKS dari %s, memakai key lama: %v\n", ks.source, err)
+		}
+		ks.mu.Lock()
+		key, found = ks.lookup(kid)
+		ks.mu.Unlock()
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// lookup mencari key di cache. Pemanggil harus memegang ks.mu.
+func (ks *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// refresh memuat key dari sumber lalu mengganti cache. ks.mu hanya dipegang saat mengganti cache.
+func (ks *KeySet) refresh(ctx context.Context) error {
+	data, err := ks.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+// fetch membaca isi JWKS dari URL atau file.
+func (ks *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		data, err := os.ReadFile(ks.source)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca file JWKS: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat request JWKS: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gagal mengambil JWKS: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca JWKS: %w", err)
+	}
+	return data, nil
+}
+
+// jsonWebKey adalah bagian JWK yang dibutuhkan untuk key RSA dan EC.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Parse mengurai dokumen JWKS menjadi map kid ke key publik.
+// Key dengan "use" selain "sig" dan tipe key yang tidak didukung diabaikan.
+func Parse(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("format JWKS tidak valid: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaKey()
+		case "EC":
+			key, err = jwk.ecdsaKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key JWKS %q tidak valid: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS tidak berisi key tanda tangan yang didukung")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("eksponen RSA tidak valid")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("kurva EC tidak didukung: %s", jwk.Crv)
+	}
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("titik EC tidak berada di kurva")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// decodeBigInt mengurai bilangan base64url tanpa padding seperti yang dipakai JWK.
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("parameter key kosong")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("parameter key bukan base64url: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,272 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func rsaJWK(t *testing.T, kid string) (string, *rsa.PublicKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &key.PublicKey
+	return fmt.Sprintf(`{"kty":"RSA","kid":%q,"use":"sig","n":%q,"e":%q}`,
+		kid, b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())), pub
+}
+
+func ecJWK(t *testing.T, kid string) (string, *ecdsa.PublicKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &key.PublicKey
+	return fmt.Sprintf(`{"kty":"EC","kid":%q,"crv":"P-256","x":%q,"y":%q}`,
+		kid, b64(pub.X.FillBytes(make([]byte, 32))), b64(pub.Y.FillBytes(make([]byte, 32)))), pub
+}
+
+func jwksDoc(keys ...string) string {
+	return `{"keys":[` + strings.Join(keys, ",") + `]}`
+}
+
+func TestDecodeBigInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{name: "eksponen RSA umum", in: "AQAB", want: 65537},
+		{name: "padding diabaikan", in: "AQAB==", want: 65537},
+		{name: "satu byte", in: "Aw", want: 3},
+		{name: "nol di depan", in: "AAEA", want: 256},
+		{name: "kosong", in: "", wantErr: true},
+		{name: "base64 standar ditolak", in: "a+b/", wantErr: true},
+		{name: "karakter tidak valid", in: "!!!", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeBigInt(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeBigInt(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeBigInt(%q): %v", tt.in, err)
+			}
+			if got.Int64() != tt.want {
+				t.Errorf("decodeBigInt(%q) = %v, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	rsaKey, rsaPub := rsaJWK(t, "rsa-1")
+	ecKey, ecPub := ecJWK(t, "ec-1")
+
+	t.Run("RSA dan EC", func(t *testing.T) {
+		keys, err := Parse([]byte(jwksDoc(rsaKey, ecKey)))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if len(keys) != 2 {
+			t.Fatalf("jumlah key = %d, want 2", len(keys))
+		}
+		if got, ok := keys["rsa-1"].(*rsa.PublicKey); !ok || !got.Equal(rsaPub) {
+			t.Errorf("key rsa-1 = %#v", keys["rsa-1"])
+		}
+		if got, ok := keys["ec-1"].(*ecdsa.PublicKey); !ok || !got.Equal(ecPub) {
+			t.Errorf("key ec-1 = %#v", keys["ec-1"])
+		}
+	})
+
+	t.Run("key selain tanda tangan dan tipe lain diabaikan", func(t *testing.T) {
+		enc := strings.Replace(rsaKey, `"use":"sig"`, `"use":"enc"`, 1)
+		oct := `{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}`
+		keys, err := Parse([]byte(jwksDoc(enc, oct, ecKey)))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if len(keys) != 1 || keys["ec-1"] == nil {
+			t.Errorf("keys = %v, want hanya ec-1", keys)
+		}
+	})
+
+	errorCases := map[string]string{
+		"JSON tidak valid":         `{"keys":`,
+		"tanpa key":                `{"keys":[]}`,
+		"hanya key tidak didukung": jwksDoc(`{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}`),
+		"eksponen RSA terlalu kecil": jwksDoc(fmt.Sprintf(`{"kty":"RSA","kid":"r","n":%q,"e":"AQ"}`,
+			b64(rsaPub.N.Bytes()))),
+		"modulus RSA kosong":  jwksDoc(`{"kty":"RSA","kid":"r","n":"","e":"AQAB"}`),
+		"kurva tidak dikenal": jwksDoc(strings.Replace(ecKey, "P-256", "secp256k1", 1)),
+		"titik di luar kurva": jwksDoc(fmt.Sprintf(`{"kty":"EC","kid":"e","crv":"P-256","x":%q,"y":%q}`,
+			b64(ecPub.X.Bytes()), b64(new(big.Int).Add(ecPub.Y, big.NewInt(1)).Bytes()))),
+	}
+	for name, doc := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			if keys, err := Parse([]byte(doc)); err == nil {
+				t.Errorf("Parse = %v, want error", keys)
+			}
+		})
+	}
+}
+
+// jwksServer menyajikan dokumen JWKS yang bisa diganti dan menghitung jumlah request.
+type jwksServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	doc      string
+	requests atomic.Int32
+	gate     chan struct{} // Jika tidak nil, request ditahan sampai gate ditutup
+}
+
+func newJWKSServer(t *testing.T, doc string) *jwksServer {
+	s := &jwksServer{doc: doc}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.gate != nil {
+			<-s.gate
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Write([]byte(s.doc))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setDoc(doc string) {
+	s.mu.Lock()
+	s.doc = doc
+	s.mu.Unlock()
+}
+
+func TestKeySetRefetchBackoff(t *testing.T) {
+	first, _ := ecJWK(t, "k1")
+	second, _ := ecJWK(t, "k2")
+	srv := newJWKSServer(t, jwksDoc(first))
+	ctx := context.Background()
+
+	t.Run("kid tidak dikenal", func(t *testing.T) {
+		ks := NewKeySet(srv.URL, time.Hour)
+		if _, err := ks.Key(ctx, "k1"); err != nil {
+			t.Fatalf("Key(k1): %v", err)
+		}
+		srv.requests.Store(0)
+		// Pemuatan pertama baru saja terjadi, jadi kid tidak dikenal belum memicu refetch
+		if _, err := ks.Key(ctx, "k2"); err != ErrKeyNotFound {
+			t.Fatalf("Key(k2) = %v, want ErrKeyNotFound", err)
+		}
+		if n := srv.requests.Load(); n != 0 {
+			t.Fatalf("jumlah refetch = %d, want 0 sebelum minRefetchInterval", n)
+		}
+
+		ks.mu.Lock()
+		ks.lastAttempt = time.Now().Add(-minRefetchInterval)
+		ks.mu.Unlock()
+		for i := 0; i < 3; i++ {
+			if _, err := ks.Key(ctx, "k2"); err != ErrKeyNotFound {
+				t.Fatalf("Key(k2) = %v, want ErrKeyNotFound", err)
+			}
+		}
+		if n := srv.requests.Load(); n != 1 {
+			t.Errorf("jumlah refetch = %d, want 1 dalam minRefetchInterval", n)
+		}
+	})
+
+	t.Run("cache kedaluwarsa", func(t *testing.T) {
+		srv.requests.Store(0)
+		ks := NewKeySet(srv.URL, time.Nanosecond)
+		if _, err := ks.Key(ctx, "k1"); err != nil {
+			t.Fatalf("Key(k1): %v", err)
+		}
+		// Cache selalu kedaluwarsa, tapi refetch tetap dibatasi minRefetchInterval
+		srv.setDoc(jwksDoc(second))
+		for i := 0; i < 3; i++ {
+			if _, err := ks.Key(ctx, "k1"); err != nil {
+				t.Fatalf("Key(k1) setelah kedaluwarsa: %v", err)
+			}
+		}
+		if n := srv.requests.Load(); n != 1 {
+			t.Errorf("jumlah request = %d, want 1", n)
+		}
+	})
+}
+
+func TestKeySetConcurrentLoadFetchesOnce(t *testing.T) {
+	doc, pub := ecJWK(t, "k1")
+	srv := newJWKSServer(t, jwksDoc(doc))
+	srv.gate = make(chan struct{})
+	ks := NewKeySet(srv.URL, time.Hour)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, err := ks.Key(context.Background(), "k1")
+			if err == nil && !key.(*ecdsa.PublicKey).Equal(pub) {
+				err = fmt.Errorf("key tidak sesuai")
+			}
+			errs <- err
+		}()
+	}
+	// Tunggu request pertama sampai di server sebelum melepasnya
+	for srv.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(srv.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Key: %v", err)
+		}
+	}
+	if n := srv.requests.Load(); n != 1 {
+		t.Errorf("jumlah request = %d, want 1", n)
+	}
+}
+
+func TestKeySetKeepsOldKeysOnFailure(t *testing.T) {
+	doc, _ := ecJWK(t, "k1")
+	srv := newJWKSServer(t, jwksDoc(doc))
+	ks := NewKeySet(srv.URL, time.Hour)
+	if _, err := ks.Key(context.Background(), "k1"); err != nil {
+		t.Fatalf("Key(k1): %v", err)
+	}
+
+	srv.setDoc("bukan json")
+	ks.mu.Lock()
+	ks.fetchedAt = time.Time{}
+	ks.lastAttempt = time.Time{}
+	ks.mu.Unlock()
+	if _, err := ks.Key(context.Background(), "k1"); err != nil {
+		t.Errorf("Key(k1) setelah refetch gagal = %v, want key lama", err)
+	}
+}
@@ -0,0 +1,129 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Registrasi decoder GIF untuk image.DecodeConfig
+	_ "image/jpeg" // Registrasi decoder JPEG untuk image.DecodeConfig
+	_ "image/png"  // Registrasi decoder PNG untuk image.DecodeConfig
+	"io"
+	"net/http"
+)
+
+// Batasan gambar halaman komik. Tinggi maksimum dibuat besar untuk format webtoon (strip panjang).
+const (
+	MaxImageBytes  = 15 << 20 // 15 MB per gambar
+	MinImageWidth  = 100
+	MinImageHeight = 100
+	MaxImageWidth  = 5000
+	MaxImageHeight = 30000
+)
+
+// ErrInvalidImage dibungkus oleh semua error validasi gambar sehingga handler bisa membedakannya dari error server.
+var ErrInvalidImage = errors.New("gambar tidak valid")
+
+// allowedImageTypes memetakan MIME type yang diizinkan ke ekstensi file.
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// ImageInfo berisi hasil inspeksi sebuah gambar.
+type ImageInfo struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// InspectImage mendeteksi MIME type dari isi file (bukan dari nama/header klien) dan membaca dimensinya.
+// Posisi r dikembalikan ke awal sehingga r bisa langsung dipakai untuk menyimpan file.
+func InspectImage(r io.ReadSeeker) (*ImageInfo, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: file kosong", ErrInvalidImage)
+		}
+		return nil, fmt.Errorf("gagal membaca gambar: %w", err)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: tipe %s tidak didukung (gunakan JPEG, PNG, WebP, atau GIF)", ErrInvalidImage, contentType)
+	}
+
+	var width, height int
+	if contentType == "image/webp" {
+		// Pustaka standar tidak memiliki decoder WebP, dimensi dibaca langsung dari header RIFF
+		width, height, err = webpDimensions(head)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("gagal membaca ulang gambar: %w", err)
+		}
+		cfg, _, err := image.DecodeConfig(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: header gambar rusak: %v", ErrInvalidImage, err)
+		}
+		width, height = cfg.Width, cfg.Height
+	}
+
+	if width < MinImageWidth || height < MinImageHeight {
+		return nil, fmt.Errorf("%w: dimensi %dx%d terlalu kecil (minimal %dx%d)", ErrInvalidImage, width, height, MinImageWidth, MinImageHeight)
+	}
+	if width > MaxImageWidth || height > MaxImageHeight {
+		return nil, fmt.Errorf("%w: dimensi %dx%d terlalu besar (maksimal %dx%d)", ErrInvalidImage, width, height, MaxImageWidth, MaxImageHeight)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("gagal membaca ulang gambar: %w", err)
+	}
+	return &ImageInfo{ContentType: contentType, Ext: ext, Width: width, Height: height}, nil
+}
+
+// webpDimensions membaca lebar dan tinggi dari header WebP (format VP8, VP8L, atau VP8X).
+func webpDimensions(head []byte) (int, int, error) {
+	invalid := fmt.Errorf("%w: header WebP rusak", ErrInvalidImage)
+	if len(head) < 30 {
+		return 0, 0, invalid
+	}
+	switch string(head[12:16]) {
+	case "VP8 ":
+		// Lossy: frame tag 3 byte lalu start code 9d 01 2a, kemudian lebar/tinggi 14 bit
+		if head[23] != 0x9d || head[24] != 0x01 || head[25] != 0x2a {
+			return 0, 0, invalid
+		}
+		w := int(binary.LittleEndian.Uint16(head[26:28]) & 0x3fff)
+		h := int(binary.LittleEndian.Uint16(head[28:30]) & 0x3fff)
+		return w, h, nil
+	case "VP8L":
+		// Lossless: signature 0x2f lalu (lebar-1) dan (tinggi-1) masing-masing 14 bit
+		if head[20] != 0x2f {
+			return 0, 0, invalid
+		}
+		bits := binary.LittleEndian.Uint32(head[21:25])
+		w := int(bits&0x3fff) + 1
+		h := int((bits>>14)&0x3fff) + 1
+		return w, h, nil
+	case "VP8X":
+		// Extended: (lebar-1) dan (tinggi-1) masing-masing 24 bit little endian
+		w := int(uint32(head[24])|uint32(head[25])<<8|uint32(head[26])<<16) + 1
+		h := int(uint32(head[27])|uint32(head[28])<<8|uint32(head[29])<<16) + 1
+		return w, h, nil
+	}
+	return 0, 0, invalid
+}
+
+// ExtensionFor mengembalikan ekstensi file untuk MIME type gambar yang didukung, atau "" jika tidak didukung.
+func ExtensionFor(contentType string) string {
+	return allowedImageTypes[contentType]
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountStatusMiddleware menyinkronkan akun pengguna ke tabel user_accounts, menolak akun yang
+// sedang di-suspend atau di-ban, dan memakai peran yang tersimpan di database sebagai userRole.
+// Dengan begitu perubahan peran dan blokir oleh admin langsung berlaku tanpa menunggu token baru.
+// Middleware ini harus dijalankan SETELAH AuthMiddleware.
+func AccountStatusMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CheckAccountStatus(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckAccountStatus menjalankan pemeriksaan AccountStatusMiddleware untuk request saat ini, misalnya untuk
+// pengguna yang dikenali tanpa token seperti lewat tiket stream notifikasi.
+// Jika akun ditolak, request sudah di-abort dan fungsi mengembalikan false.
+func CheckAccountStatus(c *gin.Context) bool {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "UserID tidak ditemukan di context"})
+		return false
+	}
+	issuedAt := time.Unix(0, 0) // Token tanpa "iat" tidak pernah menimpa peran di database
+	if t, ok := c.Get("tokenIssuedAt"); ok {
+		issuedAt = t.(time.Time)
+	}
+
+	user, err := database.SyncUserAccount(c.Request.Context(), userID, c.GetString("userEmail"), normalizeRole(c.GetString("userRole")), issuedAt)
+	if err != nil {
+		c.Error(err)
+		log.Printf("Error saat sinkronisasi akun pengguna %s: %v\n", userID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa status akun"})
+		return false
+	}
+
+	if user.IsBlocked() {
+		response := gin.H{"error": "Akun Anda sedang diblokir", "status": user.Status}
+		if user.Status == models.UserStatusSuspended {
+			response["suspended_until"] = user.SuspendedUntil
+		}
+		if user.StatusReason != nil {
+			response["reason"] = *user.StatusReason
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, response)
+		return false
+	}
+
+	c.Set("userRole", user.Role)
+	return true
+}
+
+// IsValidRole memeriksa apakah role termasuk peran yang dikenal aplikasi.
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleCreator || role == RoleUser
+}
+
+// normalizeRole memetakan peran yang tidak dikenal ke RoleUser agar lolos constraint database.
+func normalizeRole(role string) string {
+	role = strings.ToLower(role)
+	if IsValidRole(role) {
+		return role
+	}
+	return RoleUser
+}
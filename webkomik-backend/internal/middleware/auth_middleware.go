@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -24,67 +24,109 @@ type AppMetadata struct {
 
 // Claims struct
 type Claims struct {
-	UserID      string      `json:"sub"`
-	AppMeta     AppMetadata `json:"app_metadata,omitempty"` // Untuk membaca dari app_metadata
+	UserID  string      `json:"sub"`
+	Email   string      `json:"email,omitempty"`
+	AppMeta AppMetadata `json:"app_metadata,omitempty"` // Untuk membaca dari app_metadata
 	jwt.RegisteredClaims
 }
 
 // AuthMiddleware membuat Gin middleware untuk otentikasi JWT.
-// Ia akan menggunakan JWT secret dari konfigurasi.
+// Token asimetris (RS256/ES256, dll) diverifikasi dengan key dari JWKS yang dikonfigurasi,
+// sedangkan token HS256 dengan SupabaseJWTSecret hanya diterima jika JWTAllowHMAC aktif.
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	verifier := newTokenVerifier(cfg)
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header dibutuhkan"})
+		if !authenticate(c, verifier) {
 			return
 		}
+		c.Next() // Lanjutkan ke handler berikutnya
+	}
+}
 
-		// Token biasanya dikirim sebagai "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Format Authorization header salah. Harusnya: Bearer <token>"})
+// OptionalAuthMiddleware dipakai pada route publik yang menampilkan data tambahan untuk pengguna yang login,
+// misalnya draft komik untuk anggota timnya. Request tanpa Authorization header, atau dengan token yang tidak
+// valid atau kedaluwarsa, diteruskan sebagai anonim; jika token valid, status akun diperiksa sama seperti
+// AccountStatusMiddleware.
+func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	verifier := newTokenVerifier(cfg)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
 			return
 		}
-		tokenString := parts[1]
-
-		// Parse dan validasi token
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Pastikan algoritma signing adalah yang diharapkan (misalnya HMAC)
-			// Supabase biasanya menggunakan HS256 untuk JWT yang ditandatangani dengan secret.
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("metode signing tidak diharapkan: %v", token.Header["alg"])
-			}
-			return []byte(cfg.SupabaseJWTSecret), nil
-		})
-
-		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Signature token tidak valid"})
-				return
-			}
-			// Tangani error lain seperti token kedaluwarsa, token belum aktif, dll.
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token tidak valid atau kedaluwarsa: " + err.Error()})
+		claims, errMessage := parseBearerToken(c, verifier)
+		if errMessage != "" {
+			c.Next()
 			return
 		}
-
-		if !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token tidak valid"})
+		setClaims(c, claims)
+		if !CheckAccountStatus(c) {
 			return
 		}
+		c.Next()
+	}
+}
+
+// authenticate memverifikasi token dari Authorization header lalu menyimpan informasi pengguna ke context.
+// Jika gagal, request sudah di-abort dan fungsi mengembalikan false.
+func authenticate(c *gin.Context, verifier *tokenVerifier) bool {
+	claims, errMessage := parseBearerToken(c, verifier)
+	if errMessage != "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMessage})
+		return false
+	}
+	setClaims(c, claims)
+	return true
+}
 
-		// Set user ID from the token
-		c.Set("userID", claims.UserID)
+// parseBearerToken memverifikasi token dari Authorization header. Jika gagal, claims bernilai nil dan
+// errMessage berisi alasan yang bisa ditampilkan ke klien.
+func parseBearerToken(c *gin.Context, verifier *tokenVerifier) (claims *Claims, errMessage string) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, "Authorization header dibutuhkan"
+	}
 
-		// Jika token valid, simpan informasi pengguna (UserID dan Role) ke dalam context Gin
-		if claims.AppMeta.Role != "" {
-			c.Set("userRole", claims.AppMeta.Role)
-		} else {
-			// Set default role to 'user' if no role is specified
-			c.Set("userRole", RoleUser)
+	// Token biasanya dikirim sebagai "Bearer <token>"
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, "Format Authorization header salah. Harusnya: Bearer <token>"
+	}
+	tokenString := parts[1]
+
+	// Parse dan validasi token (signature, masa berlaku, issuer, dan audience)
+	claims = &Claims{}
+	token, err := verifier.Parse(c.Request.Context(), tokenString, claims)
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrSignatureInvalid) || errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			return nil, "Signature token tidak valid"
 		}
+		// Tangani error lain seperti token kedaluwarsa, token belum aktif, dll.
+		return nil, "Token tidak valid atau kedaluwarsa: " + err.Error()
+	}
 
-		c.Next() // Lanjutkan ke handler berikutnya
+	if !token.Valid {
+		return nil, "Token tidak valid"
+	}
+	return claims, ""
+}
+
+// setClaims menyimpan informasi pengguna dari token yang sudah diverifikasi ke context.
+func setClaims(c *gin.Context, claims *Claims) {
+	// Set user ID from the token
+	c.Set("userID", claims.UserID)
+	c.Set("userEmail", claims.Email)
+	if claims.IssuedAt != nil {
+		c.Set("tokenIssuedAt", claims.IssuedAt.Time)
+	}
+
+	// Jika token valid, simpan informasi pengguna (UserID dan Role) ke dalam context Gin
+	if claims.AppMeta.Role != "" {
+		c.Set("userRole", claims.AppMeta.Role)
+	} else {
+		// Set default role to 'user' if no role is specified
+		c.Set("userRole", RoleUser)
 	}
 }
 
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/authz"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission membuat middleware yang hanya meloloskan pengguna dengan izin perm.
+// Middleware ini harus dijalankan SETELAH AuthMiddleware.
+func RequirePermission(perm authz.Permission) gin.HandlerFunc {
+	return RequireAnyPermission(perm)
+}
+
+// RequireAnyPermission meloloskan pengguna yang memiliki minimal satu dari izin yang diberikan.
+// Cocok untuk route dengan kebijakan kepemilikan (misalnya comic:update:own atau comic:update:any),
+// di mana pemeriksaan kepemilikan dilanjutkan di handler.
+func RequireAnyPermission(perms ...authz.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, perm := range perms {
+			ok, err := authz.RoleHasPermission(c.Request.Context(), c.GetString("userRole"), perm)
+			if err != nil {
+				c.Error(err)
+				log.Printf("Error saat memeriksa izin %s: %v\n", perm, err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa izin akses"})
+				return
+			}
+			if ok {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Akses ditolak: Anda tidak memiliki izin untuk aksi ini."})
+	}
+}
+
+// CanAccessOwned memeriksa kebijakan kepemilikan untuk pengguna saat ini terhadap resource.
+// Jika pemeriksaan gagal karena error, response 500 sudah ditulis dan fungsi mengembalikan (false, false).
+// Nilai kedua bernilai true jika pemeriksaan berhasil dijalankan.
+func CanAccessOwned(c *gin.Context, policy authz.OwnershipPolicy, resource authz.Owned) (allowed bool, ok bool) {
+	allowed, err := policy.Allows(c.Request.Context(), c.GetString("userRole"), c.GetString("userID"), resource)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa izin akses"})
+		return false, false
+	}
+	return allowed, true
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/config"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/jwks"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma asimetris yang diterima jika JWKS dikonfigurasi.
+var asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// tokenVerifier memvalidasi JWT dengan key dari JWKS dan/atau secret HMAC sesuai konfigurasi,
+// termasuk pemeriksaan issuer dan audience.
+type tokenVerifier struct {
+	keySet     *jwks.KeySet // nil jika JWKS tidak dikonfigurasi
+	hmacSecret []byte       // nil jika HMAC tidak diizinkan
+	parser     *jwt.Parser
+}
+
+// newTokenVerifier menyusun tokenVerifier dari konfigurasi. URL JWKS didahulukan dari file JWKS.
+func newTokenVerifier(cfg *config.Config) *tokenVerifier {
+	v := &tokenVerifier{}
+	var methods []string
+
+	if source := cfg.JWTJWKSURL; source != "" || cfg.JWTJWKSFile != "" {
+		if source == "" {
+			source = cfg.JWTJWKSFile
+		}
+		v.keySet = jwks.NewKeySet(source, cfg.JWTJWKSRefresh)
+		methods = append(methods, asymmetricMethods...)
+	}
+	if cfg.JWTAllowHMAC {
+		v.hmacSecret = []byte(cfg.SupabaseJWTSecret)
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithLeeway(30 * time.Second)}
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v
+}
+
+// Parse memvalidasi tokenString dan mengisi claims.
+func (v *tokenVerifier) Parse(ctx context.Context, tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if v.hmacSecret == nil {
+				return nil, fmt.Errorf("token HMAC tidak diizinkan")
+			}
+			return v.hmacSecret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+			if v.keySet == nil {
+				return nil, fmt.Errorf("JWKS tidak dikonfigurasi untuk algoritma %v", token.Header["alg"])
+			}
+			kid, _ := token.Header["kid"].(string)
+			return v.keySet.Key(ctx, kid)
+		default:
+			return nil, fmt.Errorf("metode signing tidak diharapkan: %v", token.Header["alg"])
+		}
+	})
+}
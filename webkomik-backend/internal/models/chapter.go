@@ -4,11 +4,33 @@ import "time"
 
 // Chapter merepresentasikan satu chapter dari sebuah komik.
 type Chapter struct {
-	ID            int64     `json:"id"`
-	ComicID       int64     `json:"-"`              // Tidak perlu di-expose jika sudah dalam konteks komik
-	ChapterNumber float32   `json:"chapter_number"` // Menggunakan float32 untuk chapter_number
-	Title         *string   `json:"title,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Pages         []Page    `json:"pages,omitempty"` // Daftar halaman dalam chapter ini
+	ID            int64      `json:"id"`
+	ComicID       int64      `json:"comic_id"`       // Dibutuhkan klien saat chapter diambil tanpa konteks komik
+	ChapterNumber float32    `json:"chapter_number"` // Menggunakan float32 untuk chapter_number
+	Title         *string    `json:"title,omitempty"`
+	ComicTitle    string     `json:"comic_title,omitempty"`  // Diisi pada daftar chapter lintas komik, misalnya antrean review
+	Status        string     `json:"status"`                 // Salah satu Publication*
+	ReviewNote    *string    `json:"review_note,omitempty"`  // Alasan penolakan atau penyembunyian dari peninjau
+	PublishAt     *time.Time `json:"publish_at,omitempty"`   // Jadwal rilis; kosong berarti rilis saat disetujui
+	PublishedAt   *time.Time `json:"published_at,omitempty"` // Waktu chapter benar-benar rilis untuk publik
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	PageCount     int        `json:"page_count"`      // Jumlah halaman, diisi pada ringkasan chapter
+	Pages         []Page     `json:"pages,omitempty"` // Daftar halaman dalam chapter ini
+}
+
+// IsReleased memeriksa apakah chapter sudah tampil untuk publik pada waktu now:
+// berstatus published dan sudah rilis, atau jadwal rilisnya sudah lewat walaupun belum diproses scheduler.
+func (ch *Chapter) IsReleased(now time.Time) bool {
+	if ch.Status != PublicationPublished {
+		return false
+	}
+	return ch.PublishedAt != nil || (ch.PublishAt != nil && !ch.PublishAt.After(now))
+}
+
+// ChapterRef adalah referensi ringkas ke sebuah chapter, dipakai untuk navigasi sebelumnya/berikutnya.
+type ChapterRef struct {
+	ID            int64   `json:"id"`
+	ChapterNumber float32 `json:"chapter_number"`
+	Title         *string `json:"title,omitempty"`
 }
@@ -5,15 +5,43 @@ import (
 )
 
 type Comic struct {
-	ID                int64     `json:"id"`
-	Title             string    `json:"title"`
-	Description       *string   `json:"description,omitempty"`
-	AuthorName        *string   `json:"author_name,omitempty"`
-	GenreID           *int64    `json:"genre_id,omitempty"`
-	GenreName         *string   `json:"genre_name,omitempty"`
-	CoverImageURL     *string   `json:"cover_image_url,omitempty"`
-	UploadedByAdminID *string   `json:"-"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
-	Chapters          []Chapter `json:"chapters,omitempty"`
+	ID                int64            `json:"id"`
+	Title             string           `json:"title"`
+	Description       *string          `json:"description,omitempty"`
+	AuthorName        *string          `json:"author_name,omitempty"`
+	Genres            []GenreRef       `json:"genres"`
+	Tags              []string         `json:"tags"`
+	CoverImageURL     *string          `json:"cover_image_url,omitempty"`
+	UploadedByAdminID *string          `json:"-"`
+	ViewCount         int64            `json:"view_count"`
+	RatingAverage     *float64         `json:"rating_average"` // Rata-rata rating 1-5, null jika belum ada rating
+	RatingCount       int64            `json:"rating_count"`
+	Status            string           `json:"status"`                // Salah satu Publication*
+	ReviewNote        *string          `json:"review_note,omitempty"` // Alasan penolakan atau penyembunyian dari peninjau
+	PublishedAt       *time.Time       `json:"published_at,omitempty"`
+	SubmittedAt       *time.Time       `json:"submitted_at,omitempty"` // Waktu terakhir diajukan untuk ditinjau, hanya pada daftar komik
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	DeletedAt         *time.Time       `json:"deleted_at,omitempty"` // Terisi jika komik berada di sampah
+	Chapters          []Chapter        `json:"chapters,omitempty"`
+	Library           *LibraryState    `json:"library,omitempty"`  // Status di pustaka pengunjung, hanya pada detail komik
+	Progress          *ReadingProgress `json:"progress,omitempty"` // Posisi baca terakhir pengunjung, hanya pada detail komik
+	Members           []ComicMember    `json:"-"`                  // Hanya dimuat untuk pemeriksaan akses pengelolaan
+}
+
+// MemberRole mengembalikan peran userID di tim komik (owner, editor, uploader), atau string kosong
+// jika bukan anggota. Members harus sudah dimuat sebelumnya.
+func (c *Comic) MemberRole(userID string) string {
+	for _, m := range c.Members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// ComicRef adalah referensi ringkas ke sebuah komik.
+type ComicRef struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
 }
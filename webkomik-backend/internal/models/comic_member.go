@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Peran anggota tim sebuah komik.
+const (
+	ComicRoleOwner    = "owner"    // Mengelola komik, chapter, dan anggota tim
+	ComicRoleEditor   = "editor"   // Mengubah data komik dan mengelola chapter
+	ComicRoleUploader = "uploader" // Hanya mengelola chapter dan halaman
+)
+
+// Status undangan anggota tim.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired" // Tidak disimpan, dihitung dari expires_at saat undangan masih pending
+)
+
+// ComicMember adalah anggota tim pengelola sebuah komik.
+type ComicMember struct {
+	ComicID   int64     `json:"comic_id"`
+	UserID    string    `json:"user_id"`
+	Email     *string   `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	AddedBy   *string   `json:"added_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ComicInvitation adalah undangan untuk bergabung ke tim sebuah komik.
+// Pengguna diundang lewat ID atau email; jika lewat email, undangan bisa diterima setelah pengguna mendaftar.
+type ComicInvitation struct {
+	ID            int64      `json:"id"`
+	ComicID       int64      `json:"comic_id"`
+	ComicTitle    string     `json:"comic_title"`
+	InviteeUserID *string    `json:"invitee_user_id,omitempty"`
+	InviteeEmail  *string    `json:"invitee_email,omitempty"`
+	Role          string     `json:"role"`
+	InvitedBy     string     `json:"invited_by"`
+	Status        string     `json:"status"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Status komentar chapter.
+const (
+	CommentStatusPublished = "published"
+	CommentStatusHidden    = "hidden"  // Disembunyikan moderator
+	CommentStatusDeleted   = "deleted" // Dihapus penulis atau moderator
+)
+
+// CommentRoleAuthor adalah peran penulis terhadap komentarnya sendiri, dipakai oleh kebijakan kepemilikan.
+const CommentRoleAuthor = "author"
+
+// Comment adalah komentar pembaca pada sebuah chapter, atau balasan untuk komentar lain.
+// Komentar yang disembunyikan atau dihapus tetap tampil sebagai penanda jika masih memiliki balasan,
+// tetapi tanpa isi.
+type Comment struct {
+	ID             int64      `json:"id"`
+	ChapterID      int64      `json:"chapter_id"`
+	PageNumber     *int       `json:"page_number,omitempty"` // Halaman yang dikomentari, jika ada
+	RootID         *int64     `json:"root_id,omitempty"`     // Komentar teratas thread, kosong untuk komentar teratas
+	ParentID       *int64     `json:"parent_id,omitempty"`   // Komentar yang dibalas langsung
+	UserID         string     `json:"user_id"`
+	Body           *string    `json:"body,omitempty"`
+	IsSpoiler      bool       `json:"is_spoiler"`
+	Status         string     `json:"status"` // Salah satu CommentStatus*
+	ModerationNote *string    `json:"moderation_note,omitempty"`
+	LikeCount      int        `json:"like_count"`
+	ReplyCount     int        `json:"reply_count"`
+	LikedByMe      bool       `json:"liked_by_me"`
+	EditedAt       *time.Time `json:"edited_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// MemberRole mengembalikan CommentRoleAuthor jika userID adalah penulis komentar.
+func (c *Comment) MemberRole(userID string) string {
+	if c.UserID == userID {
+		return CommentRoleAuthor
+	}
+	return ""
+}
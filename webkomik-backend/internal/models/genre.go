@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Genre merepresentasikan satu genre komik beserta data pengelolaannya.
+type Genre struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Slug         string    `json:"slug"`
+	Description  *string   `json:"description,omitempty"`
+	DisplayOrder int       `json:"display_order"`
+	ComicCount   int64     `json:"comic_count"` // Jumlah komik aktif (tidak di sampah) dengan genre ini
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// GenreRef adalah ringkasan genre yang disertakan pada data komik.
+type GenreRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Jenis entri pustaka pembaca.
+const (
+	LibraryKindFollow   = "follow"   // Mengikuti komik untuk kabar chapter baru
+	LibraryKindBookmark = "bookmark" // Menandai komik untuk dibaca nanti
+)
+
+// LibraryState adalah status sebuah komik di pustaka pengguna.
+type LibraryState struct {
+	Following             bool       `json:"following"`
+	Bookmarked            bool       `json:"bookmarked"`
+	LastReadChapterNumber *float32   `json:"last_read_chapter_number,omitempty"`
+	LastReadAt            *time.Time `json:"last_read_at,omitempty"`
+	AddedAt               time.Time  `json:"added_at"`
+}
+
+// LibraryEntry adalah satu komik di pustaka pengguna beserta ringkasan chapter yang belum dibaca.
+type LibraryEntry struct {
+	Comic         Comic       `json:"comic"`
+	LibraryState              // Di-embed agar field status tampil sejajar dengan comic
+	UnreadCount   int         `json:"unread_count"`
+	LatestChapter *ChapterRef `json:"latest_chapter,omitempty"`
+	LastUpdatedAt *time.Time  `json:"last_updated_at,omitempty"` // Waktu rilis chapter terbaru
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Jenis notifikasi.
+const (
+	NotificationTypeNewChapter   = "new_chapter"   // Chapter baru pada komik yang diikuti
+	NotificationTypeCommentReply = "comment_reply" // Balasan untuk komentar pengguna
+	NotificationTypeModeration   = "moderation"    // Komentar atau ulasan pengguna dimoderasi
+)
+
+// Notification adalah satu notifikasi untuk pengguna.
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    string     `json:"-"`
+	Type      string     `json:"type"` // Salah satu NotificationType*
+	ComicID   *int64     `json:"comic_id,omitempty"`
+	ChapterID *int64     `json:"chapter_id,omitempty"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      *string    `json:"link,omitempty"` // Path frontend yang dibuka saat notifikasi diklik
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationPreferences adalah pilihan kanal notifikasi pengguna selain in-app, yang selalu aktif.
+type NotificationPreferences struct {
+	EmailEnabled bool `json:"email_enabled"`
+	PushEnabled  bool `json:"push_enabled"`
+}
+
+// NotificationRecipient adalah notifikasi yang baru dibuat beserta data penerima untuk kanal pengiriman.
+type NotificationRecipient struct {
+	Notification Notification
+	Email        *string
+	NotificationPreferences
+}
+
+// PushSubscription adalah langganan Web Push dari satu browser pengguna.
+type PushSubscription struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"-"`
+	Endpoint  string    `json:"endpoint"`
+	P256DH    string    `json:"-"`
+	Auth      string    `json:"-"`
+	UserAgent *string   `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -8,5 +8,7 @@ type Page struct {
 	ChapterID  int64     `json:"-"` // Biasanya tidak perlu di-expose di JSON page individu
 	ImageURL   string    `json:"image_url"`
 	PageNumber int       `json:"page_number"`
+	Width      *int      `json:"width,omitempty"`  // Lebar gambar dalam piksel, jika diketahui
+	Height     *int      `json:"height,omitempty"` // Tinggi gambar dalam piksel, jika diketahui
 	CreatedAt  time.Time `json:"created_at"`
 }
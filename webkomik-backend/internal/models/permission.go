@@ -0,0 +1,7 @@
+package models
+
+// Permission adalah satu izin yang terdaftar beserta deskripsinya.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Status publikasi komik dan chapter. Hanya konten berstatus published yang tampil di endpoint publik.
+const (
+	PublicationDraft     = "draft"     // Masih disiapkan oleh tim komik
+	PublicationInReview  = "in_review" // Sudah diajukan dan menunggu peninjau
+	PublicationPublished = "published" // Tampil untuk publik
+	PublicationHidden    = "hidden"    // Diturunkan oleh peninjau
+)
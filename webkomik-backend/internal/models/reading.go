@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ProgressUpdate adalah satu laporan posisi baca dari klien.
+type ProgressUpdate struct {
+	ChapterID  int64
+	PageNumber int
+	ReadAt     time.Time
+}
+
+// ReadingProgress adalah posisi baca terakhir pengguna pada sebuah komik.
+type ReadingProgress struct {
+	ComicID       int64       `json:"comic_id"`
+	ComicTitle    string      `json:"comic_title,omitempty"` // Diisi pada daftar lanjutkan membaca
+	CoverImageURL *string     `json:"cover_image_url,omitempty"`
+	Chapter       ChapterRef  `json:"chapter"`
+	PageNumber    int         `json:"page_number"`
+	PageCount     int         `json:"page_count"`
+	NextChapter   *ChapterRef `json:"next_chapter,omitempty"` // Chapter rilis berikutnya, jika ada
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// ReadingHistoryEntry adalah satu chapter di riwayat baca pengguna.
+type ReadingHistoryEntry struct {
+	ComicID       int64      `json:"comic_id"`
+	ComicTitle    string     `json:"comic_title"`
+	CoverImageURL *string    `json:"cover_image_url,omitempty"`
+	Chapter       ChapterRef `json:"chapter"`
+	PageNumber    int        `json:"page_number"`
+	PageCount     int        `json:"page_count"`
+	ReadAt        time.Time  `json:"read_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Alasan sebuah komik direkomendasikan.
+const (
+	RecommendationSimilar  = "similar"  // Mirip dengan komik yang diikuti, dibaca, atau dinilai tinggi pengguna
+	RecommendationTrending = "trending" // Pengisi dari komik trending, misalnya untuk pengguna baru
+)
+
+// Recommendation adalah satu komik yang direkomendasikan untuk pengguna.
+type Recommendation struct {
+	Comic
+	Score     float64   `json:"score"`                // Skor relevansi; 0 untuk rekomendasi trending
+	Reason    string    `json:"reason"`               // Salah satu Recommendation*
+	BecauseOf *ComicRef `json:"because_of,omitempty"` // Komik milik pengguna yang paling berpengaruh pada rekomendasi ini
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Status moderasi ulasan pembaca.
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusHidden    = "hidden" // Teks disembunyikan moderator, rating tetap dihitung
+)
+
+// ComicReview adalah rating dan ulasan seorang pengguna untuk sebuah komik.
+type ComicReview struct {
+	ID             int64      `json:"id"`
+	ComicID        int64      `json:"comic_id"`
+	ComicTitle     string     `json:"comic_title,omitempty"` // Diisi pada antrean moderasi
+	UserID         string     `json:"user_id"`
+	Rating         int        `json:"rating"`
+	Body           *string    `json:"body,omitempty"`
+	Status         string     `json:"status"`                    // Salah satu ReviewStatus*
+	ModerationNote *string    `json:"moderation_note,omitempty"` // Alasan moderator menyembunyikan ulasan
+	ReportCount    int        `json:"report_count,omitempty"`    // Hanya ditampilkan untuk moderator
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+}
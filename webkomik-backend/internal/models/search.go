@@ -0,0 +1,9 @@
+package models
+
+// ComicSearchResult merepresentasikan satu hasil pencarian komik beserta skor relevansi dan cuplikannya.
+type ComicSearchResult struct {
+	Comic
+	Rank                 float64 `json:"rank"`
+	TitleHighlight       string  `json:"title_highlight"`                 // HTML aman: judul yang sudah di-escape dengan kata kunci dibungkus <mark>
+	DescriptionHighlight *string `json:"description_highlight,omitempty"` // HTML aman: cuplikan yang sudah di-escape dengan kata kunci dibungkus <mark>
+}
@@ -0,0 +1,16 @@
+package models
+
+// Periode peringkat komik.
+const (
+	TrendingDaily   = "daily"    // View 24 jam terakhir
+	TrendingWeekly  = "weekly"   // View 7 hari terakhir
+	TrendingMonthly = "monthly"  // View 30 hari terakhir
+	TrendingAllTime = "all_time" // Seluruh view (comics.view_count)
+)
+
+// TrendingComic adalah satu komik di peringkat beserta jumlah view-nya dalam periode peringkat.
+type TrendingComic struct {
+	Comic
+	Rank        int   `json:"rank"`
+	PeriodViews int64 `json:"period_views"`
+}
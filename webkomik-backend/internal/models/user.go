@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Status akun pengguna.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended" // Diblokir sementara sampai SuspendedUntil
+	UserStatusBanned    = "banned"    // Diblokir tanpa batas waktu
+)
+
+// User adalah data akun pengguna yang dicerminkan dari identity provider untuk keperluan administrasi.
+type User struct {
+	ID             string     `json:"id"`
+	Email          *string    `json:"email,omitempty"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	StatusReason   *string    `json:"status_reason,omitempty"`
+	ComicCount     int64      `json:"comic_count"` // Jumlah komik aktif yang timnya diikuti pengguna; hanya diisi di listing dan detail admin
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	LastSeenAt     *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// IsBlocked mengembalikan true jika akun sedang di-suspend atau di-ban.
+func (u *User) IsBlocked() bool {
+	return u.Status == UserStatusSuspended || u.Status == UserStatusBanned
+}
@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/config"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+// Message adalah email teks biasa yang dikirim oleh Mailer.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email. Implementasinya SMTPMailer untuk produksi dan LocalMailer untuk pengembangan lokal
+// dan pengujian.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer membuat Mailer sesuai konfigurasi. Tanpa SMTP_HOST, email hanya dicatat ke log
+// sehingga pengembangan lokal tetap bisa berjalan tanpa server SMTP.
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	if cfg.SMTPHost == "" {
+		log.Println("Peringatan: SMTP_HOST tidak di-set, email notifikasi hanya dicatat ke log.")
+		return NewLocalMailer(), nil
+	}
+	return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+}
+
+// smtpTimeout adalah batas waktu satu pengiriman email, mulai dari membuka koneksi sampai QUIT.
+const smtpTimeout = 30 * time.Second
+
+// SMTPMailer mengirim email lewat server SMTP. STARTTLS dipakai otomatis jika didukung server,
+// dan autentikasi hanya dikirim lewat koneksi terenkripsi atau ke localhost.
+type SMTPMailer struct {
+	host string
+	addr string
+	auth smtp.Auth
+	from *mail.Address
+}
+
+// NewSMTPMailer membuat SMTPMailer untuk server host:port. from boleh berisi nama, misalnya
+// "WebKomik <no-reply@example.com>". Tanpa username, email dikirim tanpa autentikasi.
+func NewSMTPMailer(host string, port int, username, password, from string) (*SMTPMailer, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("alamat pengirim SMTP tidak valid: %w", err)
+	}
+	m := &SMTPMailer{host: host, addr: net.JoinHostPort(host, strconv.Itoa(port)), from: fromAddr}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// Send mengirim msg. Koneksi dibuka dengan batas waktu dari ctx, paling lama smtpTimeout, dan batas waktu yang
+// sama berlaku untuk setiap baca/tulis sehingga server SMTP yang macet tidak menahan pengiriman selamanya.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("alamat penerima tidak valid: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("gagal terhubung ke server SMTP %s: %w", m.addr, err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("gagal mengatur batas waktu SMTP: %w", err)
+	}
+	// Pembatalan ctx sebelum deadline memutus koneksi agar pembacaan yang sedang menunggu ikut berhenti
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := m.send(conn, to.Address, buildMessage(m.from, to, msg)); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		return fmt.Errorf("gagal mengirim email ke %s: %w", to.Address, err)
+	}
+	return nil
+}
+
+// send menjalankan percakapan SMTP di atas conn, sama seperti smtp.SendMail.
+func (m *SMTPMailer) send(conn net.Conn, to string, data []byte) error {
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server SMTP tidak mendukung AUTH")
+		}
+		if err := c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildMessage menyusun email teks biasa UTF-8 lengkap dengan header-nya.
+func buildMessage(from, to *mail.Address, msg Message) []byte {
+	// Baris baru di subjek dibuang agar tidak bisa menyisipkan header lain
+	subject := strings.Join(strings.Fields(msg.Subject), " ")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// localMailerCapacity adalah jumlah email terakhir yang disimpan LocalMailer.
+const localMailerCapacity = 100
+
+// LocalMailer adalah Mailer pengganti yang mencatat email ke log dan menyimpan email terakhir di memori,
+// berguna untuk pengembangan lokal dan sebagai fake di pengujian.
+type LocalMailer struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewLocalMailer membuat LocalMailer kosong.
+func NewLocalMailer() *LocalMailer {
+	return &LocalMailer{}
+}
+
+// Send mencatat dan menyimpan msg.
+func (m *LocalMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("[email lokal] ke %s: %s\n", msg.To, msg.Subject)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	if len(m.sent) > localMailerCapacity {
+		m.sent = m.sent[len(m.sent)-localMailerCapacity:]
+	}
+	return nil
+}
+
+// Sent mengembalikan salinan email yang tersimpan, yang terlama lebih dulu.
+func (m *LocalMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
+
+// EmailChannel mengirim notifikasi ke email penerima yang mengaktifkan notifikasi email.
+type EmailChannel struct {
+	mailer      Mailer
+	frontendURL string // Prefix untuk tautan notifikasi
+}
+
+// NewEmailChannel membuat EmailChannel yang mengirim lewat mailer.
+func NewEmailChannel(mailer Mailer, frontendURL string) *EmailChannel {
+	return &EmailChannel{mailer: mailer, frontendURL: strings.TrimRight(frontendURL, "/")}
+}
+
+// Name mengembalikan "email".
+func (ch *EmailChannel) Name() string { return "email" }
+
+// Deliver mengirim satu email per penerima yang mengaktifkan email dan memiliki alamat email.
+func (ch *EmailChannel) Deliver(ctx context.Context, recipients []models.NotificationRecipient) error {
+	var errs []error
+	for _, r := range recipients {
+		if !r.EmailEnabled || r.Email == nil || *r.Email == "" {
+			continue
+		}
+		body := r.Notification.Body
+		if r.Notification.Link != nil {
+			body += "\n\n" + ch.frontendURL + *r.Notification.Link
+		}
+		msg := Message{To: *r.Email, Subject: r.Notification.Title, Body: body}
+		if err := ch.mailer.Send(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
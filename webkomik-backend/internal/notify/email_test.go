@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPSession adalah hasil satu sesi yang diterima fakeSMTPServer.
+type fakeSMTPSession struct {
+	auth string
+	from string
+	rcpt []string
+	data string
+}
+
+// fakeSMTPServer menjalankan server SMTP minimal di localhost untuk satu koneksi. Jika silent true,
+// server menerima koneksi tapi tidak pernah mengirim greeting.
+func fakeSMTPServer(t *testing.T, silent bool) (host string, port int, sessions <-chan fakeSMTPSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan fakeSMTPSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if silent {
+			// Tahan koneksi sampai klien menyerah
+			conn.Read(make([]byte, 1))
+			return
+		}
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		var sess fakeSMTPSession
+		reply("220 fake.test ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO":
+				reply("250-fake.test")
+				reply("250-8BITMIME")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				sess.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+				reply("235 2.7.0 Authentication successful")
+			case "MAIL":
+				sess.from = line
+				reply("250 OK")
+			case "RCPT":
+				sess.rcpt = append(sess.rcpt, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				sess.data = data.String()
+				reply("250 OK: queued")
+			case "QUIT":
+				reply("221 Bye")
+				out <- sess
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, out
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	host, port, sessions := fakeSMTPServer(t, false)
+	// PlainAuth hanya mau mengirim kredensial tanpa TLS ke localhost
+	mailer, err := NewSMTPMailer(host, port, "user", "rahasia", "WebKomik <no-reply@webkomik.test>")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+
+	msg := Message{
+		To:      "Pembaca <pembaca@example.com>",
+		Subject: "Chapter baru\r\nBcc: korban@example.com",
+		Body:    "Baris satu\nBaris dua",
+	}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var sess fakeSMTPSession
+	select {
+	case sess = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server SMTP tidak menerima sesi lengkap")
+	}
+
+	if want := base64.StdEncoding.EncodeToString([]byte("\x00user\x00rahasia")); sess.auth != want {
+		t.Errorf("AUTH = %q, want %q", sess.auth, want)
+	}
+	if sess.from != "MAIL FROM:<no-reply@webkomik.test> BODY=8BITMIME" {
+		t.Errorf("MAIL = %q", sess.from)
+	}
+	if len(sess.rcpt) != 1 || sess.rcpt[0] != "RCPT TO:<pembaca@example.com>" {
+		t.Errorf("RCPT = %q", sess.rcpt)
+	}
+	for _, want := range []string{
+		"From: \"WebKomik\" <no-reply@webkomik.test>\r\n",
+		"To: \"Pembaca\" <pembaca@example.com>\r\n",
+		"Subject: Chapter baru Bcc: korban@example.com\r\n",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\nBaris satu\r\nBaris dua\r\n",
+	} {
+		if !strings.Contains(sess.data, want) {
+			t.Errorf("isi email tidak berisi %q:\n%s", want, sess.data)
+		}
+	}
+	if strings.Contains(sess.data, "\r\nBcc:") {
+		t.Error("subjek berhasil menyisipkan header Bcc")
+	}
+}
+
+func TestSMTPMailerSendTimesOut(t *testing.T) {
+	host, port, _ := fakeSMTPServer(t, true)
+	mailer, err := NewSMTPMailer(host, port, "", "", "no-reply@webkomik.test")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = mailer.Send(ctx, Message{To: "pembaca@example.com", Subject: "Tes", Body: "Tes"})
+	if err == nil {
+		t.Fatal("Send ke server yang diam seharusnya gagal")
+	}
+	// Deadline koneksi dan deadline ctx jatuh bersamaan, jadi salah satunya yang terlapor
+	var netErr net.Error
+	if !errors.Is(err, context.DeadlineExceeded) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+		t.Errorf("Send = %v, want error timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send baru berhenti setelah %v", elapsed)
+	}
+}
+
+func TestSMTPMailerRejectsInvalidRecipient(t *testing.T) {
+	mailer, err := NewSMTPMailer("127.0.0.1", 1, "", "", "no-reply@webkomik.test")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+	if err := mailer.Send(context.Background(), Message{To: "bukan alamat"}); err == nil {
+		t.Error("alamat penerima tidak valid seharusnya ditolak")
+	}
+	if _, err := NewSMTPMailer("127.0.0.1", 25, "", "", "bukan alamat"); err == nil {
+		t.Error("alamat pengirim tidak valid seharusnya ditolak")
+	}
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+const (
+	// hubShards adalah jumlah shard Hub; pengguna dibagi ke shard berdasarkan hash ID-nya agar
+	// pengiriman dan (un)subscribe untuk pengguna berbeda tidak saling menunggu satu mutex.
+	hubShards = 16
+	// subscriptionBuffer adalah jumlah notifikasi yang bisa menunggu dibaca satu koneksi stream.
+	subscriptionBuffer = 64
+	// MaxStreamsPerUser adalah jumlah maksimum koneksi stream yang terbuka bersamaan untuk satu pengguna.
+	MaxStreamsPerUser = 5
+)
+
+var (
+	// ErrTooManyStreams dikembalikan Subscribe jika pengguna sudah membuka MaxStreamsPerUser koneksi.
+	ErrTooManyStreams = errors.New("terlalu banyak koneksi stream untuk pengguna ini")
+	// ErrHubClosed dikembalikan Subscribe setelah Hub ditutup.
+	ErrHubClosed = errors.New("hub notifikasi sudah ditutup")
+)
+
+// Subscription adalah satu koneksi stream milik seorang pengguna.
+type Subscription struct {
+	userID string
+	ch     chan models.Notification
+}
+
+// C mengembalikan channel notifikasi untuk koneksi ini. Channel ditutup jika koneksi dilepas oleh Hub,
+// yaitu saat Hub ditutup atau saat pembaca terlalu lambat sehingga buffer-nya penuh. Klien lalu
+// tersambung ulang dan mengambil notifikasi yang terlewat lewat Last-Event-ID.
+func (s *Subscription) C() <-chan models.Notification { return s.ch }
+
+type hubShard struct {
+	mu   sync.Mutex
+	subs map[string]map[*Subscription]struct{}
+}
+
+// Hub meneruskan notifikasi yang baru disimpan ke koneksi stream (SSE) milik penerimanya.
+// Hub adalah Channel sehingga didaftarkan ke Dispatcher seperti kanal email dan web push.
+type Hub struct {
+	shards [hubShards]hubShard
+	mu     sync.RWMutex // Menjaga closed terhadap Subscribe yang berjalan bersamaan dengan Close
+	closed bool
+}
+
+// NewHub membuat Hub kosong.
+func NewHub() *Hub {
+	h := &Hub{}
+	for i := range h.shards {
+		h.shards[i].subs = make(map[string]map[*Subscription]struct{})
+	}
+	return h
+}
+
+// shard mengembalikan shard untuk userID.
+func (h *Hub) shard(userID string) *hubShard {
+	f := fnv.New32a()
+	f.Write([]byte(userID))
+	return &h.shards[f.Sum32()%hubShards]
+}
+
+// Subscribe membuka koneksi stream baru untuk userID.
+func (h *Hub) Subscribe(userID string) (*Subscription, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+
+	s := h.shard(userID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := s.subs[userID]
+	if len(subs) >= MaxStreamsPerUser {
+		return nil, ErrTooManyStreams
+	}
+	if subs == nil {
+		subs = make(map[*Subscription]struct{})
+		s.subs[userID] = subs
+	}
+	sub := &Subscription{userID: userID, ch: make(chan models.Notification, subscriptionBuffer)}
+	subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Unsubscribe melepas sub dari Hub. Aman dipanggil berkali-kali dan setelah Hub melepasnya sendiri.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	s := h.shard(sub.userID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+}
+
+// remove menghapus dan menutup sub jika masih terdaftar. Pemanggil harus memegang s.mu.
+func (s *hubShard) remove(sub *Subscription) {
+	subs := s.subs[sub.userID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(s.subs, sub.userID)
+	}
+	close(sub.ch)
+}
+
+// Name mengembalikan "sse".
+func (h *Hub) Name() string { return "sse" }
+
+// Deliver meneruskan notifikasi ke semua koneksi stream penerimanya tanpa menunggu. Stream selalu
+// menerima notifikasi karena hanya menampilkan isi kotak notifikasi in-app, terlepas dari preferensi
+// email dan web push. Koneksi yang buffer-nya penuh dilepas agar satu klien lambat tidak menahan yang lain.
+func (h *Hub) Deliver(ctx context.Context, recipients []models.NotificationRecipient) error {
+	for _, r := range recipients {
+		s := h.shard(r.Notification.UserID)
+		s.mu.Lock()
+		for sub := range s.subs[r.Notification.UserID] {
+			select {
+			case sub.ch <- r.Notification:
+			default:
+				s.remove(sub)
+			}
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Close menolak koneksi baru dan menutup semua koneksi yang masih terbuka, dipakai saat server berhenti
+// agar handler stream yang berjalan lama ikut selesai.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	for i := range h.shards {
+		s := &h.shards[i]
+		s.mu.Lock()
+		for _, subs := range s.subs {
+			for sub := range subs {
+				s.remove(sub)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,227 @@
+// Package notify membuat notifikasi pengguna dari event domain dan mengirimkannya lewat kanal-kanal pengiriman.
+// Kanal in-app adalah tabel notifications itu sendiri: setiap notifikasi selalu disimpan lebih dulu,
+// lalu diteruskan ke kanal tambahan (stream SSE, email, web push) sesuai preferensi penerimanya.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+)
+
+const (
+	// fanOutBatchSize adalah jumlah pengikut yang dibuatkan notifikasi dalam satu query.
+	fanOutBatchSize = 500
+	// queueSize adalah jumlah event yang bisa menunggu diproses sebelum pengirim event ikut menunggu.
+	queueSize = 256
+	// excerptLength adalah panjang maksimum kutipan komentar di isi notifikasi, dalam karakter.
+	excerptLength = 140
+)
+
+// Channel adalah kanal pengiriman notifikasi selain in-app.
+type Channel interface {
+	// Name mengembalikan nama kanal untuk log.
+	Name() string
+	// Deliver mengirim notifikasi yang baru disimpan. Kanal sendiri yang memeriksa preferensi penerima,
+	// dan kegagalan untuk satu penerima tidak boleh menghentikan pengiriman ke penerima lain.
+	Deliver(ctx context.Context, recipients []models.NotificationRecipient) error
+}
+
+// Dispatcher menerima event dari Bus dan melakukan fan-out notifikasi di goroutine sendiri,
+// sehingga request yang memicu event tidak ikut menunggu. Antrean disimpan di memori; khusus chapter baru,
+// rilisnya juga dicatat di outbox database dan baru dihapus setelah fan-out selesai, sehingga event yang
+// hilang saat server berhenti dikirim ulang oleh scheduler rilis chapter.
+type Dispatcher struct {
+	channels []Channel
+	queue    chan events.Event
+	done     chan struct{}
+}
+
+// NewDispatcher membuat Dispatcher yang meneruskan notifikasi ke channels.
+func NewDispatcher(channels ...Channel) *Dispatcher {
+	return &Dispatcher{
+		channels: channels,
+		queue:    make(chan events.Event, queueSize),
+		done:     make(chan struct{}),
+	}
+}
+
+// Subscribe mendaftarkan Dispatcher ke event yang menghasilkan notifikasi.
+func (d *Dispatcher) Subscribe(bus *events.Bus) {
+	enqueue := func(ctx context.Context, event events.Event) {
+		select {
+		case d.queue <- event:
+		case <-d.done:
+			log.Printf("Peringatan: Notifikasi untuk event %s tidak dibuat karena dispatcher sudah berhenti\n", event.EventName())
+		}
+	}
+	bus.Subscribe(events.ChapterPublishedEvent, enqueue)
+	bus.Subscribe(events.CommentRepliedEvent, enqueue)
+	bus.Subscribe(events.ContentModeratedEvent, enqueue)
+}
+
+// Run memproses antrean event sampai ctx dibatalkan.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log.Println("Dispatcher notifikasi berjalan")
+	defer close(d.done)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Dispatcher notifikasi dihentikan.")
+			return
+		case event := <-d.queue:
+			switch e := event.(type) {
+			case events.ChapterPublished:
+				d.fanOutNewChapter(ctx, e)
+			case events.CommentReplied:
+				d.notifyUser(ctx, e.ParentUserID, commentReplyNotification(e))
+			case events.ContentModerated:
+				d.notifyUser(ctx, e.OwnerID, moderationNotification(e))
+			}
+		}
+	}
+}
+
+// fanOutNewChapter membuat notifikasi chapter baru untuk semua pengikut komik, per batch,
+// lalu meneruskan setiap batch ke kanal pengiriman. Setelah semua batch selesai, chapter dihapus dari outbox
+// rilis; jika gagal di tengah jalan, scheduler mengulang fan-out dan notifikasi yang sudah dibuat dilewati.
+func (d *Dispatcher) fanOutNewChapter(ctx context.Context, e events.ChapterPublished) {
+	template := newChapterNotification(e)
+	var (
+		after *string
+		total int
+	)
+	for {
+		userIDs, err := database.ListComicFollowerIDs(ctx, e.ComicID, after, fanOutBatchSize)
+		if err != nil {
+			log.Printf("Error saat mengambil pengikut komik ID %d untuk notifikasi: %v\n", e.ComicID, err)
+			return
+		}
+		recipients, err := database.CreateNotifications(ctx, userIDs, template)
+		if err != nil {
+			log.Printf("Error saat membuat notifikasi chapter ID %d: %v\n", e.ChapterID, err)
+			return
+		}
+		d.deliver(ctx, recipients)
+		total += len(recipients)
+
+		if len(userIDs) < fanOutBatchSize {
+			break
+		}
+		after = &userIDs[len(userIDs)-1]
+	}
+	log.Printf("Notifikasi chapter %v komik ID %d dibuat untuk %d pengikut\n", e.ChapterNumber, e.ComicID, total)
+	if err := database.CompleteChapterRelease(ctx, e.ChapterID); err != nil {
+		log.Printf("Peringatan: Gagal menandai notifikasi chapter ID %d selesai: %v\n", e.ChapterID, err)
+	}
+}
+
+// notifyUser membuat satu notifikasi untuk userID lalu meneruskannya ke kanal pengiriman.
+func (d *Dispatcher) notifyUser(ctx context.Context, userID string, template models.Notification) {
+	recipients, err := database.CreateNotifications(ctx, []string{userID}, template)
+	if err != nil {
+		log.Printf("Error saat membuat notifikasi %s untuk pengguna %s: %v\n", template.Type, userID, err)
+		return
+	}
+	d.deliver(ctx, recipients)
+}
+
+// deliver meneruskan notifikasi ke setiap kanal; kegagalan satu kanal hanya dicatat.
+func (d *Dispatcher) deliver(ctx context.Context, recipients []models.NotificationRecipient) {
+	if len(recipients) == 0 {
+		return
+	}
+	for _, ch := range d.channels {
+		if err := ch.Deliver(ctx, recipients); err != nil {
+			log.Printf("Error saat mengirim notifikasi lewat kanal %s: %v\n", ch.Name(), err)
+		}
+	}
+}
+
+// newChapterNotification membentuk isi notifikasi chapter baru, contoh: "Chapter 10.5: Judul sudah bisa dibaca."
+func newChapterNotification(e events.ChapterPublished) models.Notification {
+	chapter := "Chapter " + strconv.FormatFloat(float64(e.ChapterNumber), 'f', -1, 32)
+	if e.ChapterTitle != nil && *e.ChapterTitle != "" {
+		chapter += ": " + *e.ChapterTitle
+	}
+	link := fmt.Sprintf("/comic/%d", e.ComicID)
+	return models.Notification{
+		Type:      models.NotificationTypeNewChapter,
+		ComicID:   &e.ComicID,
+		ChapterID: &e.ChapterID,
+		Title:     "Chapter baru: " + e.ComicTitle,
+		Body:      chapter + " sudah bisa dibaca.",
+		Link:      &link,
+	}
+}
+
+// commentReplyNotification membentuk isi notifikasi balasan komentar dengan kutipan balasannya.
+// Balasan yang ditandai spoiler tidak dikutip.
+func commentReplyNotification(e events.CommentReplied) models.Notification {
+	link := fmt.Sprintf("/comic/%d", e.ComicID)
+	body := excerpt(e.Body)
+	if e.IsSpoiler {
+		body = "Balasan ini berisi spoiler. Buka komentar untuk membacanya."
+	}
+	return models.Notification{
+		Type:      models.NotificationTypeCommentReply,
+		ComicID:   &e.ComicID,
+		ChapterID: &e.ChapterID,
+		Title:     "Komentar Anda mendapat balasan",
+		Body:      body,
+		Link:      &link,
+	}
+}
+
+// moderationTitles memetakan jenis konten dan aksi moderasi ke judul notifikasi.
+var moderationTitles = map[string]map[string]string{
+	events.ModeratedComment: {
+		events.ModerationHidden:   "Komentar Anda disembunyikan moderator",
+		events.ModerationRestored: "Komentar Anda ditampilkan kembali",
+		events.ModerationDeleted:  "Komentar Anda dihapus moderator",
+	},
+	events.ModeratedReview: {
+		events.ModerationHidden:   "Ulasan Anda disembunyikan moderator",
+		events.ModerationRestored: "Ulasan Anda ditampilkan kembali",
+		events.ModerationDeleted:  "Ulasan Anda dihapus moderator",
+	},
+}
+
+// moderationNotification membentuk isi notifikasi moderasi konten beserta alasannya, jika ada.
+func moderationNotification(e events.ContentModerated) models.Notification {
+	title := moderationTitles[e.ContentType][e.Action]
+	if title == "" {
+		title = "Konten Anda dimoderasi"
+	}
+	body := "Konten Anda sudah tampil kembali untuk publik."
+	if e.Action != events.ModerationRestored {
+		body = "Tidak ada alasan yang dicatat."
+		if e.Reason != nil && *e.Reason != "" {
+			body = "Alasan: " + excerpt(*e.Reason)
+		}
+	}
+	link := fmt.Sprintf("/comic/%d", e.ComicID)
+	return models.Notification{
+		Type:      models.NotificationTypeModeration,
+		ComicID:   &e.ComicID,
+		ChapterID: e.ChapterID,
+		Title:     title,
+		Body:      body,
+		Link:      &link,
+	}
+}
+
+// excerpt memotong s menjadi paling banyak excerptLength karakter.
+func excerpt(s string) string {
+	if utf8.RuneCountInString(s) <= excerptLength {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:excerptLength-1]) + "…"
+}
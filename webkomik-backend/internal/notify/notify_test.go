@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
+)
+
+func TestCommentReplyNotification(t *testing.T) {
+	e := events.CommentReplied{ComicID: 7, ChapterID: 70, Body: "Ternyata pelakunya adalah kakaknya sendiri"}
+
+	n := commentReplyNotification(e)
+	if n.Body != e.Body {
+		t.Errorf("Body = %q, want kutipan balasan", n.Body)
+	}
+	if n.Link == nil || *n.Link != "/comic/7" {
+		t.Errorf("Link = %v, want /comic/7", n.Link)
+	}
+
+	e.IsSpoiler = true
+	n = commentReplyNotification(e)
+	if strings.Contains(n.Body, "pelakunya") {
+		t.Errorf("Body balasan spoiler tidak boleh dikutip: %q", n.Body)
+	}
+}
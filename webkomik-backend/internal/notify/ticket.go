@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StreamTicketTTL adalah masa berlaku tiket stream yang tidak sedang dipakai: sejak diterbitkan, dan sejak
+// koneksi terakhir yang memakainya terputus. Selama masa ini browser bisa tersambung ulang dengan tiket yang sama.
+const StreamTicketTTL = time.Minute
+
+type streamTicket struct {
+	userID    string
+	expiresAt time.Time
+	active    int // Jumlah koneksi stream yang sedang memakai tiket ini
+}
+
+// StreamTickets menerbitkan tiket untuk membuka stream notifikasi. EventSource di browser tidak bisa mengirim
+// header Authorization, jadi klien menukar token login dengan tiket lalu mengirim tiket tersebut lewat query string.
+// Tiket terikat pada sesi stream: tetap berlaku selama ada koneksi yang memakainya dan selama StreamTicketTTL setelah
+// koneksi terakhir terputus, karena EventSource tersambung ulang ke URL yang sama (dengan Last-Event-ID).
+// Tiket disimpan di memori proses ini saja.
+type StreamTickets struct {
+	mu      sync.Mutex
+	tickets map[string]*streamTicket
+}
+
+// NewStreamTickets membuat penyimpanan tiket kosong.
+func NewStreamTickets() *StreamTickets {
+	return &StreamTickets{tickets: make(map[string]*streamTicket)}
+}
+
+// Issue menerbitkan tiket baru untuk userID.
+func (t *StreamTickets) Issue(userID string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("gagal membuat tiket stream: %w", err)
+	}
+	ticket := hex.EncodeToString(buf)
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Tiket kedaluwarsa dibersihkan saat penerbitan agar map tidak terus membesar
+	for k, v := range t.tickets {
+		if v.expired(now) {
+			delete(t.tickets, k)
+		}
+	}
+	t.tickets[ticket] = &streamTicket{userID: userID, expiresAt: now.Add(StreamTicketTTL)}
+	return ticket, nil
+}
+
+// Redeem memakai tiket untuk satu koneksi stream dan mengembalikan ID pemiliknya. Setiap Redeem yang berhasil
+// harus diikuti Release saat koneksi selesai. Mengembalikan false jika tiket tidak dikenal atau sudah kedaluwarsa.
+func (t *StreamTickets) Redeem(ticket string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v, ok := t.tickets[ticket]
+	if !ok {
+		return "", false
+	}
+	if v.expired(time.Now()) {
+		delete(t.tickets, ticket)
+		return "", false
+	}
+	v.active++
+	return v.userID, true
+}
+
+// Release menandai satu koneksi yang memakai tiket sudah selesai. Setelah koneksi terakhir selesai,
+// tiket masih berlaku selama StreamTicketTTL untuk penyambungan ulang.
+func (t *StreamTickets) Release(ticket string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v, ok := t.tickets[ticket]
+	if !ok || v.active == 0 {
+		return
+	}
+	v.active--
+	if v.active == 0 {
+		v.expiresAt = time.Now().Add(StreamTicketTTL)
+	}
+}
+
+// expired memeriksa apakah tiket sudah tidak berlaku pada waktu now.
+func (v *streamTicket) expired(now time.Time) bool {
+	return v.active == 0 && now.After(v.expiresAt)
+}
@@ -0,0 +1,326 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// pushRecordSize adalah ukuran record aes128gcm (RFC 8188); payload dikirim dalam satu record.
+	pushRecordSize = 4096
+	// maxPushPayload adalah ukuran payload maksimum agar body terenkripsi tidak melebihi 4096 byte
+	// yang wajib diterima push service: header 86 byte, tag GCM 16 byte, dan 1 byte pembatas.
+	maxPushPayload = 4096 - 86 - 16 - 1
+	// pushTTL adalah lama push service menyimpan pesan jika browser sedang offline.
+	pushTTL = 24 * time.Hour
+	// pushConcurrency adalah jumlah maksimum request ke push service yang berjalan bersamaan.
+	pushConcurrency = 8
+)
+
+// pushServiceHosts adalah host push service browser yang dikenal. Endpoint langganan hanya boleh mengarah ke
+// host ini atau subdomainnya, agar server tidak bisa dipakai mengirim request ke alamat internal (SSRF).
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",        // Chrome, Edge berbasis Chromium, Opera
+	"android.googleapis.com",    // Chrome versi lama
+	"push.services.mozilla.com", // Firefox
+	"push.apple.com",            // Safari
+	"notify.windows.com",        // Edge lama (WNS)
+}
+
+// errSubscriptionGone menandakan push service tidak lagi mengenal langganan (404/410).
+var errSubscriptionGone = errors.New("langganan push sudah tidak berlaku")
+
+// WebPushChannel mengirim notifikasi ke browser lewat Web Push (RFC 8030) dengan payload terenkripsi
+// (RFC 8291) dan autentikasi VAPID (RFC 8292).
+type WebPushChannel struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  string // Kunci publik VAPID, base64url, untuk applicationServerKey di browser
+	subject    string
+	client     *http.Client
+}
+
+// NewWebPushChannel membuat WebPushChannel dari kunci privat VAPID (skalar P-256 mentah 32 byte, base64url).
+// Kunci publiknya diturunkan dari kunci privat sehingga keduanya tidak mungkin tertukar.
+func NewWebPushChannel(privateKey, subject string) (*WebPushChannel, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("kunci privat VAPID bukan base64url yang valid: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("kunci privat VAPID tidak valid: %w", err)
+	}
+	pub := key.PublicKey().Bytes() // 0x04 || X || Y
+	signer := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+	return &WebPushChannel{
+		privateKey: signer,
+		publicKey:  base64.RawURLEncoding.EncodeToString(pub),
+		subject:    subject,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+			// Redirect tidak diikuti agar push service tidak bisa mengarahkan request ke host lain
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}, nil
+}
+
+// PublicKey mengembalikan kunci publik VAPID dalam base64url.
+func (ch *WebPushChannel) PublicKey() string { return ch.publicKey }
+
+// Name mengembalikan "webpush".
+func (ch *WebPushChannel) Name() string { return "webpush" }
+
+// pushPayload adalah isi pesan yang diterima service worker di browser.
+type pushPayload struct {
+	ID    int64   `json:"id"`
+	Type  string  `json:"type"`
+	Title string  `json:"title"`
+	Body  string  `json:"body"`
+	Link  *string `json:"link,omitempty"`
+}
+
+// Deliver mengirim notifikasi ke semua browser milik penerima yang mengaktifkan web push.
+// Langganan yang ditolak push service karena sudah tidak berlaku dihapus.
+func (ch *WebPushChannel) Deliver(ctx context.Context, recipients []models.NotificationRecipient) error {
+	payloads := make(map[string][]byte)
+	userIDs := []string{}
+	for _, r := range recipients {
+		if !r.PushEnabled {
+			continue
+		}
+		n := r.Notification
+		payload, err := json.Marshal(pushPayload{ID: n.ID, Type: n.Type, Title: n.Title, Body: n.Body, Link: n.Link})
+		if err != nil {
+			return fmt.Errorf("gagal menyusun payload push: %w", err)
+		}
+		payloads[n.UserID] = payload
+		userIDs = append(userIDs, n.UserID)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	subs, err := database.ListPushSubscriptions(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, pushConcurrency)
+	)
+	for _, sub := range subs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			err := ch.send(ctx, sub, payloads[sub.UserID])
+			if errors.Is(err, errSubscriptionGone) {
+				err = database.DeletePushSubscriptionByEndpoint(ctx, sub.Endpoint)
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// send mengenkripsi payload untuk satu langganan lalu mengirimkannya ke push service.
+func (ch *WebPushChannel) send(ctx context.Context, sub models.PushSubscription, payload []byte) error {
+	body, err := encryptPushPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+	endpoint, err := parseEndpoint(sub.Endpoint)
+	if err != nil {
+		return fmt.Errorf("endpoint push %s ditolak: %w", sub.Endpoint, err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": ch.subject,
+	}).SignedString(ch.privateKey)
+	if err != nil {
+		return fmt.Errorf("gagal menandatangani token VAPID: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("gagal membuat request push: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprint(int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, ch.publicKey))
+
+	resp, err := ch.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gagal menghubungi push service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("push service menolak pesan (status %d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// encryptPushPayload mengenkripsi payload untuk browser pemilik langganan sesuai RFC 8291,
+// dalam format content coding aes128gcm dengan satu record. Kunci sementara server dan salt dibuat baru
+// untuk setiap pesan.
+func encryptPushPayload(sub models.PushSubscription, payload []byte) ([]byte, error) {
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat kunci sementara: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("gagal membuat salt: %w", err)
+	}
+	return encryptPushPayloadWith(sub, payload, asKey, salt)
+}
+
+// encryptPushPayloadWith adalah encryptPushPayload dengan kunci sementara server asKey dan salt 16 byte
+// yang ditentukan pemanggil.
+func encryptPushPayloadWith(sub models.PushSubscription, payload []byte, asKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(payload) > maxPushPayload {
+		return nil, fmt.Errorf("payload push terlalu besar: %d byte", len(payload))
+	}
+	uaPublic, err := decodeBase64URL(sub.P256DH)
+	if err != nil {
+		return nil, fmt.Errorf("kunci p256dh langganan tidak valid: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("auth secret langganan tidak valid: %w", err)
+	}
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("kunci p256dh langganan tidak valid: %w", err)
+	}
+
+	asPublic := asKey.PublicKey().Bytes()
+	sharedSecret, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung ECDH: %w", err)
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt (16) || record size (4) || panjang key id (1) || key id = kunci publik server (65)
+	body := make([]byte, 0, 86+len(payload)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, pushRecordSize)
+	body = append(body, byte(len(asPublic)))
+	body = append(body, asPublic...)
+	// 0x02 menandai record terakhir; payload disalin karena dipakai bersama oleh beberapa langganan
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+// decodeBase64URL mendekode base64url dengan atau tanpa padding, seperti yang dikirim browser.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// ValidateEndpoint memastikan endpoint langganan adalah URL https milik push service browser yang dikenal.
+func ValidateEndpoint(endpoint string) error {
+	_, err := parseEndpoint(endpoint)
+	return err
+}
+
+// parseEndpoint mem-parse endpoint langganan dan memeriksanya terhadap pushServiceHosts.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return nil, errors.New("endpoint harus berupa URL https")
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return nil, errors.New("endpoint harus memakai port 443")
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	for _, allowed := range pushServiceHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("host %s bukan push service yang dikenal", host)
+}
+
+// ValidateSubscription memastikan kunci langganan dari browser bisa dipakai untuk enkripsi payload.
+func ValidateSubscription(p256dh, auth string) error {
+	uaPublic, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return errors.New("kunci p256dh bukan base64url yang valid")
+	}
+	if _, err := ecdh.P256().NewPublicKey(uaPublic); err != nil {
+		return errors.New("kunci p256dh bukan kunci publik P-256 yang valid")
+	}
+	authSecret, err := decodeBase64URL(auth)
+	if err != nil || len(authSecret) != 16 {
+		return errors.New("auth secret harus 16 byte dalam base64url")
+	}
+	return nil
+}
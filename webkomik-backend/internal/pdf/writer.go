@@ -0,0 +1,263 @@
+// Package pdf berisi penulis PDF minimal untuk mengekspor halaman komik berupa gambar.
+// Setiap gambar menjadi satu halaman PDF; dokumen ditulis secara streaming sehingga hanya satu
+// file gambar yang berada di memori pada satu waktu. JPEG disisipkan apa adanya, sedangkan PNG/GIF
+// harus di-decode penuh (4 byte per piksel), sehingga ukurannya dibatasi MaxConvertPixels dan jumlah
+// konversi yang berjalan bersamaan dibatasi untuk seluruh proses.
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Registrasi decoder GIF untuk konversi ke JPEG
+	"image/jpeg"
+	_ "image/png" // Registrasi decoder PNG untuk konversi ke JPEG
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// Satuan PDF adalah point (1/72 inci). Gambar dianggap 96 DPI.
+const (
+	pointsPerPixel = 72.0 / 96.0
+	maxPageSize    = 14400.0 // Batas ukuran halaman yang didukung kebanyakan pembaca PDF (200 inci)
+	jpegQuality    = 90
+)
+
+// Nomor objek tetap: katalog, pohon halaman, lalu empat objek per halaman dan info dokumen di akhir.
+const (
+	catalogObj   = 1
+	pagesObj     = 2
+	firstPageObj = 3
+	objsPerPage  = 4 // page, content stream, image XObject, panjang stream gambar
+)
+
+// MaxConvertPixels adalah jumlah piksel maksimum gambar non-JPEG yang dikonversi ke JPEG (sekitar 64 MB RGBA).
+const MaxConvertPixels = 16_000_000
+
+// convertSlots membatasi jumlah konversi gambar yang berjalan bersamaan agar unduhan paralel
+// tidak menghabiskan memori.
+var convertSlots = make(chan struct{}, 2)
+
+// ErrUnsupportedImage dikembalikan jika gambar tidak bisa dimasukkan ke PDF (misalnya WebP).
+var ErrUnsupportedImage = errors.New("format gambar tidak didukung untuk PDF")
+
+// ErrImageTooLarge dikembalikan jika gambar non-JPEG melebihi MaxConvertPixels.
+var ErrImageTooLarge = errors.New("gambar terlalu besar untuk dikonversi ke PDF")
+
+// NeedsConversion melaporkan apakah gambar dengan ekstensi ext harus dikonversi ke JPEG sebelum disisipkan.
+func NeedsConversion(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext != ".jpg" && ext != ".jpeg"
+}
+
+// Writer menulis dokumen PDF satu halaman demi satu halaman.
+// Jumlah halaman harus diketahui di awal karena pohon halaman ditulis sebelum isi halaman.
+type Writer struct {
+	w         *countingWriter
+	pageCount int
+	written   int
+	offsets   map[int]int64
+	title     string
+	author    string
+}
+
+// NewWriter membuat Writer untuk pageCount halaman dan menulis header serta pohon halaman.
+func NewWriter(w io.Writer, pageCount int, title, author string) (*Writer, error) {
+	pw := &Writer{
+		w:         &countingWriter{w: w},
+		pageCount: pageCount,
+		offsets:   make(map[int]int64),
+		title:     title,
+		author:    author,
+	}
+
+	// Baris komentar kedua berisi byte > 127 agar file dikenali sebagai biner
+	if _, err := io.WriteString(pw.w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, err
+	}
+	if err := pw.writeObject(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj)); err != nil {
+		return nil, err
+	}
+
+	var kids bytes.Buffer
+	for i := 0; i < pageCount; i++ {
+		fmt.Fprintf(&kids, "%d 0 R ", pw.pageObj(i))
+	}
+	if err := pw.writeObject(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [ %s] /Count %d >>", kids.String(), pageCount)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// AddImagePage membaca satu gambar (JPEG, PNG, atau GIF) dan menuliskannya sebagai halaman baru.
+// JPEG disisipkan apa adanya; format lain dikonversi ke JPEG terlebih dahulu.
+func (pw *Writer) AddImagePage(r io.Reader) error {
+	if pw.written >= pw.pageCount {
+		return errors.New("jumlah halaman melebihi yang dideklarasikan")
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("gagal membaca gambar: %w", err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if format != "jpeg" {
+		// Ukuran diperiksa dari header sebelum gambar di-decode
+		if int64(cfg.Width)*int64(cfg.Height) > MaxConvertPixels {
+			return fmt.Errorf("%w: %dx%d piksel melebihi batas %d piksel", ErrImageTooLarge, cfg.Width, cfg.Height, MaxConvertPixels)
+		}
+		if data, cfg.ColorModel, err = convertToJPEG(data); err != nil {
+			return err
+		}
+	}
+
+	colorSpace := "/DeviceRGB"
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		colorSpace = "/DeviceGray"
+	case color.CMYKModel:
+		// JPEG CMYK dari Adobe menyimpan nilai terbalik
+		colorSpace = "/DeviceCMYK /Decode [1 0 1 0 1 0 1 0]"
+	}
+
+	// Skala gambar ke point, perkecil jika melebihi batas ukuran halaman (umum pada strip webtoon)
+	scale := pointsPerPixel
+	if longest := float64(max(cfg.Width, cfg.Height)) * scale; longest > maxPageSize {
+		scale *= maxPageSize / longest
+	}
+	width := float64(cfg.Width) * scale
+	height := float64(cfg.Height) * scale
+
+	pageObj := pw.pageObj(pw.written)
+	contentObj, imageObj, lengthObj := pageObj+1, pageObj+2, pageObj+3
+
+	page := fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+		pagesObj, width, height, imageObj, contentObj)
+	if err := pw.writeObject(pageObj, page); err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("q %.2f 0 0 %.2f 0 0 cm /Im0 Do Q", width, height)
+	if err := pw.writeObject(contentObj, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)); err != nil {
+		return err
+	}
+
+	imageDict := fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode /Length %d 0 R >>\nstream\n",
+		cfg.Width, cfg.Height, colorSpace, lengthObj)
+	if err := pw.startObject(imageObj); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(pw.w, imageDict); err != nil {
+		return err
+	}
+	if _, err := pw.w.Write(data); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(pw.w, "\nendstream\nendobj\n"); err != nil {
+		return err
+	}
+	if err := pw.writeObject(lengthObj, fmt.Sprintf("%d", len(data))); err != nil {
+		return err
+	}
+
+	pw.written++
+	return nil
+}
+
+// convertToJPEG men-decode gambar lalu meng-encode ulang sebagai JPEG. Mengembalikan data JPEG dan
+// model warnanya.
+func convertToJPEG(data []byte) ([]byte, color.Model, error) {
+	convertSlots <- struct{}{}
+	defer func() { <-convertSlots }()
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, nil, fmt.Errorf("gagal mengonversi gambar ke JPEG: %w", err)
+	}
+	model := color.YCbCrModel // Encoder JPEG menulis YCbCr untuk gambar berwarna
+	if _, ok := img.(*image.Gray); ok {
+		model = img.ColorModel()
+	}
+	return buf.Bytes(), model, nil
+}
+
+// Close menulis info dokumen, tabel xref, dan trailer. Writer tujuan tidak ditutup.
+func (pw *Writer) Close() error {
+	if pw.written != pw.pageCount {
+		return fmt.Errorf("baru %d dari %d halaman yang ditulis", pw.written, pw.pageCount)
+	}
+
+	infoObj := pw.pageObj(pw.pageCount)
+	info := fmt.Sprintf("<< /Title %s /Author %s /Producer %s >>", textString(pw.title), textString(pw.author), textString("WebKomik"))
+	if err := pw.writeObject(infoObj, info); err != nil {
+		return err
+	}
+
+	xrefOffset := pw.w.n
+	size := infoObj + 1
+	if _, err := fmt.Fprintf(pw.w, "xref\n0 %d\n0000000000 65535 f \n", size); err != nil {
+		return err
+	}
+	for obj := 1; obj < size; obj++ {
+		if _, err := fmt.Fprintf(pw.w, "%010d 00000 n \n", pw.offsets[obj]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(pw.w, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		size, catalogObj, infoObj, xrefOffset)
+	return err
+}
+
+// pageObj mengembalikan nomor objek halaman ke-i (mulai dari 0).
+func (pw *Writer) pageObj(i int) int {
+	return firstPageObj + i*objsPerPage
+}
+
+// startObject mencatat offset objek lalu menulis header "N 0 obj".
+func (pw *Writer) startObject(obj int) error {
+	pw.offsets[obj] = pw.w.n
+	_, err := fmt.Fprintf(pw.w, "%d 0 obj\n", obj)
+	return err
+}
+
+// writeObject menulis objek lengkap dengan isi body.
+func (pw *Writer) writeObject(obj int, body string) error {
+	if err := pw.startObject(obj); err != nil {
+		return err
+	}
+	_, err := io.WriteString(pw.w, body+"\nendobj\n")
+	return err
+}
+
+// textString meng-encode teks sebagai string hex UTF-16BE dengan BOM sehingga aman untuk karakter non-ASCII.
+func textString(s string) string {
+	var b bytes.Buffer
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// countingWriter menghitung jumlah byte yang sudah ditulis untuk keperluan tabel xref.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
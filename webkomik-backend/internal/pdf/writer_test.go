@@ -0,0 +1,231 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// writeDocument menulis dokumen PDF berisi gambar-gambar images dan mengembalikan hasilnya.
+// resizePNGHeader mengganti ukuran pada chunk IHDR PNG tanpa mengubah data gambarnya.
+func resizePNGHeader(t *testing.T, data []byte, width, height uint32) []byte {
+	t.Helper()
+	// Signature 8 byte, lalu panjang (4), tipe "IHDR" (4), data 13 byte, dan CRC (4)
+	if string(data[12:16]) != "IHDR" {
+		t.Fatalf("chunk pertama bukan IHDR")
+	}
+	out := bytes.Clone(data)
+	binary.BigEndian.PutUint32(out[16:20], width)
+	binary.BigEndian.PutUint32(out[20:24], height)
+	binary.BigEndian.PutUint32(out[29:33], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+func writeDocument(t *testing.T, title, author string, images ...[]byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewWriter(&out, len(images), title, author)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for i, img := range images {
+		if err := w.AddImagePage(bytes.NewReader(img)); err != nil {
+			t.Fatalf("AddImagePage(%d): %v", i, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out.Bytes()
+}
+
+var startxrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+
+// checkXref memastikan tabel xref menunjuk tepat ke awal setiap objek dan mengembalikan jumlah entri.
+func checkXref(t *testing.T, doc []byte) int {
+	t.Helper()
+	m := startxrefPattern.FindSubmatch(doc)
+	if m == nil {
+		t.Fatalf("trailer startxref tidak ditemukan")
+	}
+	offset, _ := strconv.Atoi(string(m[1]))
+	rest := string(doc[offset:])
+	if !strings.HasPrefix(rest, "xref\n0 ") {
+		t.Fatalf("startxref %d tidak menunjuk ke tabel xref", offset)
+	}
+	lines := strings.Split(rest, "\n")
+	size, err := strconv.Atoi(strings.TrimPrefix(lines[1], "0 "))
+	if err != nil {
+		t.Fatalf("header xref tidak valid: %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("entri xref 0 = %q", lines[2])
+	}
+	for obj := 1; obj < size; obj++ {
+		entry := lines[2+obj]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("entri xref %d tidak valid: %q", obj, entry)
+		}
+		at, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", obj); !bytes.HasPrefix(doc[at:], []byte(want)) {
+			t.Errorf("xref objek %d menunjuk ke offset %d yang bukan awal objek", obj, at)
+		}
+	}
+	if !strings.Contains(rest, fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>", size, size-1)) {
+		t.Errorf("trailer tidak sesuai dengan ukuran xref %d", size)
+	}
+	return size
+}
+
+func TestWriterEmbedsJPEGAsIs(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 96, 192))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	data := encodeJPEG(t, img)
+
+	doc := writeDocument(t, "Judul", "Penulis", data)
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")) {
+		t.Errorf("header PDF tidak sesuai: %q", doc[:16])
+	}
+	if !bytes.Contains(doc, data) {
+		t.Error("data JPEG tidak disisipkan apa adanya")
+	}
+	// Katalog, pohon halaman, 4 objek halaman, info, dan entri 0
+	if size := checkXref(t, doc); size != 1+2+objsPerPage+1 {
+		t.Errorf("ukuran xref = %d", size)
+	}
+	for _, want := range []string{
+		"<< /Type /Pages /Kids [ 3 0 R ] /Count 1 >>",
+		"/MediaBox [0 0 72.00 144.00]",
+		"/Width 96 /Height 192 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length 6 0 R",
+		fmt.Sprintf("6 0 obj\n%d\nendobj", len(data)),
+		"q 72.00 0 0 144.00 0 0 cm /Im0 Do Q",
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("dokumen tidak berisi %q", want)
+		}
+	}
+}
+
+func TestWriterConvertsPNGAndKeepsColorSpace(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 10, 20))
+	rgba := image.NewRGBA(image.Rect(0, 0, 30, 40))
+	rgba.Set(1, 1, color.RGBA{R: 255, A: 255})
+
+	doc := writeDocument(t, "Judul", "Penulis", encodePNG(t, gray), encodePNG(t, rgba))
+
+	checkXref(t, doc)
+	for _, want := range []string{
+		"<< /Type /Pages /Kids [ 3 0 R 7 0 R ] /Count 2 >>",
+		"/Width 10 /Height 20 /ColorSpace /DeviceGray",
+		"/Width 30 /Height 40 /ColorSpace /DeviceRGB",
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("dokumen tidak berisi %q", want)
+		}
+	}
+	if bytes.Contains(doc, []byte("\x89PNG")) {
+		t.Error("PNG seharusnya dikonversi ke JPEG")
+	}
+}
+
+func TestWriterScalesTallPages(t *testing.T) {
+	// Strip webtoon: tinggi 20000 px = 15000 pt, diperkecil ke batas 14400 pt
+	img := image.NewGray(image.Rect(0, 0, 400, 20000))
+	doc := writeDocument(t, "Judul", "Penulis", encodeJPEG(t, img))
+
+	if want := "/MediaBox [0 0 288.00 14400.00]"; !bytes.Contains(doc, []byte(want)) {
+		t.Errorf("dokumen tidak berisi %q", want)
+	}
+}
+
+func TestWriterInfoUsesUTF16(t *testing.T) {
+	doc := writeDocument(t, "Komik Ä", "", encodeJPEG(t, image.NewGray(image.Rect(0, 0, 1, 1))))
+
+	want := "<< /Title <FEFF004B006F006D0069006B002000C4> /Author <FEFF> /Producer <FEFF005700650062004B006F006D0069006B> >>"
+	if !bytes.Contains(doc, []byte(want)) {
+		t.Errorf("info dokumen tidak berisi %q", want)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	page := encodeJPEG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+
+	t.Run("gambar tidak didukung", func(t *testing.T) {
+		w, err := NewWriter(&bytes.Buffer{}, 1, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddImagePage(strings.NewReader("RIFF....WEBPVP8 ")); !errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("AddImagePage = %v, want ErrUnsupportedImage", err)
+		}
+	})
+
+	t.Run("halaman melebihi jumlah", func(t *testing.T) {
+		w, err := NewWriter(&bytes.Buffer{}, 1, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddImagePage(bytes.NewReader(page)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddImagePage(bytes.NewReader(page)); err == nil {
+			t.Error("AddImagePage kedua seharusnya gagal")
+		}
+	})
+
+	t.Run("PNG melebihi batas piksel ditolak sebelum di-decode", func(t *testing.T) {
+		w, err := NewWriter(&bytes.Buffer{}, 1, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Header PNG 1 x 20.000.000 piksel; data gambarnya tetap 1x1 sehingga decode penuh akan gagal
+		data := resizePNGHeader(t, encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1))), 1, 20_000_000)
+		if err := w.AddImagePage(bytes.NewReader(data)); !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("AddImagePage = %v, want ErrImageTooLarge", err)
+		}
+	})
+
+	t.Run("halaman kurang saat Close", func(t *testing.T) {
+		w, err := NewWriter(&bytes.Buffer{}, 2, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddImagePage(bytes.NewReader(page)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err == nil {
+			t.Error("Close seharusnya gagal jika halaman kurang")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+// Package scheduler berisi pekerjaan latar belakang yang berjalan di dalam proses server.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/events"
+)
+
+const (
+	// releaseBatchSize adalah jumlah maksimum chapter yang dirilis atau diumumkan dalam satu transaksi.
+	releaseBatchSize = 100
+	// releaseRetryAfter adalah jeda sebelum notifikasi chapter baru yang belum selesai di-fan-out dikirim ulang.
+	releaseRetryAfter = 5 * time.Minute
+)
+
+// ChapterReleaser merilis chapter terjadwal saat publish_at tiba dan mengirim event chapter baru yang sama
+// seperti publikasi manual. Jadwal disimpan di database, sehingga chapter yang jatuh tempo saat server mati
+// langsung dirilis pada putaran pertama setelah server berjalan lagi. Event chapter baru diambil dari outbox
+// rilis, sehingga rilis yang notifikasinya belum selesai (termasuk dari publikasi manual) dikirim ulang.
+type ChapterReleaser struct {
+	bus      *events.Bus
+	interval time.Duration // Jeda maksimum antar pemeriksaan
+}
+
+// NewChapterReleaser membuat ChapterReleaser yang memeriksa jadwal paling lambat setiap interval.
+func NewChapterReleaser(bus *events.Bus, interval time.Duration) *ChapterReleaser {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ChapterReleaser{bus: bus, interval: interval}
+}
+
+// Run menjalankan scheduler sampai ctx dibatalkan. Jika ada jadwal yang lebih dekat dari interval,
+// scheduler bangun tepat pada jadwal tersebut.
+func (r *ChapterReleaser) Run(ctx context.Context) {
+	log.Printf("Scheduler rilis chapter berjalan (interval %s)\n", r.interval)
+	for {
+		r.releaseDue(ctx)
+		r.announcePending(ctx)
+
+		timer := time.NewTimer(r.nextWait(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("Scheduler rilis chapter dihentikan.")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// releaseDue merilis semua chapter yang sudah jatuh tempo, per batch. Event-nya dikirim oleh announcePending
+// dari outbox rilis yang ditulis dalam transaksi yang sama.
+func (r *ChapterReleaser) releaseDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		chapters, err := database.ReleaseDueChapters(ctx, releaseBatchSize)
+		if err != nil {
+			log.Printf("Error saat merilis chapter terjadwal: %v\n", err)
+			return
+		}
+		for _, ch := range chapters {
+			log.Printf("Chapter %v komik ID %d dirilis sesuai jadwal\n", ch.ChapterNumber, ch.ComicID)
+		}
+		if len(chapters) < releaseBatchSize {
+			return
+		}
+	}
+}
+
+// announcePending mengirim event chapter baru untuk rilis di outbox yang belum pernah dicoba atau yang
+// fan-out-nya belum selesai setelah releaseRetryAfter, per batch.
+func (r *ChapterReleaser) announcePending(ctx context.Context) {
+	for ctx.Err() == nil {
+		chapters, err := database.ClaimChapterReleases(ctx, releaseBatchSize, releaseRetryAfter)
+		if err != nil {
+			log.Printf("Error saat mengambil outbox rilis chapter: %v\n", err)
+			return
+		}
+		for i := range chapters {
+			ch := &chapters[i]
+			r.bus.Publish(ctx, events.NewChapterPublished(ch.ComicTitle, ch, ch.PublishAt != nil))
+		}
+		if len(chapters) < releaseBatchSize {
+			return
+		}
+	}
+}
+
+// nextWait menghitung jeda sampai pemeriksaan berikutnya: sampai jadwal terdekat, paling lama interval.
+func (r *ChapterReleaser) nextWait(ctx context.Context) time.Duration {
+	next, err := database.NextChapterReleaseAt(ctx)
+	if err != nil {
+		log.Printf("Peringatan: Gagal membaca jadwal rilis berikutnya: %v\n", err)
+		return r.interval
+	}
+	if next == nil {
+		return r.interval
+	}
+	wait := time.Until(*next)
+	if wait < time.Second {
+		wait = time.Second // Jadwal yang sudah lewat tapi terkunci instance lain dicoba lagi sebentar lagi
+	}
+	return min(wait, r.interval)
+}
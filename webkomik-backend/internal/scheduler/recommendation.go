@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+)
+
+// SimilarityRefresher menghitung ulang kemiripan antar komik untuk rekomendasi secara berkala.
+// Perhitungannya membaca seluruh interaksi pembaca, jadi intervalnya jauh lebih panjang dari scheduler lain.
+type SimilarityRefresher struct {
+	interval time.Duration // Jeda antar perhitungan
+}
+
+// NewSimilarityRefresher membuat SimilarityRefresher yang berjalan setiap interval.
+func NewSimilarityRefresher(interval time.Duration) *SimilarityRefresher {
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	return &SimilarityRefresher{interval: interval}
+}
+
+// Run menjalankan scheduler sampai ctx dibatalkan. Perhitungan pertama langsung dilakukan saat server berjalan
+// agar rekomendasi tersedia setelah migrasi tanpa menunggu satu interval.
+func (r *SimilarityRefresher) Run(ctx context.Context) {
+	log.Printf("Scheduler rekomendasi berjalan (interval %s)\n", r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("Scheduler rekomendasi dihentikan.")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh menghitung ulang kemiripan komik. Kegagalan hanya dicatat dan dicoba lagi pada putaran berikutnya.
+func (r *SimilarityRefresher) refresh(ctx context.Context) {
+	start := time.Now()
+	pairs, refreshed, err := database.RefreshComicSimilarities(ctx)
+	if err != nil {
+		log.Printf("Error saat menghitung ulang kemiripan komik: %v\n", err)
+		return
+	}
+	if refreshed {
+		log.Printf("Kemiripan komik dihitung ulang: %d pasangan dalam %s\n", pairs, time.Since(start).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/database"
+)
+
+// trendingSize adalah jumlah komik yang disimpan per periode trending, sama dengan limit maksimum endpoint-nya.
+const trendingSize = 100
+
+// TrendingRefresher menghitung ulang peringkat trending dari counter view per jam secara berkala, lalu
+// membersihkan penanda deduplikasi view dan counter yang sudah tidak dipakai.
+type TrendingRefresher struct {
+	interval    time.Duration // Jeda antar perhitungan
+	dedupWindow time.Duration // Jendela deduplikasi view, batas umur penanda view
+}
+
+// NewTrendingRefresher membuat TrendingRefresher yang berjalan setiap interval.
+func NewTrendingRefresher(interval, dedupWindow time.Duration) *TrendingRefresher {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	return &TrendingRefresher{interval: interval, dedupWindow: dedupWindow}
+}
+
+// Run menjalankan scheduler sampai ctx dibatalkan. Perhitungan pertama langsung dilakukan saat server berjalan.
+func (r *TrendingRefresher) Run(ctx context.Context) {
+	log.Printf("Scheduler trending berjalan (interval %s)\n", r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("Scheduler trending dihentikan.")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh menghitung ulang trending lalu membersihkan data view lama. Kegagalan hanya dicatat dan dicoba lagi
+// pada putaran berikutnya.
+func (r *TrendingRefresher) refresh(ctx context.Context) {
+	refreshed, err := database.RefreshTrending(ctx, trendingSize)
+	if err != nil {
+		log.Printf("Error saat menghitung ulang trending: %v\n", err)
+		return
+	}
+	if !refreshed {
+		return // Sedang dihitung oleh instance lain, termasuk pembersihannya
+	}
+	marks, buckets, err := database.PruneViewData(ctx, r.dedupWindow)
+	if err != nil {
+		log.Printf("Error saat membersihkan data view lama: %v\n", err)
+		return
+	}
+	if marks > 0 || buckets > 0 {
+		log.Printf("Data view lama dibersihkan: %d penanda, %d counter per jam\n", marks, buckets)
+	}
+}
@@ -0,0 +1,189 @@
+// Package similarity menghitung kemiripan item-item antar komik untuk rekomendasi dari co-occurrence pembaca
+// (cosine) dan kesamaan genre (Jaccard). Perhitungan dilakukan di memori agar database hanya perlu membaca
+// interaksi sekali dan menulis hasilnya per batch.
+package similarity
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Params adalah bobot dan batas perhitungan kemiripan.
+type Params struct {
+	CoReaderWeight float64 // Bobot cosine co-occurrence pembaca
+	GenreWeight    float64 // Bobot Jaccard genre
+	Neighbors      int     // Jumlah komik paling mirip yang disimpan untuk setiap komik
+	// GenreCandidates adalah jumlah komik terpopuler per genre yang menjadi kandidat tetangga lewat genre saja.
+	// Pasangan yang punya pembaca bersama selalu dihitung, sehingga batas ini hanya memangkas pasangan
+	// yang kemiripannya murni dari genre.
+	GenreCandidates int
+}
+
+// Pair adalah kemiripan komik ComicID dengan SimilarComicID.
+type Pair struct {
+	ComicID        int64
+	SimilarComicID int64
+	Score          float64
+	CoReaders      int // Jumlah pengguna yang berinteraksi dengan kedua komik
+}
+
+// Builder mengumpulkan komik, genre, dan interaksi pembaca lalu menghitung kemiripannya.
+type Builder struct {
+	comics  map[int64]comicInfo
+	readers map[int64]int    // Jumlah pembaca per komik
+	co      map[[2]int64]int // Jumlah pembaca bersama, kunci {a, b} dengan a < b
+}
+
+type comicInfo struct {
+	genres     []int64 // Terurut
+	popularity int64
+}
+
+// NewBuilder membuat Builder kosong.
+func NewBuilder() *Builder {
+	return &Builder{
+		comics:  map[int64]comicInfo{},
+		readers: map[int64]int{},
+		co:      map[[2]int64]int{},
+	}
+}
+
+// AddComic mendaftarkan komik yang boleh direkomendasikan beserta genre dan popularitasnya (misalnya view).
+// Interaksi dengan komik yang tidak didaftarkan diabaikan.
+func (b *Builder) AddComic(id int64, genres []int64, popularity int64) {
+	genres = slices.Clone(genres)
+	slices.Sort(genres)
+	b.comics[id] = comicInfo{genres: slices.Compact(genres), popularity: popularity}
+}
+
+// AddReader mencatat komik-komik yang berinteraksi dengan satu pengguna.
+func (b *Builder) AddReader(comicIDs []int64) {
+	ids := make([]int64, 0, len(comicIDs))
+	for _, id := range comicIDs {
+		if _, ok := b.comics[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	for i, a := range ids {
+		b.readers[a]++
+		for _, c := range ids[i+1:] {
+			b.co[[2]int64{a, c}]++
+		}
+	}
+}
+
+// Build menghitung tetangga setiap komik: skor = CoReaderWeight x cosine pembaca + GenreWeight x Jaccard genre.
+// Hasil diurutkan per ComicID, lalu skor tertinggi lebih dulu; pasangan dengan skor 0 tidak disertakan.
+func (b *Builder) Build(p Params) []Pair {
+	// Tetangga dari pembaca bersama, dua arah
+	coNeighbors := map[int64][]int64{}
+	for key := range b.co {
+		coNeighbors[key[0]] = append(coNeighbors[key[0]], key[1])
+		coNeighbors[key[1]] = append(coNeighbors[key[1]], key[0])
+	}
+
+	// Kandidat genre: komik terpopuler di setiap genre
+	byGenre := map[int64][]int64{}
+	for id, info := range b.comics {
+		for _, g := range info.genres {
+			byGenre[g] = append(byGenre[g], id)
+		}
+	}
+	for g, ids := range byGenre {
+		slices.SortFunc(ids, func(x, y int64) int {
+			if c := cmp.Compare(b.comics[y].popularity, b.comics[x].popularity); c != 0 {
+				return c
+			}
+			return cmp.Compare(x, y)
+		})
+		if len(ids) > p.GenreCandidates {
+			byGenre[g] = ids[:p.GenreCandidates]
+		}
+	}
+
+	sources := make([]int64, 0, len(b.comics))
+	for id := range b.comics {
+		sources = append(sources, id)
+	}
+	slices.Sort(sources)
+
+	var pairs []Pair
+	candidates := map[int64]bool{}
+	for _, a := range sources {
+		clear(candidates)
+		for _, c := range coNeighbors[a] {
+			candidates[c] = true
+		}
+		for _, g := range b.comics[a].genres {
+			for _, c := range byGenre[g] {
+				if c != a {
+					candidates[c] = true
+				}
+			}
+		}
+
+		neighbors := make([]Pair, 0, len(candidates))
+		for c := range candidates {
+			pair := b.score(a, c, p)
+			if pair.Score > 0 {
+				neighbors = append(neighbors, pair)
+			}
+		}
+		slices.SortFunc(neighbors, func(x, y Pair) int {
+			if c := cmp.Compare(y.Score, x.Score); c != 0 {
+				return c
+			}
+			return cmp.Compare(x.SimilarComicID, y.SimilarComicID)
+		})
+		if len(neighbors) > p.Neighbors {
+			neighbors = neighbors[:p.Neighbors]
+		}
+		pairs = append(pairs, neighbors...)
+	}
+	return pairs
+}
+
+// score menghitung kemiripan komik a dengan c.
+func (b *Builder) score(a, c int64, p Params) Pair {
+	key := [2]int64{min(a, c), max(a, c)}
+	shared := b.co[key]
+
+	var cosine float64
+	if shared > 0 {
+		cosine = float64(shared) / math.Sqrt(float64(b.readers[a])*float64(b.readers[c]))
+	}
+
+	var jaccard float64
+	ga, gc := b.comics[a].genres, b.comics[c].genres
+	if common := countCommon(ga, gc); common > 0 {
+		jaccard = float64(common) / float64(len(ga)+len(gc)-common)
+	}
+
+	return Pair{
+		ComicID:        a,
+		SimilarComicID: c,
+		Score:          p.CoReaderWeight*cosine + p.GenreWeight*jaccard,
+		CoReaders:      shared,
+	}
+}
+
+// countCommon menghitung elemen yang ada di kedua slice terurut tanpa duplikat.
+func countCommon(a, b []int64) int {
+	n := 0
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] == b[0]:
+			n++
+			a, b = a[1:], b[1:]
+		case a[0] < b[0]:
+			a = a[1:]
+		default:
+			b = b[1:]
+		}
+	}
+	return n
+}
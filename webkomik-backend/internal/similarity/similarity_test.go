@@ -0,0 +1,126 @@
+package similarity
+
+import (
+	"math"
+	"testing"
+)
+
+var testParams = Params{CoReaderWeight: 0.7, GenreWeight: 0.3, Neighbors: 50, GenreCandidates: 100}
+
+// fixtureBuilder menyiapkan empat komik:
+//
+//	1: genre {10, 20}, 2: genre {10}, 3: genre {30}, 4: genre {10, 20} (tidak punya pembaca)
+//
+// Pembaca: u1 {1, 2}, u2 {1, 2, 3}, u3 {1}, u4 {3, 99}. Komik 99 tidak didaftarkan sehingga diabaikan.
+func fixtureBuilder() *Builder {
+	b := NewBuilder()
+	b.AddComic(1, []int64{20, 10}, 500)
+	b.AddComic(2, []int64{10}, 300)
+	b.AddComic(3, []int64{30}, 100)
+	b.AddComic(4, []int64{10, 20, 10}, 50)
+
+	b.AddReader([]int64{1, 2})
+	b.AddReader([]int64{2, 1, 3})
+	b.AddReader([]int64{1, 1})
+	b.AddReader([]int64{3, 99})
+	return b
+}
+
+func pairMap(pairs []Pair) map[[2]int64]Pair {
+	m := map[[2]int64]Pair{}
+	for _, p := range pairs {
+		m[[2]int64{p.ComicID, p.SimilarComicID}] = p
+	}
+	return m
+}
+
+func TestBuildScores(t *testing.T) {
+	pairs := pairMap(fixtureBuilder().Build(testParams))
+
+	// Pembaca: komik 1 = 3 (u1, u2, u3), komik 2 = 2 (u1, u2), komik 3 = 2 (u2, u4)
+	tests := []struct {
+		a, b      int64
+		score     float64
+		coReaders int
+	}{
+		// cosine 2/sqrt(3*2), Jaccard {10}/{10,20} = 1/2
+		{1, 2, 0.7*2/math.Sqrt(6) + 0.3*0.5, 2},
+		{2, 1, 0.7*2/math.Sqrt(6) + 0.3*0.5, 2},
+		// cosine 1/sqrt(3*2), tanpa genre bersama
+		{1, 3, 0.7 / math.Sqrt(6), 1},
+		// cosine 1/sqrt(2*2)
+		{2, 3, 0.7 * 0.5, 1},
+		// Tanpa pembaca, genre identik
+		{1, 4, 0.3, 0},
+		{4, 1, 0.3, 0},
+		// Tanpa pembaca, Jaccard {10}/{10,20}
+		{4, 2, 0.3 * 0.5, 0},
+	}
+	for _, tt := range tests {
+		got, ok := pairs[[2]int64{tt.a, tt.b}]
+		if !ok {
+			t.Errorf("pasangan (%d, %d) tidak ada", tt.a, tt.b)
+			continue
+		}
+		if math.Abs(got.Score-tt.score) > 1e-12 {
+			t.Errorf("skor (%d, %d) = %v, want %v", tt.a, tt.b, got.Score, tt.score)
+		}
+		if got.CoReaders != tt.coReaders {
+			t.Errorf("co_readers (%d, %d) = %d, want %d", tt.a, tt.b, got.CoReaders, tt.coReaders)
+		}
+	}
+
+	// Tanpa pembaca maupun genre bersama, skornya 0 dan tidak disimpan
+	for _, key := range [][2]int64{{3, 4}, {4, 3}, {1, 1}, {3, 99}} {
+		if _, ok := pairs[key]; ok {
+			t.Errorf("pasangan %v seharusnya tidak ada", key)
+		}
+	}
+	if len(pairs) != 10 {
+		t.Errorf("jumlah pasangan = %d, want 10", len(pairs))
+	}
+}
+
+func TestBuildOrdersAndLimitsNeighbors(t *testing.T) {
+	p := testParams
+	p.Neighbors = 2
+	pairs := fixtureBuilder().Build(p)
+
+	var fromOne []int64
+	for i, pair := range pairs {
+		if i > 0 && pairs[i-1].ComicID > pair.ComicID {
+			t.Fatalf("hasil tidak terurut per komik: %v", pairs)
+		}
+		if pair.ComicID == 1 {
+			fromOne = append(fromOne, pair.SimilarComicID)
+		}
+	}
+	// Komik 1: komik 2 (~0.72), komik 4 (0.3), komik 3 (~0.29)
+	if len(fromOne) != 2 || fromOne[0] != 2 || fromOne[1] != 4 {
+		t.Errorf("tetangga komik 1 = %v, want [2 4]", fromOne)
+	}
+}
+
+func TestBuildCapsGenreOnlyCandidates(t *testing.T) {
+	p := testParams
+	p.GenreCandidates = 2
+	pairs := pairMap(fixtureBuilder().Build(p))
+
+	// Genre 10 berisi komik 1, 2, 4; hanya dua terpopuler (1 dan 2) yang menjadi kandidat genre,
+	// jadi komik 2 tidak lagi mendapat komik 4 sebagai tetangga.
+	if _, ok := pairs[[2]int64{2, 4}]; ok {
+		t.Error("pasangan (2, 4) seharusnya dipangkas batas kandidat genre")
+	}
+	// Genre 20 hanya berisi komik 1 dan 4, jadi komik 1 tetap mendapat komik 4 lewat genre tersebut
+	if _, ok := pairs[[2]int64{1, 4}]; !ok {
+		t.Error("pasangan (1, 4) seharusnya tetap ada")
+	}
+	// Komik 4 tetap mendapat tetangga dari kandidat genre terpopuler
+	if _, ok := pairs[[2]int64{4, 1}]; !ok {
+		t.Error("pasangan (4, 1) seharusnya tetap ada")
+	}
+	// Pasangan dengan pembaca bersama tidak terpengaruh batas kandidat genre
+	if got := pairs[[2]int64{3, 2}]; got.CoReaders != 1 {
+		t.Errorf("pasangan (3, 2) = %+v, want co_readers 1", got)
+	}
+}
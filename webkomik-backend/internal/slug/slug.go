@@ -0,0 +1,27 @@
+// Package slug membuat slug URL dari teks bebas (nama genre, tag, dll).
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Make mengubah teks menjadi slug huruf kecil dengan pemisah "-".
+// Huruf dan angka Unicode dipertahankan, karakter lain dianggap pemisah.
+// Contoh: "Slice of Life!" menjadi "slice-of-life".
+func Make(s string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingDash = false
+			continue
+		}
+		pendingDash = true
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage menyimpan file di filesystem lokal dan menyajikannya lewat URL publik
+// (misalnya route static /uploads pada server Gin).
+type LocalStorage struct {
+	baseDir   string
+	publicURL string
+}
+
+// NewLocalStorage membuat LocalStorage dengan root baseDir. Direktori dibuat jika belum ada.
+func NewLocalStorage(baseDir, publicURL string) (*LocalStorage, error) {
+	if baseDir == "" {
+		return nil, errors.New("direktori storage lokal harus di-set")
+	}
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori storage %s: %w", baseDir, err)
+	}
+	return &LocalStorage{baseDir: baseDir, publicURL: strings.TrimRight(publicURL, "/")}, nil
+}
+
+// BaseDir mengembalikan root direktori storage, dipakai untuk menyajikan file statis.
+func (s *LocalStorage) BaseDir() string {
+	return s.baseDir
+}
+
+// Put menulis file ke disk lewat file sementara lalu me-rename-nya agar tidak ada file setengah jadi.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.baseDir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("gagal membuat direktori untuk %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Tidak berpengaruh setelah rename berhasil
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("gagal menulis file %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("gagal menutup file %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("gagal memindahkan file %s: %w", key, err)
+	}
+	return s.publicURL + "/" + key, nil
+}
+
+// Open membuka file dari disk.
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(s.baseDir, filepath.FromSlash(key)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("gagal membuka file %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete menghapus file dari disk.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.baseDir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("gagal menghapus file %s: %w", key, err)
+	}
+	return nil
+}
+
+// KeyFromURL mengembalikan key jika url berada di bawah URL publik storage ini.
+func (s *LocalStorage) KeyFromURL(url string) (string, bool) {
+	return keyFromPublicURL(s.publicURL, url)
+}
@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// unsignedPayload dipakai sebagai hash payload agar body bisa di-stream tanpa dibaca dua kali.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Options berisi konfigurasi untuk S3Storage.
+// Endpoint bisa mengarah ke AWS S3 maupun layanan S3-compatible (MinIO, Supabase Storage S3, R2, dll).
+type S3Options struct {
+	Endpoint     string // Contoh: https://s3.ap-southeast-1.amazonaws.com atau http://localhost:9000
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	PublicURL    string // Opsional, prefix URL publik (misalnya CDN). Default ke URL objek di endpoint.
+	UsePathStyle bool   // true untuk MinIO dan sebagian besar layanan S3-compatible
+	HTTPClient   *http.Client
+}
+
+// S3Storage menyimpan file ke bucket S3-compatible menggunakan REST API dengan AWS Signature V4.
+type S3Storage struct {
+	opts     S3Options
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Storage membuat S3Storage dan memvalidasi opsi yang wajib.
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" || opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY, dan S3_SECRET_KEY harus di-set untuk storage s3")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT tidak valid: %q", opts.Endpoint)
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Minute}
+	}
+	return &S3Storage{opts: opts, endpoint: endpoint, client: client}, nil
+}
+
+// Put mengunggah objek dengan PUT Object. size wajib diketahui karena S3 membutuhkan Content-Length.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if size < 0 {
+		return "", errors.New("ukuran objek harus diketahui untuk upload ke S3")
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", fmt.Errorf("gagal upload %s ke S3: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", s.responseError("upload", key, resp)
+	}
+	return s.publicURL(key), nil
+}
+
+// Open mengambil objek dengan GET Object.
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil %s dari S3: %w", key, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.responseError("mengambil", key, resp)
+	}
+}
+
+// Delete menghapus objek dengan DELETE Object. S3 mengembalikan 204 walaupun objek tidak ada.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus %s dari S3: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError("menghapus", key, resp)
+	}
+	return nil
+}
+
+// KeyFromURL mengembalikan key jika url berada di bawah URL publik bucket ini.
+func (s *S3Storage) KeyFromURL(url string) (string, bool) {
+	return keyFromPublicURL(s.publicBaseURL(), url)
+}
+
+// publicBaseURL adalah prefix URL publik objek tanpa "/" di akhir.
+func (s *S3Storage) publicBaseURL() string {
+	if s.opts.PublicURL != "" {
+		return strings.TrimRight(s.opts.PublicURL, "/")
+	}
+	u := s.bucketURL()
+	return u.String()
+}
+
+// publicURL membentuk URL publik untuk key.
+func (s *S3Storage) publicURL(key string) string {
+	return s.publicBaseURL() + "/" + escapePath(key)
+}
+
+// bucketURL mengembalikan URL dasar bucket sesuai gaya addressing (path-style atau virtual-hosted).
+func (s *S3Storage) bucketURL() *url.URL {
+	u := *s.endpoint
+	if s.opts.UsePathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + s.opts.Bucket
+	} else {
+		u.Host = s.opts.Bucket + "." + u.Host
+	}
+	return &u
+}
+
+// newRequest membuat request ke objek key. Signature ditambahkan oleh do.
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := s.bucketURL()
+	u.Path = u.Path + "/" + key
+	u.RawPath = escapePath(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat request S3: %w", err)
+	}
+	return req, nil
+}
+
+// do menandatangani request dengan AWS Signature V4 lalu mengirimkannya.
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+// sign menambahkan header Authorization sesuai AWS Signature Version 4.
+func (s *S3Storage) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	canonicalRequest, signedHeaders := canonicalRequestV4(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, [][2]string{
+		{"host", req.URL.Host},
+		{"x-amz-content-sha256", unsignedPayload},
+		{"x-amz-date", amzDate},
+	}, unsignedPayload)
+	scope, signature := signV4(s.opts.SecretKey, s.opts.Region, "s3", now, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalRequestV4 menyusun canonical request Signature V4. headers berisi nama header huruf kecil yang sudah
+// terurut beserta nilainya; path dan query harus sudah di-encode. Mengembalikan canonical request dan daftar
+// signed headers.
+func canonicalRequestV4(method, path, query string, headers [][2]string, payloadHash string) (string, string) {
+	var canonicalHeaders strings.Builder
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		canonicalHeaders.WriteString(h[0] + ":" + strings.TrimSpace(h[1]) + "\n")
+		names[i] = h[0]
+	}
+	signedHeaders := strings.Join(names, ";")
+	return strings.Join([]string{
+		method,
+		path,
+		query,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// signV4 menandatangani canonicalRequest untuk service di region pada waktu now.
+// Mengembalikan credential scope dan signature dalam hex.
+func signV4(secretKey, region, service string, now time.Time, canonicalRequest string) (string, string) {
+	shortDate := now.Format("20060102")
+	scope := shortDate + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), shortDate)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	return scope, hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+}
+
+// responseError membentuk error dari response S3 yang gagal, termasuk potongan body XML-nya.
+func (s *S3Storage) responseError(action, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 gagal %s %s: status %d: %s", action, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// escapePath meng-encode path sesuai aturan URI encoding AWS (karakter unreserved dan "/" tidak di-encode).
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		ch := path[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || ch == '/' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// emptyPayloadHash adalah SHA-256 dari body kosong.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Vektor dari AWS Signature Version 4 test suite (region us-east-1, service "service").
+func TestSignV4TestSuite(t *testing.T) {
+	const secret = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		query     string
+		canonical string
+		signature string
+	}{
+		{
+			name:   "get-vanilla",
+			method: "GET", path: "/",
+			canonical: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "post-vanilla",
+			method: "POST", path: "/",
+			canonical: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: "GET", path: "/", query: "Param1=value1&Param2=value2",
+			canonical: "GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "get-unreserved",
+			method: "GET", path: escapePath("/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"),
+			canonical: "GET\n/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, signedHeaders := canonicalRequestV4(tt.method, tt.path, tt.query, [][2]string{
+				{"host", "example.amazonaws.com"},
+				{"x-amz-date", "20150830T123600Z"},
+			}, emptyPayloadHash)
+			if canonical != tt.canonical {
+				t.Errorf("canonical request\n got %q\nwant %q", canonical, tt.canonical)
+			}
+			if signedHeaders != "host;x-amz-date" {
+				t.Errorf("signed headers = %q", signedHeaders)
+			}
+			scope, signature := signV4(secret, "us-east-1", "service", now, canonical)
+			if scope != "20150830/us-east-1/service/aws4_request" {
+				t.Errorf("scope = %q", scope)
+			}
+			if signature != tt.signature {
+				t.Errorf("signature = %s, want %s", signature, tt.signature)
+			}
+		})
+	}
+}
+
+// Contoh GET Object dari dokumentasi Amazon S3 (Signature V4 dengan header Authorization).
+func TestSignV4S3GetObjectExample(t *testing.T) {
+	canonical, signedHeaders := canonicalRequestV4("GET", "/test.txt", "", [][2]string{
+		{"host", "examplebucket.s3.amazonaws.com"},
+		{"range", "bytes=0-9"},
+		{"x-amz-content-sha256", emptyPayloadHash},
+		{"x-amz-date", "20130524T000000Z"},
+	}, emptyPayloadHash)
+	if got := hexSHA256([]byte(canonical)); got != "7344ae5b7ee6c3e7e6b0fe0640412a37625d1fbfff95c48bbb2dc43964946972" {
+		t.Errorf("hash canonical request = %s\n%s", got, canonical)
+	}
+	if signedHeaders != "host;range;x-amz-content-sha256;x-amz-date" {
+		t.Errorf("signed headers = %q", signedHeaders)
+	}
+	_, signature := signV4("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", "us-east-1", "s3",
+		time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC), canonical)
+	if signature != "f0e8bdb87c964420e857bd35b5d6ed310bd44f0170aba48dd91039c6036bdb41" {
+		t.Errorf("signature = %s", signature)
+	}
+}
+
+// fakeS3 adalah pengganti server S3-compatible (seperti MinIO) dengan path-style addressing yang
+// memverifikasi Signature V4 setiap request secara independen dari signer S3Storage.
+type fakeS3 struct {
+	accessKey, secretKey, region, bucket string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	paths   []string // Path mentah setiap request yang diterima
+}
+
+var authorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f.verify(r); err != nil {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>"+err.Error()+"</Message></Error>", http.StatusForbidden)
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.URL.EscapedPath())
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if int64(len(data)) != r.ContentLength {
+			http.Error(w, "<Error><Code>IncompleteBody</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = data
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verify menghitung ulang signature dari request yang diterima server.
+func (f *fakeS3) verify(r *http.Request) error {
+	m := authorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return fmt.Errorf("header Authorization tidak valid: %q", r.Header.Get("Authorization"))
+	}
+	accessKey, date, region, signedHeaders, signature := m[1], m[2], m[3], m[4], m[5]
+	if accessKey != f.accessKey || region != f.region {
+		return errors.New("credential tidak dikenal")
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(amzDate, date) {
+		return errors.New("tanggal scope tidak sama dengan X-Amz-Date")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(value))
+	}
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonicalHeaders.String(), signedHeaders,
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + date + "/" + region + "/s3/aws4_request\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + f.secretKey)
+	for _, part := range []string{date, region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature)) {
+		return errors.New("signature tidak cocok")
+	}
+	return nil
+}
+
+func newFakeS3Storage(t *testing.T, secretKey string) (*S3Storage, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{accessKey: "minio", secretKey: "minio-secret", region: "us-east-1", bucket: "komik", objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	store, err := NewS3Storage(S3Options{
+		Endpoint:     srv.URL,
+		Bucket:       "komik",
+		AccessKey:    "minio",
+		SecretKey:    secretKey,
+		UsePathStyle: true,
+		HTTPClient:   srv.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return store, fake
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	store, fake := newFakeS3Storage(t, "minio-secret")
+	ctx := context.Background()
+	key := "comics/1/chapters/2/halaman 01+ä.jpg"
+	data := []byte("isi gambar")
+
+	url, err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	const escaped = "/komik/comics/1/chapters/2/halaman%2001%2B%C3%A4.jpg"
+	if !strings.HasSuffix(url, escaped) {
+		t.Errorf("URL publik = %q, want akhiran %q", url, escaped)
+	}
+	if fake.paths[0] != escaped {
+		t.Errorf("path request = %q, want %q", fake.paths[0], escaped)
+	}
+
+	rc, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Errorf("Open = %q, want %q", got, data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open setelah Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3StorageWrongSecret(t *testing.T) {
+	store, _ := newFakeS3Storage(t, "secret-salah")
+	_, err := store.Put(context.Background(), "a.jpg", strings.NewReader("x"), 1, "image/jpeg")
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("Put dengan secret salah = %v, want status 403", err)
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-vue-WebKomik/webkomik-backend/internal/config"
+)
+
+// Driver storage yang didukung.
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrNotFound dikembalikan jika objek yang diminta tidak ada di storage.
+var ErrNotFound = errors.New("objek tidak ditemukan di storage")
+
+// Storage adalah abstraksi tempat penyimpanan file (gambar halaman, sampul, dll).
+// Key selalu berupa path relatif dengan pemisah "/", contoh: "comics/1/chapters/2/abc.jpg".
+type Storage interface {
+	// Put menyimpan isi r di bawah key dan mengembalikan URL publik objek tersebut.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	// Open membuka objek untuk dibaca. Pemanggil wajib menutup reader yang dikembalikan.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus objek. Menghapus objek yang tidak ada bukan error.
+	Delete(ctx context.Context, key string) error
+	// KeyFromURL mengembalikan key dari URL publik jika URL tersebut dikelola oleh storage ini.
+	KeyFromURL(url string) (string, bool)
+}
+
+// New membuat Storage sesuai driver di konfigurasi.
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.StorageLocalDir, cfg.StoragePublicURL)
+	case DriverS3:
+		return NewS3Storage(S3Options{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			PublicURL:    cfg.StoragePublicURL,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("driver storage tidak dikenal: %s", cfg.StorageDriver)
+	}
+}
+
+// cleanKey menormalkan key dan menolak key yang mencoba keluar dari root storage.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	if key == "" {
+		return "", errors.New("key storage kosong")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("key storage tidak valid: %q", key)
+		}
+	}
+	return key, nil
+}
+
+// keyFromPublicURL memotong prefix baseURL dari url untuk mendapatkan key.
+func keyFromPublicURL(baseURL, url string) (string, bool) {
+	prefix := strings.TrimRight(baseURL, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	key, err := cleanKey(strings.TrimPrefix(url, prefix))
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,16 @@
+-- 001_comic_listing.sql
+-- Kolom dan indeks pendukung untuk listing komik yang dipaginasi, difilter, dan diurutkan.
+-- Jalankan secara berurutan lewat SQL editor Supabase atau psql.
+
+-- view_count dipakai sebagai sinyal popularitas untuk sort=popularity.
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS view_count BIGINT NOT NULL DEFAULT 0;
+
+-- Indeks komposit (kolom sort, id) agar keyset pagination tidak perlu full scan.
+CREATE INDEX IF NOT EXISTS idx_comics_created_at_id ON comics (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comics_updated_at_id ON comics (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comics_title_id ON comics (title, id);
+CREATE INDEX IF NOT EXISTS idx_comics_view_count_id ON comics (view_count, id);
+
+-- Indeks untuk filter.
+CREATE INDEX IF NOT EXISTS idx_comics_genre_id ON comics (genre_id);
+CREATE INDEX IF NOT EXISTS idx_comics_author_name_lower ON comics (lower(author_name));
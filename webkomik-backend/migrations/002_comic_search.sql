@@ -0,0 +1,21 @@
+-- 002_comic_search.sql
+-- Full-text search (tsvector) dan fuzzy search (pg_trgm) untuk endpoint GET /api/comics/search.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Vektor pencarian gabungan Indonesia + Inggris agar perbedaan stemming kedua bahasa tetap cocok.
+-- Bobot: judul (A) > penulis (B) > deskripsi (C).
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(author_name, '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce(description, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_comics_search_vector ON comics USING GIN (search_vector);
+
+-- Indeks trigram untuk toleransi salah ketik.
+CREATE INDEX IF NOT EXISTS idx_comics_title_trgm ON comics USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_comics_author_name_trgm ON comics USING GIN (author_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_comics_description_trgm ON comics USING GIN (description gin_trgm_ops);
@@ -0,0 +1,10 @@
+-- 003_chapter_crud.sql
+-- Nomor chapter harus unik per komik; dipakai sebagai identitas chapter di URL.
+
+ALTER TABLE chapters
+    ADD CONSTRAINT chapters_comic_id_chapter_number_key UNIQUE (comic_id, chapter_number);
+
+-- Halaman ikut terhapus saat chapter dihapus.
+ALTER TABLE pages DROP CONSTRAINT IF EXISTS pages_chapter_id_fkey;
+ALTER TABLE pages
+    ADD CONSTRAINT pages_chapter_id_fkey FOREIGN KEY (chapter_id) REFERENCES chapters (id) ON DELETE CASCADE;
@@ -0,0 +1,8 @@
+-- 004_page_uploads.sql
+-- Dimensi gambar halaman dan nomor halaman unik per chapter.
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS width INTEGER;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS height INTEGER;
+
+ALTER TABLE pages
+    ADD CONSTRAINT pages_chapter_id_page_number_key UNIQUE (chapter_id, page_number);
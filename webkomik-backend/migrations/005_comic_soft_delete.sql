@@ -0,0 +1,11 @@
+-- 005_comic_soft_delete.sql
+-- Soft delete komik: baris dengan deleted_at terisi disembunyikan dari endpoint publik.
+
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_comics_deleted_at ON comics (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Chapter ikut terhapus saat komik di-purge.
+ALTER TABLE chapters DROP CONSTRAINT IF EXISTS chapters_comic_id_fkey;
+ALTER TABLE chapters
+    ADD CONSTRAINT chapters_comic_id_fkey FOREIGN KEY (comic_id) REFERENCES comics (id) ON DELETE CASCADE;
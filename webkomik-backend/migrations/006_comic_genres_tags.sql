@@ -0,0 +1,31 @@
+-- 006_comic_genres_tags.sql
+-- Relasi many-to-many komik-genre menggantikan comics.genre_id, ditambah tag bebas.
+
+CREATE TABLE IF NOT EXISTS comic_genres (
+    comic_id BIGINT NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    genre_id BIGINT NOT NULL REFERENCES genres (id) ON DELETE RESTRICT,
+    PRIMARY KEY (comic_id, genre_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comic_genres_genre_id ON comic_genres (genre_id);
+
+-- Pindahkan genre tunggal yang sudah ada ke tabel relasi lalu hapus kolom lamanya.
+INSERT INTO comic_genres (comic_id, genre_id)
+SELECT id, genre_id FROM comics WHERE genre_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+DROP INDEX IF EXISTS idx_comics_genre_id;
+ALTER TABLE comics DROP COLUMN IF EXISTS genre_id;
+
+CREATE TABLE IF NOT EXISTS tags (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    slug       TEXT        NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS comic_tags (
+    comic_id BIGINT NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    tag_id   BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (comic_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comic_tags_tag_id ON comic_tags (tag_id);
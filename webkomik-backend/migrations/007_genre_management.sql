@@ -0,0 +1,20 @@
+-- 007_genre_management.sql
+-- Kolom tambahan untuk pengelolaan genre oleh admin: slug, deskripsi, dan urutan tampil.
+
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS slug TEXT;
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS display_order INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Isi slug genre lama dari namanya; jika bentrok, tambahkan ID agar tetap unik.
+UPDATE genres
+SET slug = trim(BOTH '-' FROM regexp_replace(lower(name), '[^[:alnum:]]+', '-', 'g'))
+WHERE slug IS NULL;
+
+UPDATE genres g
+SET slug = g.slug || '-' || g.id
+WHERE EXISTS (SELECT 1 FROM genres o WHERE o.slug = g.slug AND o.id < g.id);
+
+ALTER TABLE genres ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_genres_slug ON genres (slug);
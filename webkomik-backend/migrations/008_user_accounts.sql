@@ -0,0 +1,38 @@
+-- 008_user_accounts.sql
+-- Cermin data pengguna dari identity provider (Supabase Auth) untuk kebutuhan administrasi:
+-- pencarian pengguna, perubahan peran, serta suspend/ban.
+
+CREATE TABLE IF NOT EXISTS user_accounts (
+    id              UUID PRIMARY KEY, -- Sama dengan auth.users.id
+    email           TEXT,
+    role            TEXT        NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'creator', 'admin')),
+    role_updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status          TEXT        NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended', 'banned')),
+    suspended_until TIMESTAMPTZ,
+    status_reason   TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_accounts_email_lower ON user_accounts (lower(email));
+CREATE INDEX IF NOT EXISTS idx_user_accounts_role ON user_accounts (role);
+
+-- Salin pengguna yang sudah terdaftar jika database ini adalah database Supabase.
+-- role_updated_at diisi waktu lampau agar peran dari JWT yang lebih baru tetap dipakai saat sinkronisasi.
+DO $$
+BEGIN
+    IF to_regclass('auth.users') IS NOT NULL THEN
+        INSERT INTO user_accounts (id, email, role, role_updated_at, created_at, last_seen_at)
+        SELECT
+            u.id,
+            u.email,
+            CASE WHEN u.raw_app_meta_data ->> 'role' IN ('user', 'creator', 'admin')
+                 THEN u.raw_app_meta_data ->> 'role' ELSE 'user' END,
+            'epoch',
+            u.created_at,
+            u.last_sign_in_at
+        FROM auth.users u
+        ON CONFLICT (id) DO NOTHING;
+    END IF;
+END $$;
@@ -0,0 +1,42 @@
+-- 009_permissions.sql
+-- Model izin: setiap peran dipetakan ke sekumpulan izin. Middleware dan handler memeriksa izin,
+-- bukan nama peran, sehingga hak akses bisa diubah tanpa mengubah kode.
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name        TEXT PRIMARY KEY,
+    description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role       TEXT NOT NULL CHECK (role IN ('user', 'creator', 'admin')),
+    permission TEXT NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('comic:create', 'Membuat komik baru'),
+    ('comic:update:own', 'Memperbarui komik milik sendiri'),
+    ('comic:update:any', 'Memperbarui komik siapa pun'),
+    ('comic:delete:own', 'Memindahkan komik milik sendiri ke sampah'),
+    ('comic:delete:any', 'Memindahkan komik siapa pun ke sampah'),
+    ('comic:trash:manage', 'Melihat sampah, memulihkan, dan menghapus permanen komik'),
+    ('chapter:publish:own', 'Mengelola chapter dan halaman pada komik milik sendiri'),
+    ('chapter:publish:any', 'Mengelola chapter dan halaman pada komik siapa pun'),
+    ('genre:manage', 'Membuat, mengubah, dan menghapus genre'),
+    ('user:read', 'Melihat daftar dan detail pengguna'),
+    ('user:role:update', 'Mengubah peran pengguna'),
+    ('user:ban', 'Suspend, ban, dan mencabut blokir pengguna'),
+    ('role:manage', 'Mengubah pemetaan peran ke izin')
+ON CONFLICT (name) DO NOTHING;
+
+-- Pemetaan awal mengikuti perilaku sebelumnya: creator mengelola komik miliknya, admin mengelola semuanya.
+INSERT INTO role_permissions (role, permission) VALUES
+    ('creator', 'comic:create'),
+    ('creator', 'comic:update:own'),
+    ('creator', 'comic:delete:own'),
+    ('creator', 'chapter:publish:own')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
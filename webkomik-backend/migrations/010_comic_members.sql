@@ -0,0 +1,41 @@
+-- 010_comic_members.sql
+-- Tim pengelola komik: setiap komik bisa memiliki beberapa anggota dengan peran owner, editor, atau uploader,
+-- beserta undangan yang harus diterima oleh pengguna yang diundang.
+
+CREATE TABLE IF NOT EXISTS comic_members (
+    comic_id   BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL,
+    role       TEXT        NOT NULL CHECK (role IN ('owner', 'editor', 'uploader')),
+    added_by   UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comic_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comic_members_user_id ON comic_members (user_id);
+
+-- Pengunggah komik yang sudah ada menjadi owner.
+INSERT INTO comic_members (comic_id, user_id, role)
+SELECT id, uploaded_by_admin_id::uuid, 'owner' FROM comics WHERE uploaded_by_admin_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS comic_invitations (
+    id              BIGSERIAL PRIMARY KEY,
+    comic_id        BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    invitee_user_id UUID,
+    invitee_email   TEXT,
+    role            TEXT        NOT NULL CHECK (role IN ('owner', 'editor', 'uploader')),
+    invited_by      UUID        NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at    TIMESTAMPTZ,
+    CHECK (invitee_user_id IS NOT NULL OR invitee_email IS NOT NULL)
+);
+
+-- Hanya satu undangan pending per pengguna/email untuk komik yang sama.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_comic_invitations_pending_user
+    ON comic_invitations (comic_id, invitee_user_id) WHERE status = 'pending' AND invitee_user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_comic_invitations_pending_email
+    ON comic_invitations (comic_id, lower(invitee_email)) WHERE status = 'pending' AND invitee_email IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comic_invitations_invitee_email ON comic_invitations (lower(invitee_email)) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_comic_invitations_invitee_user_id ON comic_invitations (invitee_user_id) WHERE status = 'pending';
@@ -0,0 +1,39 @@
+-- 011_publication_workflow.sql
+-- Alur publikasi komik dan chapter: draft -> in_review -> published, dengan status hidden untuk
+-- konten yang diturunkan peninjau. Endpoint publik hanya menampilkan konten berstatus published.
+
+-- Kolom ditambahkan dengan default 'published' agar konten yang sudah ada tetap tampil,
+-- lalu default diganti 'draft' untuk konten baru.
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'in_review', 'published', 'hidden')),
+    ADD COLUMN IF NOT EXISTS review_note  TEXT,
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS reviewed_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS reviewed_by  UUID,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+ALTER TABLE comics ALTER COLUMN status SET DEFAULT 'draft';
+UPDATE comics SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+ALTER TABLE chapters
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'in_review', 'published', 'hidden')),
+    ADD COLUMN IF NOT EXISTS review_note  TEXT,
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS reviewed_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS reviewed_by  UUID,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+ALTER TABLE chapters ALTER COLUMN status SET DEFAULT 'draft';
+UPDATE chapters SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comics_status ON comics (status) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comics_review_queue ON comics (submitted_at) WHERE status = 'in_review';
+CREATE INDEX IF NOT EXISTS idx_chapters_review_queue ON chapters (submitted_at) WHERE status = 'in_review';
+
+INSERT INTO permissions (name, description) VALUES
+    ('content:review', 'Meninjau, menyetujui, menolak, dan menyembunyikan komik dan chapter')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'content:review')
+ON CONFLICT DO NOTHING;
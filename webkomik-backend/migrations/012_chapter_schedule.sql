@@ -0,0 +1,9 @@
+-- 012_chapter_schedule.sql
+-- Rilis chapter terjadwal: chapter yang sudah disetujui dengan publish_at di masa depan baru tampil
+-- untuk publik saat publish_at tiba. published_at terisi ketika chapter benar-benar rilis,
+-- sehingga chapter yang sudah waktunya tetapi published_at masih NULL belum diumumkan oleh scheduler.
+
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_chapters_scheduled
+    ON chapters (publish_at) WHERE status = 'published' AND published_at IS NULL;
@@ -0,0 +1,20 @@
+-- 013_reader_library.sql
+-- Pustaka pembaca: komik yang diikuti (follow) atau ditandai (bookmark) oleh setiap pengguna.
+-- Satu baris per pengguna dan komik; baris dihapus jika komik tidak lagi diikuti maupun ditandai.
+-- last_read_chapter_number dipakai untuk menghitung chapter yang belum dibaca.
+
+CREATE TABLE IF NOT EXISTS library_entries (
+    user_id                  UUID        NOT NULL,
+    comic_id                 BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    following                BOOLEAN     NOT NULL DEFAULT FALSE,
+    bookmarked               BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_read_chapter_number REAL,
+    last_read_at             TIMESTAMPTZ,
+    created_at               TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at               TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, comic_id),
+    CHECK (following OR bookmarked)
+);
+
+-- Daftar pengikut sebuah komik, misalnya untuk notifikasi chapter baru.
+CREATE INDEX IF NOT EXISTS idx_library_entries_followers ON library_entries (comic_id) WHERE following;
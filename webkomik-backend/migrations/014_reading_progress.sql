@@ -0,0 +1,25 @@
+-- 014_reading_progress.sql
+-- Progres baca pengguna. reading_progress menyimpan posisi terakhir per komik untuk "lanjutkan membaca",
+-- sedangkan reading_history menyimpan satu baris per chapter yang pernah dibuka untuk riwayat baca.
+-- Keduanya di-upsert dengan pembanding waktu baca sehingga kiriman batch yang datang tidak berurutan
+-- tidak menimpa progres yang lebih baru.
+
+CREATE TABLE IF NOT EXISTS reading_progress (
+    user_id     UUID        NOT NULL,
+    comic_id    BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    chapter_id  BIGINT      NOT NULL REFERENCES chapters (id) ON DELETE CASCADE,
+    page_number INTEGER     NOT NULL CHECK (page_number >= 1),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, comic_id)
+);
+CREATE INDEX IF NOT EXISTS idx_reading_progress_user_updated ON reading_progress (user_id, updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS reading_history (
+    user_id     UUID        NOT NULL,
+    chapter_id  BIGINT      NOT NULL REFERENCES chapters (id) ON DELETE CASCADE,
+    comic_id    BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    page_number INTEGER     NOT NULL CHECK (page_number >= 1),
+    read_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, chapter_id)
+);
+CREATE INDEX IF NOT EXISTS idx_reading_history_user_read_at ON reading_history (user_id, read_at DESC, chapter_id);
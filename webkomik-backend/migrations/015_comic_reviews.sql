@@ -0,0 +1,47 @@
+-- 015_comic_reviews.sql
+-- Rating bintang 1-5 dan ulasan teks dari pembaca, satu per pengguna per komik.
+-- Agregat rating disimpan di tabel comics dan diperbarui di transaksi yang sama dengan perubahan ulasan,
+-- sehingga listing tidak perlu menghitung ulang dari seluruh ulasan.
+
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_sum   BIGINT  NOT NULL DEFAULT 0;
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS rating_average DOUBLE PRECISION
+    GENERATED ALWAYS AS (CASE WHEN rating_count > 0 THEN ROUND(rating_sum::numeric / rating_count, 2)::float8 END) STORED;
+
+CREATE TABLE IF NOT EXISTS comic_reviews (
+    id              BIGSERIAL PRIMARY KEY,
+    comic_id        BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    user_id         UUID        NOT NULL,
+    rating          SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body            TEXT,
+    -- Moderasi hanya berlaku untuk teks ulasan; rating tetap dihitung walaupun ulasan disembunyikan.
+    status          TEXT        NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden')),
+    moderation_note TEXT,
+    moderated_by    UUID,
+    moderated_at    TIMESTAMPTZ,
+    report_count    INTEGER     NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (comic_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comic_reviews_public ON comic_reviews (comic_id, created_at DESC)
+    WHERE status = 'published' AND body IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comic_reviews_reported ON comic_reviews (report_count DESC, id) WHERE report_count > 0;
+
+-- Laporan pembaca atas ulasan yang tidak pantas, satu laporan per pengguna per ulasan.
+CREATE TABLE IF NOT EXISTS comic_review_reports (
+    review_id  BIGINT      NOT NULL REFERENCES comic_reviews (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL,
+    reason     TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (review_id, user_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('review:moderate', 'Menyembunyikan, memulihkan, dan menghapus ulasan pembaca')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'review:moderate')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,56 @@
+-- 016_chapter_comments.sql
+-- Komentar pembaca pada chapter (opsional menunjuk satu halaman) dengan balasan bertingkat.
+-- root_id menunjuk komentar teratas dalam thread sehingga semua balasan satu thread bisa dipaginasi bersama,
+-- sedangkan parent_id menunjuk komentar yang dibalas langsung.
+-- like_count dan reply_count disimpan agar urutan "top" dan jumlah balasan tidak dihitung ulang setiap dibaca.
+
+CREATE TABLE IF NOT EXISTS chapter_comments (
+    id              BIGSERIAL PRIMARY KEY,
+    chapter_id      BIGINT      NOT NULL REFERENCES chapters (id) ON DELETE CASCADE,
+    page_id         BIGINT      REFERENCES pages (id) ON DELETE SET NULL,
+    root_id         BIGINT      REFERENCES chapter_comments (id) ON DELETE CASCADE,
+    parent_id       BIGINT      REFERENCES chapter_comments (id) ON DELETE CASCADE,
+    user_id         UUID        NOT NULL,
+    body            TEXT        NOT NULL,
+    is_spoiler      BOOLEAN     NOT NULL DEFAULT FALSE,
+    -- deleted: dihapus penulis atau moderator; baris tetap ada agar balasan di bawahnya tidak hilang.
+    status          TEXT        NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden', 'deleted')),
+    moderation_note TEXT,
+    moderated_by    UUID,
+    moderated_at    TIMESTAMPTZ,
+    like_count      INTEGER     NOT NULL DEFAULT 0,
+    reply_count     INTEGER     NOT NULL DEFAULT 0, -- Balasan yang tampil di thread, hanya untuk komentar teratas
+    edited_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((root_id IS NULL) = (parent_id IS NULL))
+);
+
+-- Indeks keyset untuk komentar teratas per chapter (urutan terbaru dan top) dan balasan per thread.
+CREATE INDEX IF NOT EXISTS idx_chapter_comments_newest ON chapter_comments (chapter_id, created_at DESC, id DESC) WHERE root_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_chapter_comments_top ON chapter_comments (chapter_id, like_count DESC, id DESC) WHERE root_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_chapter_comments_thread ON chapter_comments (root_id, created_at, id) WHERE root_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_chapter_comments_moderation ON chapter_comments (status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS chapter_comment_likes (
+    comment_id BIGINT      NOT NULL REFERENCES chapter_comments (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('comment:create', 'Menulis, membalas, dan menyunting komentar sendiri'),
+    ('comment:delete:own', 'Menghapus komentar sendiri'),
+    ('comment:moderate', 'Menyembunyikan, memulihkan, dan menghapus komentar siapa pun')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('user', 'comment:create'),
+    ('user', 'comment:delete:own'),
+    ('creator', 'comment:create'),
+    ('creator', 'comment:delete:own'),
+    ('admin', 'comment:create'),
+    ('admin', 'comment:delete:own'),
+    ('admin', 'comment:moderate')
+ON CONFLICT DO NOTHING;
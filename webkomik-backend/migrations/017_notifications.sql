@@ -0,0 +1,43 @@
+-- 017_notifications.sql
+-- Notifikasi pengguna, misalnya chapter baru pada komik yang diikuti.
+-- Tabel notifications sekaligus menjadi kanal in-app; email dan web push dikirim dari baris yang sama
+-- sesuai preferensi pengguna.
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    type       TEXT        NOT NULL,
+    comic_id   BIGINT      REFERENCES comics (id) ON DELETE CASCADE,
+    chapter_id BIGINT      REFERENCES chapters (id) ON DELETE CASCADE,
+    title      TEXT        NOT NULL,
+    body       TEXT        NOT NULL,
+    link       TEXT,        -- Path frontend yang dibuka saat notifikasi diklik
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+-- Satu notifikasi chapter baru per pengguna dan chapter, meskipun event-nya terkirim lebih dari sekali.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_new_chapter ON notifications (user_id, chapter_id) WHERE type = 'new_chapter';
+
+-- Preferensi kanal notifikasi. Pengguna tanpa baris di sini memakai nilai default kolom.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id       UUID PRIMARY KEY,
+    email_enabled BOOLEAN     NOT NULL DEFAULT FALSE,
+    push_enabled  BOOLEAN     NOT NULL DEFAULT TRUE,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Langganan Web Push dari browser pengguna (hasil PushManager.subscribe).
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    endpoint   TEXT        NOT NULL UNIQUE,
+    p256dh     TEXT        NOT NULL, -- Kunci publik browser, base64url
+    auth       TEXT        NOT NULL, -- Auth secret browser, base64url
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user ON push_subscriptions (user_id);
@@ -0,0 +1,36 @@
+-- 018_view_counts.sql
+-- Penghitungan view chapter dan peringkat trending.
+-- Setiap view yang lolos deduplikasi langsung menambah counter per komik per jam dan comics.view_count,
+-- tanpa menyimpan event mentah. Peringkat trending dihitung ulang berkala oleh scheduler dari counter per jam
+-- ke comic_trending, sehingga endpoint trending hanya membaca hasil yang sudah jadi.
+
+-- Penanda view terakhir yang dihitung per pengunjung per chapter. viewer_key berisi "u:<user id>" untuk
+-- pengguna login atau "ip:<hash IP>" untuk pengunjung anonim. Baris yang lebih tua dari jendela deduplikasi
+-- dihapus scheduler.
+CREATE TABLE IF NOT EXISTS chapter_view_marks (
+    chapter_id BIGINT      NOT NULL REFERENCES chapters (id) ON DELETE CASCADE,
+    viewer_key TEXT        NOT NULL,
+    counted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chapter_id, viewer_key)
+);
+CREATE INDEX IF NOT EXISTS idx_chapter_view_marks_counted_at ON chapter_view_marks (counted_at);
+
+-- Counter view per komik per jam (UTC). Disimpan 30 hari, sesuai periode trending terpanjang.
+CREATE TABLE IF NOT EXISTS comic_view_buckets (
+    comic_id     BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    bucket_start TIMESTAMPTZ NOT NULL,
+    views        BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (comic_id, bucket_start)
+);
+CREATE INDEX IF NOT EXISTS idx_comic_view_buckets_bucket_start ON comic_view_buckets (bucket_start);
+
+-- Peringkat trending hasil perhitungan terakhir, per periode.
+CREATE TABLE IF NOT EXISTS comic_trending (
+    period      TEXT        NOT NULL CHECK (period IN ('daily', 'weekly', 'monthly')),
+    comic_id    BIGINT      NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    rank        INTEGER     NOT NULL,
+    views       BIGINT      NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (period, comic_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comic_trending_period_rank ON comic_trending (period, rank);
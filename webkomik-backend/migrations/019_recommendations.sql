@@ -0,0 +1,20 @@
+-- 019_recommendations.sql
+-- Kemiripan antar komik (item-item) untuk rekomendasi personal. Skor menggabungkan co-occurrence pembaca
+-- (pengguna yang mengikuti, membaca, atau memberi rating tinggi pada kedua komik) dengan kesamaan genre.
+-- Tabel dihitung ulang oleh scheduler per batch komik sumber; baris yang computed_at-nya lebih lama dari
+-- perhitungan terakhir dihapus. Rekomendasi per pengguna dihitung saat request dari sinyal pengguna
+-- tersebut dan tabel ini.
+
+CREATE TABLE IF NOT EXISTS comic_similarities (
+    comic_id         BIGINT           NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    similar_comic_id BIGINT           NOT NULL REFERENCES comics (id) ON DELETE CASCADE,
+    score            DOUBLE PRECISION NOT NULL,
+    co_readers       INTEGER          NOT NULL DEFAULT 0, -- Jumlah pengguna yang berinteraksi dengan kedua komik
+    computed_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comic_id, similar_comic_id),
+    CHECK (comic_id <> similar_comic_id)
+);
+
+-- Sinyal per pengguna dibaca berdasarkan user_id; reading_history dan library_entries sudah memiliki
+-- primary key yang diawali user_id, ulasan belum.
+CREATE INDEX IF NOT EXISTS idx_comic_reviews_user ON comic_reviews (user_id);
@@ -0,0 +1,11 @@
+-- 020_chapter_release_outbox.sql
+-- Outbox notifikasi chapter baru. Baris ditulis di transaksi yang sama dengan pengisian published_at,
+-- sehingga chapter yang sudah rilis tetap diumumkan ke pengikutnya walaupun server mati sebelum fan-out selesai.
+-- Baris dihapus setelah fan-out berhasil; scheduler rilis chapter mengulang baris yang belum pernah dicoba
+-- atau percobaan terakhirnya sudah lama. Fan-out aman diulang karena notifikasi chapter baru unik per pengguna.
+CREATE TABLE IF NOT EXISTS chapter_release_outbox (
+    chapter_id   BIGINT      PRIMARY KEY REFERENCES chapters (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempted_at TIMESTAMPTZ -- NULL berarti belum pernah dicoba
+);
+CREATE INDEX IF NOT EXISTS idx_chapter_release_outbox_attempted_at ON chapter_release_outbox (attempted_at NULLS FIRST);
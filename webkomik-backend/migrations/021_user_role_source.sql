@@ -0,0 +1,5 @@
+-- 021_user_role_source.sql
+-- Asal peran pengguna: 'token' berarti peran mengikuti app_metadata di JWT, 'admin' berarti peran
+-- diubah admin lewat aplikasi. Peran dari admin tidak pernah ditimpa oleh sinkronisasi token.
+ALTER TABLE user_accounts
+    ADD COLUMN IF NOT EXISTS role_source TEXT NOT NULL DEFAULT 'token' CHECK (role_source IN ('token', 'admin'));
@@ -0,0 +1,14 @@
+-- 022_team_own_permissions.sql
+-- Izin *:own komik berlaku untuk anggota tim komik (comic_members) sesuai peran timnya. Anggota yang
+-- diundang biasanya berperan global 'user', jadi izin tersebut juga dipetakan ke peran itu. Admin bisa
+-- mencabutnya dari role_permissions untuk menonaktifkan pengelolaan komik oleh anggota tim.
+
+UPDATE permissions SET description = 'Memperbarui komik yang timnya diikuti pengguna' WHERE name = 'comic:update:own';
+UPDATE permissions SET description = 'Memindahkan komik yang dimiliki pengguna ke sampah' WHERE name = 'comic:delete:own';
+UPDATE permissions SET description = 'Mengelola chapter dan halaman pada komik yang timnya diikuti pengguna' WHERE name = 'chapter:publish:own';
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('user', 'comic:update:own'),
+    ('user', 'comic:delete:own'),
+    ('user', 'chapter:publish:own')
+ON CONFLICT DO NOTHING;
@@ -57,6 +57,10 @@ export const getComicDetail = (id) => {
     return request(`/comics/${id}`); // Endpoint GET /api/comics/:id
 };
 
+export const getChapter = (chapterId) => {
+    return request(`/chapters/${chapterId}`); // Endpoint GET /api/chapters/:id (chapter beserta halamannya)
+};
+
 export const createComic = (comicData) => {
     // Endpoint ini memerlukan otentikasi dan peran admin atau creator
     // Kita akan menandainya agar token ditambahkan
@@ -74,7 +74,7 @@ export const useComicStore = defineStore('comics', () => {
         title: comicData.title,
         description: comicData.description || null,
         author_name: comicData.author_name || null,
-        genre_id: comicData.genre_id || null,
+        genre_ids: comicData.genre_id ? [comicData.genre_id] : [], // Form masih memilih satu genre
         cover_image_url: comicData.cover_image_url || null,
       };
 
@@ -110,7 +110,7 @@ export const useComicStore = defineStore('comics', () => {
       if (comicData.title !== undefined && comicData.title !== null) payload.title = comicData.title;
       if (comicData.description !== undefined) payload.description = comicData.description;
       if (comicData.author_name !== undefined) payload.author_name = comicData.author_name;
-      if (comicData.genre_id !== undefined) payload.genre_id = comicData.genre_id || null;
+      if (comicData.genre_id !== undefined) payload.genre_ids = comicData.genre_id ? [comicData.genre_id] : [];
       if (comicData.cover_image_url !== undefined) payload.cover_image_url = comicData.cover_image_url;
       
       // Panggil fungsi updateComic dari apiService